@@ -0,0 +1,89 @@
+package memlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// AccessEntry is an entry of the access log of a log, see WithAccessLog()
+type AccessEntry struct {
+	// LogID is the identity of the read log, see Log.ID()
+	LogID string `json:"logId"`
+	// Consumer is the name of the reading consumer
+	Consumer string `json:"consumer"`
+	// Offset is the offset of the read record
+	Offset Offset `json:"offset"`
+	// Time is the UTC time of the access
+	Time time.Time `json:"time"`
+}
+
+// accessLog records record accesses into a side log
+type accessLog struct {
+	log   Appender
+	every uint64
+	reads uint64 // atomic, number of accesses
+}
+
+// WithAccessLog records which consumer read which offsets into the given side
+// log, e.g. to evidence data access in regulated data paths. Every n-th access
+// is recorded, i.e. 1 records all accesses. Accesses are recorded as JSON
+// encoded AccessEntry with the consumer name as record key.
+//
+// Reads of a Consumer (see NewConsumer()) and records delivered by streams are
+// recorded. Streams are attributed to their owner, see WithStreamOwner(). If
+// an access can not be recorded, the read fails or the stream terminates, i.e.
+// records are not handed out without evidence. The side log must not be the
+// log itself.
+func WithAccessLog(side Appender, n int) Option {
+	return func(log *Log) error {
+		if side == nil {
+			return errors.New("access log must not be nil")
+		}
+
+		if l, ok := side.(*Log); ok && l == log {
+			return errors.New("access log must not be the log itself")
+		}
+
+		if n <= 0 {
+			return errors.New("access sample rate must be greater than 0")
+		}
+
+		log.access = &accessLog{log: side, every: uint64(n)}
+		return nil
+	}
+}
+
+// recordAccess records the access of the given consumer to the record with
+// the given offset in the access log, if enabled. Must not be called with a
+// lock held.
+func (l *Log) recordAccess(ctx context.Context, consumer string, offset Offset) error {
+	if l.access == nil {
+		return nil
+	}
+
+	if (atomic.AddUint64(&l.access.reads, 1)-1)%l.access.every != 0 {
+		return nil
+	}
+
+	entry := AccessEntry{
+		LogID:    l.ID(),
+		Consumer: consumer,
+		Offset:   offset,
+		Time:     l.clock.Now().UTC(),
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode access entry: %w", err)
+	}
+
+	if _, err = l.access.log.Write(ctx, b, WithKey([]byte(consumer))); err != nil {
+		return fmt.Errorf("record access: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package memlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+// failingAppender is an Appender failing every write
+type failingAppender struct{}
+
+func (failingAppender) Write(context.Context, []byte, ...WriteOption) (Offset, error) {
+	return -1, errors.New("write failed")
+}
+
+func readAccessEntries(t *testing.T, side *Log) []AccessEntry {
+	t.Helper()
+
+	ctx := context.Background()
+	_, latest := side.Range(ctx)
+
+	var entries []AccessEntry
+	for offset := Offset(0); offset <= latest; offset++ {
+		r, err := side.Read(ctx, offset)
+		assert.NilError(t, err)
+
+		var e AccessEntry
+		assert.NilError(t, json.Unmarshal(r.Data, &e))
+		assert.DeepEqual(t, r.Metadata.Key, []byte(e.Consumer))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestWithAccessLog(t *testing.T) {
+	t.Run("fails with invalid options", func(t *testing.T) {
+		ctx := context.Background()
+		side, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = New(ctx, WithAccessLog(nil, 1))
+		assert.ErrorContains(t, err, "must not be nil")
+
+		_, err = New(ctx, WithAccessLog(side, 0))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("records consumer reads", func(t *testing.T) {
+		ctx := context.Background()
+		side, err := New(ctx)
+		assert.NilError(t, err)
+
+		l, err := New(ctx, WithAccessLog(side, 1))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 3) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		c, err := l.NewConsumer(ctx, "c1", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err = c.Next(ctx)
+			assert.NilError(t, err)
+		}
+
+		entries := readAccessEntries(t, side)
+		assert.Equal(t, len(entries), 3)
+		for i, e := range entries {
+			assert.Equal(t, e.LogID, l.ID())
+			assert.Equal(t, e.Consumer, "c1")
+			assert.Equal(t, e.Offset, Offset(i))
+		}
+	})
+
+	t.Run("samples stream deliveries", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		side, err := New(ctx)
+		assert.NilError(t, err)
+
+		l, err := New(ctx, WithAccessLog(side, 2))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 4) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		streamCtx, streamCancel := context.WithCancel(ctx)
+		stream, errs := l.Stream(streamCtx, 0, WithStreamOwner("dashboard"))
+		for i := 0; i < 4; i++ {
+			<-stream
+		}
+
+		// the access of the last record is recorded after delivery
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if _, latest := side.Range(ctx); latest == 1 {
+				return poll.Success()
+			}
+			return poll.Continue("waiting for access to be recorded")
+		}, poll.WithTimeout(time.Second))
+		streamCancel()
+		<-errs
+
+		entries := readAccessEntries(t, side)
+		assert.Equal(t, len(entries), 2)
+		assert.Equal(t, entries[0].Consumer, "dashboard")
+		assert.Equal(t, entries[0].Offset, Offset(0))
+		assert.Equal(t, entries[1].Offset, Offset(2))
+	})
+
+	t.Run("fails read if access can not be recorded", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithAccessLog(failingAppender{}, 1))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		c, err := l.NewConsumer(ctx, "c1", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+
+		_, err = c.Next(ctx)
+		assert.ErrorContains(t, err, "record access")
+	})
+	t.Run("ends stream if access can not be recorded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		l, err := New(ctx, WithAccessLog(failingAppender{}, 1))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		stream, errs := l.Stream(ctx, 0)
+		err = <-errs
+		assert.ErrorContains(t, err, "record access")
+
+		// the record is not handed out
+		_, ok := <-stream
+		assert.Assert(t, !ok)
+	})
+}
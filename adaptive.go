@@ -0,0 +1,94 @@
+package memlog
+
+import (
+	"errors"
+	"time"
+)
+
+// WithSegmentTargetDuration adapts the size of new segments to the observed
+// write throughput, so that a segment holds records written within about the
+// given duration. The size of a new segment is derived from the records of the
+// previous segment and bounded by the maximum segment size (see
+// WithMaxSegmentSize()), i.e. retention stays bounded during bursts while
+// quiet periods do not retain records for overly long.
+func WithSegmentTargetDuration(d time.Duration) Option {
+	return func(log *Log) error {
+		if d <= 0 {
+			return errors.New("target duration must be greater than 0")
+		}
+
+		log.conf.targetDuration = d
+		return nil
+	}
+}
+
+// WithSegmentTargetBytes adapts the size of new segments to the observed
+// record sizes, so that a segment holds about the given number of payload
+// bytes. The size of a new segment is derived from the records of the previous
+// segment and bounded by the maximum segment size (see WithMaxSegmentSize()).
+// If combined with WithSegmentTargetDuration(), the smaller size is used.
+func WithSegmentTargetBytes(n int) Option {
+	return func(log *Log) error {
+		if n <= 0 {
+			return errors.New("target bytes must be greater than 0")
+		}
+
+		log.conf.targetBytes = n
+		return nil
+	}
+}
+
+// nextSegmentSize returns the size of a new segment based on the records of
+// the given previous segment. Must be protected with a lock by the caller.
+func (l *Log) nextSegmentSize(prev *segment) int {
+	limit := l.conf.segmentSize
+	if prev == nil || (l.conf.targetDuration == 0 && l.conf.targetBytes == 0) {
+		return limit
+	}
+
+	var (
+		records     int
+		bytes       int
+		first, last time.Time
+	)
+	for _, r := range prev.data {
+		if r.Metadata.Offset == -1 {
+			// gap
+			continue
+		}
+
+		if records == 0 {
+			first = r.Metadata.Created
+		}
+		last = r.Metadata.Created
+		bytes += len(r.Data)
+		records++
+	}
+
+	if records == 0 {
+		return limit
+	}
+
+	size := limit
+	if l.conf.targetDuration > 0 {
+		if elapsed := last.Sub(first); elapsed > 0 {
+			size = minInt(size, int(float64(records)*float64(l.conf.targetDuration)/float64(elapsed)))
+		}
+	}
+
+	if l.conf.targetBytes > 0 && bytes > 0 {
+		size = minInt(size, l.conf.targetBytes*records/bytes)
+	}
+
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_AdaptiveSegmentSize(t *testing.T) {
+	t.Run("fails with invalid targets", func(t *testing.T) {
+		ctx := context.Background()
+
+		_, err := New(ctx, WithSegmentTargetDuration(0))
+		assert.ErrorContains(t, err, "greater than 0")
+
+		_, err = New(ctx, WithSegmentTargetBytes(-1))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("adapts segment size to target duration", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(100), WithSegmentTargetDuration(time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, cap(l.active.data), 100)
+
+		// 100 records within 99s: ~1 record/s
+		for _, d := range NewTestDataSlice(t, 101) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+			mockClock.Add(time.Second)
+		}
+		assert.Equal(t, cap(l.active.data), 60)
+
+		// burst: 60 records within 59ms, bounded by max segment size
+		for _, d := range NewTestDataSlice(t, 60) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+			mockClock.Add(time.Millisecond)
+		}
+		assert.Equal(t, cap(l.active.data), 100)
+	})
+
+	t.Run("adapts segment size to target bytes", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(10), WithSegmentTargetBytes(64))
+		assert.NilError(t, err)
+
+		for i := 0; i < 11; i++ {
+			_, err = l.Write(ctx, make([]byte, 16))
+			assert.NilError(t, err)
+		}
+		assert.Equal(t, cap(l.active.data), 4)
+		assert.Equal(t, l.nextSegmentSize(nil), 10)
+	})
+}
@@ -0,0 +1,118 @@
+package memlog
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAdmissionDenied is returned when a write is rejected by admission control
+// because the log retains too many payload bytes for the priority of the
+// write, see WithAdmissionControl()
+var ErrAdmissionDenied = errors.New("admission denied")
+
+// Priority is the priority class of a write used by admission control
+type Priority int
+
+const (
+	// PriorityLow is the priority of writes which are rejected first under
+	// memory pressure, e.g. debug or analytics events
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of writes unless explicitly specified
+	PriorityNormal Priority = 0
+	// PriorityHigh is the priority of critical writes which should succeed
+	// during overload
+	PriorityHigh Priority = 1
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// WithPriority sets the admission priority class of the write, see
+// WithAdmissionControl()
+func WithPriority(p Priority) WriteOption {
+	return func(wc *writeConfig) {
+		wc.priority = p
+	}
+}
+
+// WithAdmissionControl rejects writes with ErrAdmissionDenied when the payload
+// bytes retained in the log including the written record would exceed the
+// limit of the priority class of the write. Writes of a priority class without
+// a limit are always admitted.
+//
+// Giving lower priorities smaller limits protects critical writes under memory
+// pressure, e.g. {PriorityLow: 64 << 20, PriorityNormal: 96 << 20} rejects low
+// priority writes first, then normal priority writes, while high priority
+// writes still succeed. Retained bytes only decrease when segments are purged,
+// i.e. by writes of admitted priorities.
+func WithAdmissionControl(limits map[Priority]int) Option {
+	return func(log *Log) error {
+		if len(limits) == 0 {
+			return errors.New("admission limits must not be empty")
+		}
+
+		admission := make(map[Priority]int, len(limits))
+		for p, limit := range limits {
+			if limit <= 0 {
+				return fmt.Errorf("admission limit of priority %s must be greater than 0", p)
+			}
+			admission[p] = limit
+		}
+
+		log.admission = admission
+		return nil
+	}
+}
+
+// RetainedBytes returns the payload bytes of all records retained in the log,
+// i.e. the bytes checked by admission control
+//
+// Safe for concurrent use.
+func (l *Log) RetainedBytes() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.retainedBytes()
+}
+
+// retainedBytes returns the payload bytes of all records retained in the log.
+// Must be protected with a lock by the caller.
+func (l *Log) retainedBytes() int {
+	n := l.active.bytes
+	if l.history != nil {
+		n += l.history.bytes
+	}
+	return n
+}
+
+// admit returns ErrAdmissionDenied if writing the given number of payload
+// bytes with the given priority exceeds the admission limit of the priority.
+// Must be protected with a lock by the caller.
+func (l *Log) admit(size int, p Priority) error {
+	limit, ok := l.admission[p]
+	if !ok {
+		return nil
+	}
+
+	retained := l.retainedBytes()
+	if l.active.full() {
+		// the write purges the history segment
+		retained = l.active.bytes
+	}
+
+	if retained+size > limit {
+		return fmt.Errorf("%w: %s priority write of %d bytes exceeds limit of %d bytes (retained %d bytes)", ErrAdmissionDenied, p, size, limit, retained)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWithAdmissionControl(t *testing.T) {
+	testCases := []struct {
+		name    string
+		limits  map[Priority]int
+		wantErr string
+	}{
+		{name: "fails with empty limits", limits: nil, wantErr: "must not be empty"},
+		{name: "fails with invalid limit", limits: map[Priority]int{PriorityLow: 0}, wantErr: "admission limit of priority low must be greater than 0"},
+		{name: "succeeds", limits: map[Priority]int{PriorityLow: 10, PriorityNormal: 20}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(context.Background(), WithAdmissionControl(tc.limits))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestLog_Admission(t *testing.T) {
+	t.Run("rejects writes by priority", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx,
+			WithMaxSegmentSize(10),
+			WithAdmissionControl(map[Priority]int{PriorityLow: 4, PriorityNormal: 8}),
+		)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("abcd"), WithPriority(PriorityLow))
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 4)
+
+		_, err = l.Write(ctx, []byte("a"), WithPriority(PriorityLow))
+		assert.Assert(t, errors.Is(err, ErrAdmissionDenied))
+
+		_, err = l.Write(ctx, []byte("abcd"))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("a"))
+		assert.Assert(t, errors.Is(err, ErrAdmissionDenied))
+
+		_, err = l.Write(ctx, []byte("abcd"), WithPriority(PriorityHigh))
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 12)
+	})
+
+	t.Run("admits writes after purge", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx,
+			WithMaxSegmentSize(1),
+			WithAdmissionControl(map[Priority]int{PriorityLow: 2}),
+		)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("aa"), WithPriority(PriorityHigh))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("bb"), WithPriority(PriorityHigh))
+		assert.NilError(t, err)
+
+		// accounts for purging the history segment
+		_, err = l.Write(ctx, []byte("c"), WithPriority(PriorityLow))
+		assert.Assert(t, errors.Is(err, ErrAdmissionDenied))
+
+		_, err = l.Write(ctx, []byte("d"), WithPriority(PriorityHigh))
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 3)
+
+		_, err = l.Write(ctx, []byte("e"), WithPriority(PriorityLow))
+		assert.NilError(t, err)
+	})
+
+	t.Run("rejects whole batch", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithAdmissionControl(map[Priority]int{PriorityNormal: 5}))
+		assert.NilError(t, err)
+
+		_, err = l.WriteBatch(ctx, [][]byte{[]byte("abc"), []byte("def")})
+		assert.Assert(t, errors.Is(err, ErrAdmissionDenied))
+		assert.ErrorContains(t, err, "validate record 1")
+		assert.Assert(t, l.IsEmpty(ctx))
+	})
+
+	t.Run("rejected sparse write leaves no gap", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets(), WithAdmissionControl(map[Priority]int{PriorityNormal: 1}))
+		assert.NilError(t, err)
+
+		_, err = l.WriteAt(ctx, 10, []byte("ab"))
+		assert.Assert(t, errors.Is(err, ErrAdmissionDenied))
+
+		offset, err := l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(0))
+	})
+}
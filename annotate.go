@@ -0,0 +1,85 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxAnnotationSize is the maximum size of an annotation key and value in
+// bytes
+const maxAnnotationSize = 1024
+
+// Annotate sets the annotation with the given key of the record at the given
+// offset, e.g. to mark a record as "processed-by" a consumer or "flagged"
+// after it has been written. In contrast to the immutable record, annotations
+// can be changed at any time. An empty value removes the annotation.
+// Annotations are returned in the record metadata (see Header.Annotations)
+// and do not alter the payload, checksum or signature of the record. The
+// annotations of a record are removed when the record is purged. Annotating a
+// record which is not readable returns the error of Read() for the offset.
+//
+// Safe for concurrent use.
+func (l *Log) Annotate(ctx context.Context, offset Offset, key, value string) error {
+	if key == "" {
+		return errors.New("annotation key must not be empty")
+	}
+
+	if len(key)+len(value) > maxAnnotationSize {
+		return fmt.Errorf("annotation exceeds %d bytes", maxAnnotationSize)
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.mu.Unlock()
+
+	if _, err := l.read(ctx, offset); err != nil {
+		return err
+	}
+
+	l.pruneAnnotations()
+
+	if value == "" {
+		delete(l.annotations[offset], key)
+		if len(l.annotations[offset]) == 0 {
+			delete(l.annotations, offset)
+		}
+		return nil
+	}
+
+	if l.annotations == nil {
+		l.annotations = make(map[Offset]map[string]string)
+	}
+	if l.annotations[offset] == nil {
+		l.annotations[offset] = make(map[string]string)
+	}
+	l.annotations[offset][key] = value
+
+	return nil
+}
+
+// annotate adds a copy of the annotations of the record to its metadata. Must
+// be protected with a lock by the caller.
+func (l *Log) annotate(r *Record) {
+	a, ok := l.annotations[r.Metadata.Offset]
+	if !ok {
+		return
+	}
+
+	r.Metadata.Annotations = make(map[string]string, len(a))
+	for k, v := range a {
+		r.Metadata.Annotations[k] = v
+	}
+}
+
+// pruneAnnotations removes the annotations of purged records. Must be
+// protected with a lock by the caller.
+func (l *Log) pruneAnnotations() {
+	earliest, _ := l.offsetRange()
+	for offset := range l.annotations {
+		if offset < earliest || l.nextWritten(offset) != offset {
+			delete(l.annotations, offset)
+		}
+	}
+}
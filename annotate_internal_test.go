@@ -0,0 +1,85 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Annotate(t *testing.T) {
+	t.Run("fails with invalid input", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.Annotate(ctx, 0, "", "value")
+		assert.ErrorContains(t, err, "must not be empty")
+
+		err = l.Annotate(ctx, 0, "key", strings.Repeat("a", maxAnnotationSize))
+		assert.ErrorContains(t, err, "exceeds")
+
+		err = l.Annotate(ctx, 0, "key", "value")
+		assert.Assert(t, errors.Is(err, ErrEmptyLog))
+
+		_, err = l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+
+		err = l.Annotate(ctx, 1, "key", "value")
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	})
+
+	t.Run("sets and removes annotations without altering records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithChecksums(), WithChecksumVerification())
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		before, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.Assert(t, before.Metadata.Annotations == nil)
+
+		assert.NilError(t, l.Annotate(ctx, offset, "processed-by", "billing"))
+		assert.NilError(t, l.Annotate(ctx, offset, "flagged", "true"))
+
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Annotations, map[string]string{"processed-by": "billing", "flagged": "true"})
+		assert.DeepEqual(t, r.Data, before.Data)
+		assert.Equal(t, r.Metadata.Checksum, before.Metadata.Checksum)
+
+		// returned annotations are copies
+		r.Metadata.Annotations["flagged"] = "false"
+
+		assert.NilError(t, l.Annotate(ctx, offset, "processed-by", ""))
+		r, err = l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Annotations, map[string]string{"flagged": "true"})
+
+		assert.NilError(t, l.Annotate(ctx, offset, "flagged", ""))
+		r, err = l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.Assert(t, r.Metadata.Annotations == nil)
+	})
+
+	t.Run("removes annotations of purged records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		assert.NilError(t, l.Annotate(ctx, 0, "flagged", "true"))
+
+		for i := 0; i < 4; i++ {
+			_, err = l.Write(ctx, []byte("a"))
+			assert.NilError(t, err)
+		}
+
+		assert.NilError(t, l.Annotate(ctx, 4, "flagged", "true"))
+		assert.Equal(t, len(l.annotations), 1)
+	})
+}
@@ -0,0 +1,149 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WriteBatch creates new records in the log with the given data in order under
+// a single lock acquisition. The batch is atomic, i.e. either all records are
+// written or none. The write options are applied to every record of the
+// batch. The offsets of the written records are returned. If an error occurs,
+// no record is written and the error is returned.
+//
+// Safe for concurrent use.
+func (l *Log) WriteBatch(ctx context.Context, data [][]byte, options ...WriteOption) ([]Offset, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data provided")
+	}
+
+	batch := make([]batchEntry, 0, len(data))
+	for _, d := range data {
+		batch = append(batch, batchEntry{data: d, options: options})
+	}
+
+	if l.latency != nil {
+		defer l.latency.write.since(l.clock, l.clock.Now())
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer l.mu.Unlock()
+
+	return l.writeBatch(ctx, batch)
+}
+
+// batchEntry is a record of a batch write
+type batchEntry struct {
+	data    []byte
+	options []WriteOption
+}
+
+// writeBatch writes the given records in order. All records are validated
+// before the first record is written, i.e. the batch is either fully written
+// or not at all. Must be protected with a lock by the caller.
+func (l *Log) writeBatch(ctx context.Context, batch []batchEntry) ([]Offset, error) {
+	configs, err := l.prepareBatch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.commitBatch(batch, configs), nil
+}
+
+// prepareBatch validates the given records as if they were written in order
+// and returns their write configurations. Must be protected with a lock by the
+// caller.
+func (l *Log) prepareBatch(ctx context.Context, batch []batchEntry) ([]writeConfig, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var (
+		configs = make([]writeConfig, 0, len(batch))
+		pending int // payload bytes of the batch up to the current record
+	)
+	for i, e := range batch {
+		wc := newWriteConfig(e.options)
+		if wc.sequence != nil {
+			return nil, fmt.Errorf("validate record %d: sequence numbers not supported for batch writes", i)
+		}
+
+		if err := l.validateWrite(e.data, wc); err != nil {
+			return nil, fmt.Errorf("validate record %d: %w", i, err)
+		}
+
+		pending += len(e.data)
+		if err := l.admit(pending, wc.priority); err != nil {
+			return nil, fmt.Errorf("validate record %d: %w", i, err)
+		}
+
+		if err := l.checkPurge(l.purgedBy(i + 1)); err != nil {
+			return nil, fmt.Errorf("validate record %d: %w", i, err)
+		}
+
+		// the expected offset applies to the batch, i.e. its first record
+		wc.expected = nil
+
+		l.extractHeaders(ctx, &wc)
+		configs = append(configs, wc)
+	}
+
+	return configs, nil
+}
+
+// commitBatch writes the given records prepared with prepareBatch(). Must be
+// protected with a lock by the caller.
+func (l *Log) commitBatch(batch []batchEntry, configs []writeConfig) []Offset {
+	offsets := make([]Offset, 0, len(batch))
+	for i, e := range batch {
+		// context is not checked per record to not abort a partially written
+		// batch
+		offset, err := l.write(context.Background(), e.data, withConfig(configs[i]))
+		if err != nil {
+			panic("batch write error: " + err.Error()) // abnormal program state
+		}
+		offsets = append(offsets, offset)
+	}
+
+	return offsets
+}
+
+// ReadBatch reads up to max records from the log starting at the given offset
+// under a single lock acquisition, e.g. for catch-up readers after restoring a
+// checkpoint. Fewer records are returned if the end of the log is reached.
+// Gaps of logs with sparse offsets are skipped, i.e. do not count towards max.
+// The start offset must be readable, i.e. ReadBatch returns the same errors
+// as Read() for the start offset.
+//
+// Safe for concurrent use.
+func (l *Log) ReadBatch(ctx context.Context, start Offset, max int) ([]Record, error) {
+	if max <= 0 {
+		return nil, errors.New("max must be greater than 0")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// fail early if the start offset is not readable
+	if _, err := l.read(ctx, start); err != nil && !errors.Is(err, ErrOffsetGap) {
+		return nil, err
+	}
+
+	records := make([]Record, 0, minInt(max, int(l.offset-start)))
+	for offset := start; offset < l.offset && len(records) < max; offset++ {
+		r, err := l.read(ctx, offset)
+		if errors.Is(err, ErrOffsetGap) {
+			offset = l.nextWritten(offset) - 1
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
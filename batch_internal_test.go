@@ -0,0 +1,136 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_WriteBatch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		start       Offset
+		segSize     int
+		maxSize     int
+		data        [][]byte
+		options     []WriteOption
+		wantOffsets []Offset
+		wantErr     error
+		wantErrMsg  string
+	}{
+		{name: "fails with empty batch", segSize: 10, maxSize: 10, wantErrMsg: "no data provided"},
+		{name: "fails with empty record", segSize: 10, maxSize: 10, data: [][]byte{[]byte("a"), nil}, wantErrMsg: "validate record 1: no data provided"},
+		{name: "fails with too large record", segSize: 10, maxSize: 1, data: [][]byte{[]byte("a"), []byte("bb")}, wantErr: ErrRecordTooLarge},
+		{name: "fails with stale epoch", segSize: 10, maxSize: 10, data: [][]byte{[]byte("a")}, options: []WriteOption{WithEpoch(1)}, wantErr: ErrStaleEpoch},
+		{name: "writes batch", start: 10, segSize: 10, maxSize: 10, data: [][]byte{[]byte("a"), []byte("b"), []byte("c")}, wantOffsets: []Offset{10, 11, 12}},
+		{name: "writes batch across segments", segSize: 2, maxSize: 10, data: [][]byte{[]byte("a"), []byte("b"), []byte("c")}, wantOffsets: []Offset{0, 1, 2}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithStartOffset(tc.start), WithMaxSegmentSize(tc.segSize), WithMaxRecordSizeBytes(tc.maxSize))
+			assert.NilError(t, err)
+
+			offsets, err := l.WriteBatch(ctx, tc.data, tc.options...)
+			switch {
+			case tc.wantErr != nil:
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+			case tc.wantErrMsg != "":
+				assert.ErrorContains(t, err, tc.wantErrMsg)
+			default:
+				assert.NilError(t, err)
+			}
+			assert.DeepEqual(t, offsets, tc.wantOffsets)
+
+			if err != nil {
+				// nothing written
+				assert.Assert(t, l.IsEmpty(ctx))
+				return
+			}
+
+			for i, offset := range offsets {
+				r, err := l.Read(ctx, offset)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, r.Data, tc.data[i])
+			}
+		})
+	}
+}
+
+func TestLog_ReadBatch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		start      Offset
+		max        int
+		wantData   []string
+		wantErr    error
+		wantErrMsg string
+	}{
+		{name: "fails with invalid max", start: 10, max: 0, wantErrMsg: "max must be greater than 0"},
+		{name: "fails with purged offset", start: 10, max: 2, wantErr: ErrOutOfRange},
+		{name: "fails with future offset", start: 20, max: 2, wantErr: ErrFutureOffset},
+		{name: "reads up to max records", start: 12, max: 2, wantData: []string{"c", "d"}},
+		{name: "reads until end of log", start: 13, max: 10, wantData: []string{"d", "e", "f"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithStartOffset(10), WithMaxSegmentSize(2))
+			assert.NilError(t, err)
+
+			// purges offsets 10 and 11
+			_, err = l.WriteBatch(ctx, [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e"), []byte("f")})
+			assert.NilError(t, err)
+
+			records, err := l.ReadBatch(ctx, tc.start, tc.max)
+			switch {
+			case tc.wantErr != nil:
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+				return
+			case tc.wantErrMsg != "":
+				assert.ErrorContains(t, err, tc.wantErrMsg)
+				return
+			}
+			assert.NilError(t, err)
+
+			data := make([]string, 0, len(records))
+			for i, r := range records {
+				assert.Equal(t, r.Metadata.Offset, tc.start+Offset(i))
+				data = append(data, string(r.Data))
+			}
+			assert.DeepEqual(t, data, tc.wantData)
+		})
+	}
+
+	t.Run("fails with empty log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.ReadBatch(ctx, 0, 1)
+		assert.Assert(t, errors.Is(err, ErrEmptyLog))
+	})
+
+	t.Run("skips gaps", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets(), WithMaxSegmentSize(10))
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{0, 3, 4} {
+			_, err = l.WriteAt(ctx, offset, []byte("data"))
+			assert.NilError(t, err)
+		}
+
+		records, err := l.ReadBatch(ctx, 1, 2)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), 2)
+		assert.Equal(t, records[0].Metadata.Offset, Offset(3))
+		assert.Equal(t, records[1].Metadata.Offset, Offset(4))
+	})
+}
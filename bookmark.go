@@ -0,0 +1,93 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBookmarkNotFound is returned when a bookmark does not exist in a log
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// SetBookmark creates or moves the named bookmark to the given offset, e.g. to
+// mark the first record written after a deployment for operational replays.
+// The offset can be the next write offset of the log, i.e. a bookmark can be
+// set before the bookmarked record is written. See FromBookmark().
+//
+// Safe for concurrent use.
+func (l *Log) SetBookmark(ctx context.Context, name string, offset Offset) error {
+	if name == "" {
+		return errors.New("bookmark name must not be empty")
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.mu.Unlock()
+
+	if offset < l.conf.startOffset || offset > l.offset {
+		return fmt.Errorf("%w: bookmark offset %d", ErrOutOfRange, offset)
+	}
+
+	if l.bookmarks == nil {
+		l.bookmarks = make(map[string]Offset)
+	}
+	l.bookmarks[name] = offset
+
+	return nil
+}
+
+// Bookmark returns the offset of the named bookmark. If the bookmark does not
+// exist, ErrBookmarkNotFound is returned.
+//
+// Safe for concurrent use.
+func (l *Log) Bookmark(_ context.Context, name string) (Offset, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.bookmark(name)
+}
+
+// DeleteBookmark removes the named bookmark. If the bookmark does not exist,
+// ErrBookmarkNotFound is returned.
+//
+// Safe for concurrent use.
+func (l *Log) DeleteBookmark(ctx context.Context, name string) error {
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.mu.Unlock()
+
+	if _, err := l.bookmark(name); err != nil {
+		return err
+	}
+	delete(l.bookmarks, name)
+
+	return nil
+}
+
+// bookmark returns the offset of the named bookmark. Must be protected with a
+// lock by the caller.
+func (l *Log) bookmark(name string) (Offset, error) {
+	offset, ok := l.bookmarks[name]
+	if !ok {
+		return -1, fmt.Errorf("%w: %s", ErrBookmarkNotFound, name)
+	}
+	return offset, nil
+}
+
+// FromBookmark starts the stream at the offset of the named bookmark instead of
+// the specified start offset (see SetBookmark()). The bookmark is resolved
+// when the stream is created, i.e. moving the bookmark afterwards does not
+// affect the stream. If the bookmark does not exist, the stream terminates
+// with ErrBookmarkNotFound.
+func FromBookmark(name string) StreamOption {
+	return func(sc *streamConfig) error {
+		if name == "" {
+			return errors.New("bookmark name must not be empty")
+		}
+
+		sc.bookmark = name
+		return nil
+	}
+}
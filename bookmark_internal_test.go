@@ -0,0 +1,84 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Bookmark(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	err = l.SetBookmark(ctx, "", 0)
+	assert.ErrorContains(t, err, "must not be empty")
+
+	err = l.SetBookmark(ctx, "future", 1)
+	assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+	_, err = l.Bookmark(ctx, "deploy")
+	assert.Assert(t, errors.Is(err, ErrBookmarkNotFound))
+
+	// bookmark the next record
+	assert.NilError(t, l.SetBookmark(ctx, "deploy", 0))
+	_, err = l.Write(ctx, []byte("data"))
+	assert.NilError(t, err)
+
+	offset, err := l.Bookmark(ctx, "deploy")
+	assert.NilError(t, err)
+	assert.Equal(t, offset, Offset(0))
+
+	assert.NilError(t, l.SetBookmark(ctx, "deploy", 1))
+	offset, err = l.Bookmark(ctx, "deploy")
+	assert.NilError(t, err)
+	assert.Equal(t, offset, Offset(1))
+
+	assert.NilError(t, l.DeleteBookmark(ctx, "deploy"))
+	err = l.DeleteBookmark(ctx, "deploy")
+	assert.Assert(t, errors.Is(err, ErrBookmarkNotFound))
+}
+
+func TestLog_StreamFromBookmark(t *testing.T) {
+	t.Run("fails with unknown bookmark", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		stream, errs := l.Stream(ctx, 0, FromBookmark("unknown"))
+		assert.Assert(t, errors.Is(<-errs, ErrBookmarkNotFound))
+		_, ok := <-stream
+		assert.Assert(t, !ok)
+	})
+
+	t.Run("starts at bookmark", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.SetBookmark(ctx, "deploy-v2", 3))
+
+		streamCtx, streamCancel := context.WithCancel(ctx)
+		stream, errs := l.Stream(streamCtx, 0, FromBookmark("deploy-v2"))
+
+		// moving the bookmark does not affect the stream
+		assert.NilError(t, l.SetBookmark(ctx, "deploy-v2", 0))
+
+		r := <-stream
+		assert.Equal(t, r.Record.Metadata.Offset, Offset(3))
+		r = <-stream
+		assert.Equal(t, r.Record.Metadata.Offset, Offset(4))
+
+		streamCancel()
+		assert.Assert(t, errors.Is(<-errs, context.Canceled))
+	})
+}
@@ -0,0 +1,233 @@
+package memlog
+
+import (
+	"container/heap"
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// DecodeCache is a bounded least-recently-used cache of records of a log
+// decoded into values of type T, so that records re-read by many subscribers
+// are decoded only once. Cached records are not served once they are no longer
+// readable from the log, e.g. purged, expired or deleted.
+//
+// Cached values are shared by all readers of the cache and must not be
+// modified.
+//
+// Safe for concurrent use.
+type DecodeCache[T any] struct {
+	log   *Log
+	codec Codec
+	size  int
+
+	mu      sync.Mutex
+	entries map[Offset]*list.Element
+	lru     *list.List // front is most recently used
+	offsets offsetHeap // cached offsets, may contain evicted offsets
+	hits    int
+	misses  int
+}
+
+// NewDecodeCache creates a decode cache for the log holding up to size decoded
+// records
+func NewDecodeCache[T any](l *Log, codec Codec, size int) (*DecodeCache[T], error) {
+	if l == nil {
+		return nil, errors.New("log must not be nil")
+	}
+
+	if codec == nil {
+		return nil, errors.New("codec must not be nil")
+	}
+
+	if size <= 0 {
+		return nil, errors.New("size must be greater than 0")
+	}
+
+	return &DecodeCache[T]{
+		log:     l,
+		codec:   codec,
+		size:    size,
+		entries: make(map[Offset]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// Read returns the decoded record at the given offset, reading and decoding it
+// from the log if not cached. If the record cannot be decoded, a *DecodeError
+// is returned.
+func (c *DecodeCache[T]) Read(ctx context.Context, offset Offset) (TypedRecord[T], error) {
+	earliest, _ := c.log.Range(ctx)
+	c.Invalidate(earliest)
+
+	if c.log.stale(offset) {
+		// the log returns the error, e.g. ErrExpired
+		c.remove(offset)
+	} else if tr, ok := c.get(offset); ok {
+		return tr, nil
+	}
+
+	r, err := c.log.Read(ctx, offset)
+	if err != nil {
+		return TypedRecord[T]{}, err
+	}
+
+	return c.decode(r)
+}
+
+// Decode returns the decoded record, e.g. received from a stream, using the
+// cached value if the record at its offset has been decoded before. If the
+// record cannot be decoded, a *DecodeError is returned.
+func (c *DecodeCache[T]) Decode(r Record) (TypedRecord[T], error) {
+	if IsTombstone(r) {
+		c.remove(r.Metadata.Offset)
+	} else if tr, ok := c.get(r.Metadata.Offset); ok {
+		return tr, nil
+	}
+
+	return c.decode(r)
+}
+
+// Stream is like Decode() but decodes the records of the stream with the
+// cache
+func (c *DecodeCache[T]) Stream(ctx context.Context, stream <-chan StreamRecord, errs <-chan error) (<-chan TypedRecord[T], <-chan error) {
+	return decodeStream(ctx, stream, errs, c.Decode)
+}
+
+// Invalidate removes all cached records with an offset lower than the given
+// offset, e.g. called from an EventPurged handler (see WithEventHandler()).
+// Purged records are also invalidated on Read. Other records which are no
+// longer readable, e.g. expired records, are removed when detected on Read.
+func (c *DecodeCache[T]) Invalidate(before Offset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.offsets) > 0 && c.offsets[0] < before {
+		offset := heap.Pop(&c.offsets).(Offset)
+		if e, ok := c.entries[offset]; ok {
+			c.lru.Remove(e)
+			delete(c.entries, offset)
+		}
+	}
+}
+
+// Len returns the number of cached records
+func (c *DecodeCache[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Stats returns the number of cache hits and misses
+func (c *DecodeCache[T]) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses
+}
+
+// remove removes the cached record at the given offset, if any
+func (c *DecodeCache[T]) remove(offset Offset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[offset]; ok {
+		c.lru.Remove(e)
+		delete(c.entries, offset)
+	}
+}
+
+func (c *DecodeCache[T]) get(offset Offset) (TypedRecord[T], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[offset]
+	if !ok {
+		c.misses++
+		return TypedRecord[T]{}, false
+	}
+
+	c.hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(TypedRecord[T]), true
+}
+
+func (c *DecodeCache[T]) decode(r Record) (TypedRecord[T], error) {
+	var v T
+	if err := c.codec.Unmarshal(r.Data, &v); err != nil {
+		return TypedRecord[T]{}, &DecodeError{Offset: r.Metadata.Offset, Err: err}
+	}
+
+	tr := TypedRecord[T]{Metadata: r.Metadata, Data: v}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[r.Metadata.Offset]; ok {
+		// decoded concurrently
+		c.lru.MoveToFront(e)
+		return e.Value.(TypedRecord[T]), nil
+	}
+
+	c.entries[r.Metadata.Offset] = c.lru.PushFront(tr)
+	heap.Push(&c.offsets, r.Metadata.Offset)
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(TypedRecord[T]).Metadata.Offset)
+	}
+
+	if len(c.offsets) > 2*c.size {
+		// drop evicted offsets
+		c.offsets = c.offsets[:0]
+		for offset := range c.entries {
+			c.offsets = append(c.offsets, offset)
+		}
+		heap.Init(&c.offsets)
+	}
+
+	return tr, nil
+}
+
+// offsetHeap is a min-heap of offsets implementing heap.Interface
+type offsetHeap []Offset
+
+func (h offsetHeap) Len() int           { return len(h) }
+func (h offsetHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h offsetHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *offsetHeap) Push(x any) {
+	*h = append(*h, x.(Offset))
+}
+
+func (h *offsetHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// stale returns true if the record at the given offset can not be served from a
+// cache, e.g. because it has been purged, has expired or has been deleted (see
+// Delete()). Payloads are not accessed.
+//
+// Safe for concurrent use.
+func (l *Log) stale(offset Offset) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed || offset >= l.offset || offset < l.conf.startOffset {
+		return true
+	}
+
+	s, err := l.getSegment(offset)
+	if err != nil || !s.live(offset, l.expiryTime()) {
+		return true
+	}
+
+	_, deleted := s.data[offset-s.start].Metadata.Headers[HeaderTombstone]
+	return deleted
+}
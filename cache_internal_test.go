@@ -0,0 +1,169 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestDecodeCache(t *testing.T) {
+	type event struct {
+		ID string `json:"id"`
+	}
+
+	ctx := context.Background()
+
+	t.Run("fails with invalid input", func(t *testing.T) {
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = NewDecodeCache[event](nil, JSONCodec{}, 1)
+		assert.ErrorContains(t, err, "log must not be nil")
+
+		_, err = NewDecodeCache[event](l, nil, 1)
+		assert.ErrorContains(t, err, "codec must not be nil")
+
+		_, err = NewDecodeCache[event](l, JSONCodec{}, 0)
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("decodes records once and evicts least recently used", func(t *testing.T) {
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, id := range []string{"1", "2", "3"} {
+			_, err = l.Write(ctx, []byte(`{"id":"`+id+`"}`))
+			assert.NilError(t, err)
+		}
+		_, err = l.Write(ctx, []byte("not json"))
+		assert.NilError(t, err)
+
+		c, err := NewDecodeCache[event](l, JSONCodec{}, 2)
+		assert.NilError(t, err)
+
+		tr, err := c.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Equal(t, tr.Data.ID, "1")
+
+		tr, err = c.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Equal(t, tr.Data.ID, "1")
+
+		hits, misses := c.Stats()
+		assert.Equal(t, hits, 1)
+		assert.Equal(t, misses, 1)
+
+		_, err = c.Read(ctx, 1)
+		assert.NilError(t, err)
+		_, err = c.Read(ctx, 0) // 1 least recently used
+		assert.NilError(t, err)
+		_, err = c.Read(ctx, 2)
+		assert.NilError(t, err)
+		assert.Equal(t, c.Len(), 2)
+
+		_, err = c.Read(ctx, 1)
+		assert.NilError(t, err)
+		hits, misses = c.Stats()
+		assert.Equal(t, hits, 2)
+		assert.Equal(t, misses, 4)
+
+		_, err = c.Read(ctx, 3)
+		var decodeErr *DecodeError
+		assert.Assert(t, errors.As(err, &decodeErr))
+		assert.Equal(t, decodeErr.Offset, Offset(3))
+
+		_, err = c.Read(ctx, 4)
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	})
+
+	t.Run("invalidates purged records", func(t *testing.T) {
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		for _, id := range []string{"1", "2", "3", "4"} {
+			_, err = l.Write(ctx, []byte(`{"id":"`+id+`"}`))
+			assert.NilError(t, err)
+		}
+
+		c, err := NewDecodeCache[event](l, JSONCodec{}, 10)
+		assert.NilError(t, err)
+
+		for offset := Offset(0); offset < 4; offset++ {
+			_, err = c.Read(ctx, offset)
+			assert.NilError(t, err)
+		}
+		assert.Equal(t, c.Len(), 4)
+
+		// purges offsets 0 and 1
+		_, err = l.Write(ctx, []byte(`{"id":"5"}`))
+		assert.NilError(t, err)
+
+		_, err = c.Read(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+		assert.Equal(t, c.Len(), 2)
+	})
+
+	t.Run("does not serve expired or deleted records", func(t *testing.T) {
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte(`{"id":"1"}`), WithTTL(time.Minute))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte(`{"id":"2"}`))
+		assert.NilError(t, err)
+
+		c, err := NewDecodeCache[event](l, JSONCodec{}, 10)
+		assert.NilError(t, err)
+
+		for offset := Offset(0); offset < 2; offset++ {
+			_, err = c.Read(ctx, offset)
+			assert.NilError(t, err)
+		}
+		assert.Equal(t, c.Len(), 2)
+
+		mockClock.Add(time.Minute)
+		_, err = c.Read(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrExpired))
+
+		assert.NilError(t, l.Delete(ctx, 1))
+		_, err = c.Read(ctx, 1)
+		var decodeErr *DecodeError
+		assert.Assert(t, errors.As(err, &decodeErr))
+		assert.Equal(t, c.Len(), 0)
+	})
+
+	t.Run("decodes stream with cache", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, id := range []string{"1", "2"} {
+			_, err = l.Write(ctx, []byte(`{"id":"`+id+`"}`))
+			assert.NilError(t, err)
+		}
+
+		c, err := NewDecodeCache[event](l, JSONCodec{}, 10)
+		assert.NilError(t, err)
+
+		for i := 0; i < 2; i++ {
+			stream, errs := l.Stream(ctx, 0)
+			typedCh, _ := c.Stream(ctx, stream, errs)
+
+			for _, want := range []string{"1", "2"} {
+				tr := <-typedCh
+				assert.Equal(t, tr.Data.ID, want)
+			}
+		}
+
+		hits, misses := c.Stats()
+		assert.Equal(t, hits, 2)
+		assert.Equal(t, misses, 2)
+	})
+}
@@ -0,0 +1,101 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrCheckpointNotFound is returned by a CheckpointStore when no checkpoint
+	// exists for a consumer
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+	// ErrLogMismatch is returned when resuming from a checkpoint created
+	// against another log incarnation, see Log.ID()
+	ErrLogMismatch = errors.New("checkpoint of another log")
+)
+
+// Checkpoint is the position of a consumer in a log
+type Checkpoint struct {
+	// LogID is the identity of the log the checkpoint was created against,
+	// see Log.ID()
+	LogID string `json:"logID"`
+	// Offset is the offset of the last processed record
+	Offset Offset `json:"offset"`
+}
+
+// CheckpointStore persists the checkpoint of the last processed record per
+// consumer
+type CheckpointStore interface {
+	// Load returns the checkpoint of the consumer. If no checkpoint exists,
+	// ErrCheckpointNotFound is returned.
+	Load(ctx context.Context, consumer string) (Checkpoint, error)
+	// Save stores the checkpoint of the consumer
+	Save(ctx context.Context, consumer string, checkpoint Checkpoint) error
+}
+
+// verify returns ErrLogMismatch if the checkpoint was created against another
+// log
+func (c Checkpoint) verify(l *Log) error {
+	if c.LogID != l.ID() {
+		return fmt.Errorf("%w: checkpoint log %q, log %q", ErrLogMismatch, c.LogID, l.ID())
+	}
+	return nil
+}
+
+// MemoryCheckpointStore is an in-memory CheckpointStore.
+//
+// Safe for concurrent use.
+type MemoryCheckpointStore struct {
+	mu          sync.RWMutex
+	checkpoints map[string]Checkpoint
+	saves       int
+}
+
+var _ CheckpointStore = (*MemoryCheckpointStore)(nil)
+
+// NewMemoryCheckpointStore creates an empty in-memory CheckpointStore
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints: make(map[string]Checkpoint),
+	}
+}
+
+// Load returns the checkpoint of the consumer
+func (s *MemoryCheckpointStore) Load(ctx context.Context, consumer string) (Checkpoint, error) {
+	if ctx.Err() != nil {
+		return Checkpoint{}, ctx.Err()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	checkpoint, ok := s.checkpoints[consumer]
+	if !ok {
+		return Checkpoint{}, fmt.Errorf("%w: %s", ErrCheckpointNotFound, consumer)
+	}
+	return checkpoint, nil
+}
+
+// Save stores the checkpoint of the consumer
+func (s *MemoryCheckpointStore) Save(ctx context.Context, consumer string, checkpoint Checkpoint) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[consumer] = checkpoint
+	s.saves++
+	return nil
+}
+
+// Saves returns the number of Save calls, e.g. to measure commit batching
+func (s *MemoryCheckpointStore) Saves() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.saves
+}
@@ -0,0 +1,29 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMemoryCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryCheckpointStore()
+
+	_, err := s.Load(ctx, "c")
+	assert.Assert(t, errors.Is(err, ErrCheckpointNotFound))
+
+	assert.NilError(t, s.Save(ctx, "c", Checkpoint{LogID: "log", Offset: 10}))
+	assert.NilError(t, s.Save(ctx, "c", Checkpoint{LogID: "log", Offset: 20}))
+
+	checkpoint, err := s.Load(ctx, "c")
+	assert.NilError(t, err)
+	assert.Equal(t, checkpoint, Checkpoint{LogID: "log", Offset: 20})
+	assert.Equal(t, s.Saves(), 2)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Assert(t, errors.Is(s.Save(cancelled, "c", Checkpoint{LogID: "log", Offset: 30}), context.Canceled))
+}
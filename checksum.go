@@ -0,0 +1,52 @@
+package memlog
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// ErrChecksumMismatch is returned when reading a record whose payload does not
+// match its checksum, see WithChecksumVerification()
+var ErrChecksumMismatch = errors.New("record checksum mismatch")
+
+// checksumTable is the CRC-32C (Castagnoli) table, which is hardware
+// accelerated on most platforms
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WithChecksums stores a CRC-32C checksum of the payload of every written
+// record in Header.Checksum, e.g. to detect silent corruption of payloads
+// when records are passed on to other systems. Checksums can not be combined
+// with minimal metadata (see WithMinimalMetadata()).
+func WithChecksums() Option {
+	return func(log *Log) error {
+		log.conf.checksums = true
+		return nil
+	}
+}
+
+// WithChecksumVerification stores checksums like WithChecksums() and verifies
+// the payload of every read record against its checksum. Reading a corrupted
+// record fails with ErrChecksumMismatch. Payloads of deleted records (see
+// Delete()) are not verified.
+func WithChecksumVerification() Option {
+	return func(log *Log) error {
+		log.conf.checksums = true
+		log.conf.verifyChecksums = true
+		return nil
+	}
+}
+
+// checksum returns the CRC-32C checksum of the given payload
+func checksum(data []byte) uint32 {
+	return crc32.Checksum(data, checksumTable)
+}
+
+// verifyChecksum returns ErrChecksumMismatch if the payload of the record does
+// not match its checksum
+func verifyChecksum(r Record) error {
+	if sum := checksum(r.Data); sum != r.Metadata.Checksum {
+		return fmt.Errorf("%w: offset %d: checksum %08x, expected %08x", ErrChecksumMismatch, r.Metadata.Offset, sum, r.Metadata.Checksum)
+	}
+	return nil
+}
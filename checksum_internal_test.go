@@ -0,0 +1,77 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Checksums(t *testing.T) {
+	data := []byte("payload")
+	want := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+
+	t.Run("no checksum by default", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, data)
+		assert.NilError(t, err)
+
+		r, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Checksum, uint32(0))
+	})
+
+	t.Run("fails with minimal metadata", func(t *testing.T) {
+		_, err := New(context.Background(), WithChecksums(), WithMinimalMetadata())
+		assert.ErrorContains(t, err, "cannot be combined")
+	})
+
+	testCases := []struct {
+		name    string
+		option  Option
+		wantErr error
+	}{
+		{name: "stores checksum without verification", option: WithChecksums()},
+		{name: "detects corruption with verification", option: WithChecksumVerification(), wantErr: ErrChecksumMismatch},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, tc.option)
+			assert.NilError(t, err)
+
+			for i := 0; i < 2; i++ {
+				_, err = l.Write(ctx, data)
+				assert.NilError(t, err)
+			}
+
+			r, err := l.Read(ctx, 0)
+			assert.NilError(t, err)
+			assert.Equal(t, r.Metadata.Checksum, want)
+
+			// silent corruption of the stored payload
+			l.active.data[0].Data[0] ^= 0xff
+
+			_, err = l.Read(ctx, 0)
+			if tc.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+			} else {
+				assert.NilError(t, err)
+			}
+
+			// deleted records remain readable
+			assert.NilError(t, l.Delete(ctx, 1))
+			r, err = l.Read(ctx, 1)
+			assert.NilError(t, err)
+			assert.Assert(t, IsTombstone(r))
+			assert.Equal(t, r.Metadata.Checksum, uint32(0))
+		})
+	}
+}
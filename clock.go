@@ -0,0 +1,105 @@
+package memlog
+
+import (
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// TimeSource is the time source of a log, see WithTimeSource(). Adapters exist
+// for the standard library (SystemTimeSource()) and github.com/benbjohnson/clock
+// (ClockTimeSource()). Other clocks, e.g. github.com/jonboulle/clockwork, can
+// be used by implementing the interface with a small adapter, so deterministic
+// tests do not require a specific clock dependency.
+type TimeSource interface {
+	// Now returns the current time
+	Now() time.Time
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+	// NewTimer creates a timer firing once after the given duration
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a ticker firing repeatedly at the given interval
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f in its own goroutine after the given duration
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a TimeSource
+type Timer interface {
+	// Chan returns the channel receiving the time when the timer fires. Timers
+	// created with AfterFunc() may return a nil channel.
+	Chan() <-chan time.Time
+	// Stop prevents the timer from firing and returns false if the timer has
+	// already fired or been stopped
+	Stop() bool
+}
+
+// Ticker is a ticker created by a TimeSource
+type Ticker interface {
+	// Chan returns the channel receiving the time of each tick
+	Chan() <-chan time.Time
+	// Stop turns off the ticker
+	Stop()
+}
+
+// SystemTimeSource returns a TimeSource using the time package of the
+// standard library
+func SystemTimeSource() TimeSource {
+	return systemTimeSource{}
+}
+
+type systemTimeSource struct{}
+
+func (systemTimeSource) Now() time.Time                  { return time.Now() }
+func (systemTimeSource) Since(t time.Time) time.Duration { return time.Since(t) }
+
+func (systemTimeSource) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemTimeSource) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemTimeSource) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) Chan() <-chan time.Time { return t.C }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) Chan() <-chan time.Time { return t.C }
+
+// ClockTimeSource returns a TimeSource using the given
+// github.com/benbjohnson/clock clock, e.g. a mock clock in tests
+func ClockTimeSource(c clock.Clock) TimeSource {
+	return clockTimeSource{c}
+}
+
+type clockTimeSource struct{ c clock.Clock }
+
+func (s clockTimeSource) Now() time.Time                  { return s.c.Now() }
+func (s clockTimeSource) Since(t time.Time) time.Duration { return s.c.Since(t) }
+
+func (s clockTimeSource) NewTimer(d time.Duration) Timer {
+	return clockTimer{s.c.Timer(d)}
+}
+
+func (s clockTimeSource) NewTicker(d time.Duration) Ticker {
+	return clockTicker{s.c.Ticker(d)}
+}
+
+func (s clockTimeSource) AfterFunc(d time.Duration, f func()) Timer {
+	return clockTimer{s.c.AfterFunc(d, f)}
+}
+
+type clockTimer struct{ *clock.Timer }
+
+func (t clockTimer) Chan() <-chan time.Time { return t.C }
+
+type clockTicker struct{ *clock.Ticker }
+
+func (t clockTicker) Chan() <-chan time.Time { return t.C }
@@ -0,0 +1,80 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+// fixedTimeSource is a TimeSource returning a fixed time, i.e. a minimal
+// custom clock adapter
+type fixedTimeSource struct {
+	TimeSource
+	now time.Time
+}
+
+func (s fixedTimeSource) Now() time.Time {
+	return s.now
+}
+
+func TestWithTimeSource(t *testing.T) {
+	t.Run("fails with nil time source", func(t *testing.T) {
+		_, err := New(context.Background(), WithTimeSource(nil))
+		assert.ErrorContains(t, err, "must not be nil")
+	})
+
+	t.Run("uses custom time source", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		l, err := New(ctx, WithTimeSource(fixedTimeSource{TimeSource: SystemTimeSource(), now: now}))
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Created, now)
+	})
+}
+
+func TestSystemTimeSource(t *testing.T) {
+	ts := SystemTimeSource()
+
+	timer := ts.NewTimer(time.Millisecond)
+	<-timer.Chan()
+	assert.Assert(t, !timer.Stop())
+
+	ticker := ts.NewTicker(time.Millisecond)
+	<-ticker.Chan()
+	<-ticker.Chan()
+	ticker.Stop()
+
+	called := make(chan struct{})
+	ts.AfterFunc(time.Millisecond, func() { close(called) })
+	<-called
+
+	start := ts.Now()
+	assert.Assert(t, ts.Since(start) >= 0)
+}
+
+func TestClockTimeSource(t *testing.T) {
+	mockClock := clock.NewMock()
+	ts := ClockTimeSource(mockClock)
+
+	start := ts.Now()
+	timer := ts.NewTimer(time.Second)
+	ticker := ts.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	mockClock.Add(time.Second)
+	assert.Equal(t, ts.Since(start), time.Second)
+	<-timer.Chan()
+	<-ticker.Chan()
+
+	stopped := ts.AfterFunc(time.Second, func() {})
+	assert.Assert(t, stopped.Stop())
+}
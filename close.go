@@ -0,0 +1,40 @@
+package memlog
+
+// Close closes the log and releases its records and internal resources, e.g.
+// off-heap memory, to signal a clean shutdown to all goroutines using the log.
+// Writes and reads of a closed log fail with ErrClosed. Active streams are
+// force-closed with ErrSubscriptionClosed, channels of watchers (see
+// Notify()) are closed and a blocked Drain() returns ErrClosed. Closing a
+// closed log returns ErrClosed.
+//
+// Safe for concurrent use.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	l.close()
+	l.mu.Unlock()
+
+	l.subscriptions.closeAll()
+	return nil
+}
+
+// close purges all segments and marks the log as closed. Must be protected with
+// a lock by the caller.
+func (l *Log) close() {
+	l.closed = true
+	close(l.done)
+
+	if l.history != nil {
+		l.purge(l.history)
+	}
+	l.purge(l.active)
+
+	// keep an empty segment so that offset ranges remain valid
+	l.history = nil
+	l.active = &segment{start: l.offset, sealed: true}
+	l.annotations = nil
+	l.emit(EventClosed, -1, -1)
+}
@@ -0,0 +1,77 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Close(t *testing.T) {
+	t.Run("rejects writes and reads", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 5)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		assert.NilError(t, l.Close())
+		assert.Assert(t, errors.Is(l.Close(), ErrClosed))
+		assert.Equal(t, l.RetainedBytes(), 0)
+
+		_, err = l.Write(ctx, data[0])
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		_, err = l.WriteBatch(ctx, data)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		_, err = l.Read(ctx, 4)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		_, err = l.ReadBatch(ctx, 0, 10)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		err = l.Trim(ctx, 3)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		err = l.Delete(ctx, 4)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		records, err := l.Tail(ctx, 3)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), 0)
+	})
+
+	t.Run("unblocks streams and drain", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "first"))
+		assert.NilError(t, err)
+
+		// consumer behind the latest offset blocks draining
+		assert.NilError(t, l.Commit(ctx, "c1", -1))
+
+		drainErr := make(chan error, 1)
+		go func() {
+			drainErr <- l.Drain(ctx)
+		}()
+
+		stream, errs := l.Stream(ctx, 0)
+		<-stream
+
+		assert.NilError(t, l.Close())
+
+		assert.Assert(t, errors.Is(<-errs, ErrSubscriptionClosed))
+		assert.Assert(t, errors.Is(<-drainErr, ErrClosed))
+	})
+}
@@ -0,0 +1,207 @@
+package memlog
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// ContentTypeJSON is the media type of the JSON codec
+	ContentTypeJSON = "application/json"
+	// ContentTypeGob is the media type of the gob codec
+	ContentTypeGob = "application/x-gob"
+)
+
+// ErrCodecNotFound is returned when no codec is registered for a content type
+var ErrCodecNotFound = errors.New("codec not found")
+
+// Codec encodes values into record data and decodes record data into values
+type Codec interface {
+	// ContentType returns the media type handled by the codec, e.g.
+	// "application/json"
+	ContentType() string
+	// Marshal encodes v into record data
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes record data into v
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is a Codec using encoding/json
+type JSONCodec struct{}
+
+// ContentType returns ContentTypeJSON
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Marshal encodes v as JSON
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec is a Codec using encoding/gob. Every record is encoded as a
+// self-contained gob stream, i.e. type information is repeated in every
+// record.
+type GobCodec struct{}
+
+// ContentType returns ContentTypeGob
+func (GobCodec) ContentType() string {
+	return ContentTypeGob
+}
+
+// Marshal encodes v as gob
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes gob data into v
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// CodecRegistry maps content types to codecs. Content types are matched
+// case-insensitive and without parameters, i.e. "application/json;
+// charset=utf-8" resolves to the codec registered for "application/json".
+//
+// Safe for concurrent use.
+type CodecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewCodecRegistry creates a registry with the given codecs registered
+func NewCodecRegistry(codecs ...Codec) (*CodecRegistry, error) {
+	r := CodecRegistry{
+		codecs: make(map[string]Codec),
+	}
+
+	for _, c := range codecs {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+// Register adds a codec to the registry. A codec previously registered for the
+// same content type is replaced.
+func (r *CodecRegistry) Register(c Codec) error {
+	if c == nil {
+		return errors.New("codec must not be nil")
+	}
+
+	ct, err := normalizeContentType(c.ContentType())
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codecs[ct] = c
+	return nil
+}
+
+// Lookup returns the codec registered for the given content type. If no codec
+// is registered, ErrCodecNotFound is returned.
+func (r *CodecRegistry) Lookup(contentType string) (Codec, error) {
+	ct, err := normalizeContentType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.codecs[ct]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCodecNotFound, ct)
+	}
+
+	return c, nil
+}
+
+// ContentTypes returns the sorted list of registered content types
+func (r *CodecRegistry) ContentTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.codecs))
+	for ct := range r.codecs {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+func normalizeContentType(contentType string) (string, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return "", errors.New("content type must not be empty")
+	}
+
+	ct, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("parse content type: %v", err)
+	}
+
+	return ct, nil
+}
+
+// defaultCodecs is the process-wide registry used by RegisterCodec,
+// LookupCodec and logs without a custom registry
+var defaultCodecs = mustCodecRegistry(JSONCodec{}, GobCodec{})
+
+func mustCodecRegistry(codecs ...Codec) *CodecRegistry {
+	r, err := NewCodecRegistry(codecs...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return r
+}
+
+// DefaultCodecRegistry returns the process-wide codec registry. It has a
+// JSONCodec registered for ContentTypeJSON and a GobCodec registered for
+// ContentTypeGob.
+func DefaultCodecRegistry() *CodecRegistry {
+	return defaultCodecs
+}
+
+// RegisterCodec adds a codec to the process-wide codec registry
+func RegisterCodec(c Codec) error {
+	return defaultCodecs.Register(c)
+}
+
+// LookupCodec returns the codec for the given content type from the
+// process-wide codec registry
+func LookupCodec(contentType string) (Codec, error) {
+	return defaultCodecs.Lookup(contentType)
+}
+
+// Codecs returns the codec registry used by the log
+func (l *Log) Codecs() *CodecRegistry {
+	return l.codecs
+}
+
+// Codec returns the codec used to encode and decode values of the log, see
+// WithCodec()
+func (l *Log) Codec() Codec {
+	return l.codec
+}
@@ -0,0 +1,165 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type testCodec struct {
+	contentType string
+}
+
+func (c testCodec) ContentType() string                     { return c.contentType }
+func (c testCodec) Marshal(_ interface{}) ([]byte, error)   { return []byte(c.contentType), nil }
+func (c testCodec) Unmarshal(_ []byte, _ interface{}) error { return nil }
+
+func TestCodecRegistry(t *testing.T) {
+	t.Run("register fails with invalid codec", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			codec Codec
+			error string
+		}{
+			{name: "nil codec", codec: nil, error: "must not be nil"},
+			{name: "empty content type", codec: testCodec{contentType: " "}, error: "must not be empty"},
+			{name: "invalid content type", codec: testCodec{contentType: "text/plain;;"}, error: "parse content type"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				r, err := NewCodecRegistry()
+				assert.NilError(t, err)
+
+				err = r.Register(tc.codec)
+				assert.ErrorContains(t, err, tc.error)
+			})
+		}
+	})
+
+	t.Run("lookup resolves normalized content types", func(t *testing.T) {
+		testCases := []struct {
+			name        string
+			contentType string
+			wantErr     error
+		}{
+			{name: "exact match", contentType: "application/json"},
+			{name: "upper case", contentType: "Application/JSON"},
+			{name: "with parameters", contentType: "application/json; charset=utf-8"},
+			{name: "not registered", contentType: "application/xml", wantErr: ErrCodecNotFound},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				r, err := NewCodecRegistry(JSONCodec{})
+				assert.NilError(t, err)
+
+				c, err := r.Lookup(tc.contentType)
+				if tc.wantErr != nil {
+					assert.Assert(t, errors.Is(err, tc.wantErr))
+					return
+				}
+
+				assert.NilError(t, err)
+				assert.Equal(t, c.ContentType(), ContentTypeJSON)
+			})
+		}
+	})
+
+	t.Run("register replaces existing codec", func(t *testing.T) {
+		r, err := NewCodecRegistry(testCodec{contentType: "text/plain"})
+		assert.NilError(t, err)
+
+		err = r.Register(testCodec{contentType: "TEXT/PLAIN"})
+		assert.NilError(t, err)
+
+		c, err := r.Lookup("text/plain")
+		assert.NilError(t, err)
+		assert.Equal(t, c.ContentType(), "TEXT/PLAIN")
+		assert.DeepEqual(t, r.ContentTypes(), []string{"text/plain"})
+	})
+
+	t.Run("json codec round trip", func(t *testing.T) {
+		type event struct {
+			ID string `json:"id"`
+		}
+
+		c, err := LookupCodec(ContentTypeJSON)
+		assert.NilError(t, err)
+
+		b, err := c.Marshal(event{ID: "1"})
+		assert.NilError(t, err)
+
+		var got event
+		err = c.Unmarshal(b, &got)
+		assert.NilError(t, err)
+		assert.Equal(t, got.ID, "1")
+	})
+}
+
+func TestLog_Codecs(t *testing.T) {
+	t.Run("uses default registry", func(t *testing.T) {
+		l, err := New(context.Background())
+		assert.NilError(t, err)
+		assert.Assert(t, l.Codecs() == DefaultCodecRegistry())
+	})
+
+	t.Run("uses custom registry", func(t *testing.T) {
+		r, err := NewCodecRegistry(testCodec{contentType: "text/plain"})
+		assert.NilError(t, err)
+
+		l, err := New(context.Background(), WithCodecRegistry(r))
+		assert.NilError(t, err)
+
+		_, err = l.Codecs().Lookup(ContentTypeJSON)
+		assert.Assert(t, errors.Is(err, ErrCodecNotFound))
+	})
+
+	t.Run("fails with nil registry", func(t *testing.T) {
+		_, err := New(context.Background(), WithCodecRegistry(nil))
+		assert.ErrorContains(t, err, "must not be nil")
+	})
+}
+
+func TestCodecs(t *testing.T) {
+	type event struct {
+		ID    string
+		Count int
+	}
+
+	for _, c := range []Codec{JSONCodec{}, GobCodec{}} {
+		c := c
+		t.Run(c.ContentType(), func(t *testing.T) {
+			data, err := c.Marshal(event{ID: "1", Count: 2})
+			assert.NilError(t, err)
+
+			var got event
+			assert.NilError(t, c.Unmarshal(data, &got))
+			assert.Equal(t, got, event{ID: "1", Count: 2})
+
+			err = c.Unmarshal([]byte("invalid"), &got)
+			assert.Assert(t, err != nil)
+
+			registered, err := LookupCodec(c.ContentType())
+			assert.NilError(t, err)
+			assert.Equal(t, registered, c)
+		})
+	}
+}
+
+func TestWithCodec(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(ctx, WithCodec(nil))
+	assert.ErrorContains(t, err, "must not be nil")
+
+	l, err := New(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, l.Codec(), Codec(JSONCodec{}))
+
+	l, err = New(ctx, WithCodec(GobCodec{}))
+	assert.NilError(t, err)
+	assert.Equal(t, l.Codec(), Codec(GobCodec{}))
+}
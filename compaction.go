@@ -0,0 +1,112 @@
+package memlog
+
+import (
+	"context"
+	"sort"
+)
+
+// WithCompaction enables key-based compaction, i.e. the latest record per key
+// is retained in a compacted tier when records are purged from the log due to
+// retention or Trim(), e.g. to use the log as an in-memory changelog of
+// materialized state. Writing a newer record with the same key supersedes the
+// compacted record. Records without a key and deleted records (see Delete())
+// are dropped on purge as usual. Compacted records do not count towards
+// RetainedBytes(). See CompactedHistory().
+func WithCompaction() Option {
+	return func(log *Log) error {
+		log.compaction = &compactor{records: make(map[string]Record)}
+		return nil
+	}
+}
+
+// compactor keeps the latest purged record per key
+type compactor struct {
+	records map[string]Record
+}
+
+// CompactedHistory returns the records kept from purged segments with
+// WithCompaction() ordered by offset, i.e. the latest record of every key not
+// superseded by a record retained in the log. Without compaction, an empty
+// slice is returned.
+//
+// Safe for concurrent use.
+func (l *Log) CompactedHistory(_ context.Context) []Record {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.compactedHistory()
+}
+
+// compactedHistory returns deep copies of the compacted records ordered by
+// offset. Must be protected with a lock by the caller.
+func (l *Log) compactedHistory() []Record {
+	if l.compaction == nil {
+		return []Record{}
+	}
+
+	records := make([]Record, 0, len(l.compaction.records))
+	for _, r := range l.compaction.records {
+		records = append(records, r.deepCopy())
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Metadata.Offset < records[j].Metadata.Offset
+	})
+
+	return records
+}
+
+// compact keeps the latest keyed record of the first n records of the given
+// segment before they are purged, unless the key has a newer record retained
+// in the log. Must be protected with a lock by the caller.
+func (l *Log) compact(s *segment, n int) error {
+	c := l.compaction
+	if c == nil {
+		return nil
+	}
+
+	// payloads of compressed segments are needed for the copies
+	if err := s.touch(l.clock.Now()); err != nil {
+		return err
+	}
+
+	for i, r := range s.data[:n] {
+		if r.Metadata.Offset != s.start+Offset(i) {
+			// gap
+			continue
+		}
+
+		key := string(r.Metadata.Key)
+		switch {
+		case key == "":
+		case IsTombstone(r):
+			delete(c.records, key)
+		default:
+			// copy, payloads of the segment are released on purge
+			c.records[key] = r.deepCopy()
+		}
+	}
+
+	// drop records superseded by records retained in the log
+	retained := [][]Record{s.data[n:]}
+	if s == l.history {
+		retained = append(retained, l.active.data)
+	}
+	for _, records := range retained {
+		for _, r := range records {
+			if len(r.Metadata.Key) > 0 {
+				delete(c.records, string(r.Metadata.Key))
+			}
+		}
+	}
+
+	return nil
+}
+
+// supersede removes the compacted record of the given key after a newer
+// record with the key has been written. Must be protected with a lock by the
+// caller.
+func (l *Log) supersede(key []byte) {
+	if l.compaction != nil && len(key) > 0 {
+		delete(l.compaction.records, string(key))
+	}
+}
@@ -0,0 +1,87 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Compaction(t *testing.T) {
+	keys := func(records []Record) []string {
+		var k []string
+		for _, r := range records {
+			k = append(k, string(r.Metadata.Key)+":"+string(r.Data))
+		}
+		return k
+	}
+
+	t.Run("empty without compaction", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		for i := 0; i < 6; i++ {
+			_, err = l.WriteKeyed(ctx, []byte("k"), []byte("v"))
+			assert.NilError(t, err)
+		}
+		assert.Equal(t, len(l.CompactedHistory(ctx)), 0)
+	})
+
+	t.Run("keeps latest purged record per key", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(3), WithCompaction())
+		assert.NilError(t, err)
+
+		writes := []struct{ key, value string }{
+			{"a", "1"}, {"b", "1"}, {"", "unkeyed"},
+			{"a", "2"}, {"c", "1"}, {"d", "1"},
+			// purges first segment
+			{"e", "1"}, {"d", "2"}, {"f", "1"},
+			// purges second segment
+			{"g", "1"},
+		}
+		for _, w := range writes {
+			var opts []WriteOption
+			if w.key != "" {
+				opts = append(opts, WithKey([]byte(w.key)))
+			}
+			_, err = l.Write(ctx, []byte(w.value), opts...)
+			assert.NilError(t, err)
+		}
+
+		// "d" superseded by a retained record
+		assert.DeepEqual(t, keys(l.CompactedHistory(ctx)), []string{"b:1", "a:2", "c:1"})
+	})
+
+	t.Run("drops deleted records and compacts trimmed records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(10), WithCompaction())
+		assert.NilError(t, err)
+
+		for _, k := range []string{"a", "b", "c"} {
+			_, err = l.WriteKeyed(ctx, []byte(k), []byte("1"))
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.Delete(ctx, 1))
+
+		assert.NilError(t, l.Trim(ctx, 2))
+		assert.DeepEqual(t, keys(l.CompactedHistory(ctx)), []string{"a:1"})
+	})
+
+	t.Run("table snapshot includes compacted records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2), WithCompaction())
+		assert.NilError(t, err)
+
+		for _, k := range []string{"a", "b", "c", "d", "e"} {
+			_, err = l.WriteKeyed(ctx, []byte(k), []byte("1"))
+			assert.NilError(t, err)
+		}
+
+		snapshot, next, err := l.tableSnapshot(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, next, Offset(5))
+		assert.DeepEqual(t, keys(snapshot), []string{"a:1", "b:1", "c:1", "d:1", "e:1"})
+	})
+}
@@ -0,0 +1,186 @@
+package memlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// segmentPayloads holds the gzip compressed payloads of a segment
+type segmentPayloads struct {
+	blob  []byte
+	sizes []int // payload size per record
+}
+
+// touch records a read access of the segment at the given time and
+// decompresses the payloads of a compressed segment. Safe for concurrent use
+// by readers holding a read lock on the log. Readers holding a read lock must
+// call touch before accessing record payloads and must only access record
+// metadata otherwise, e.g. with live().
+func (s *segment) touch(now time.Time) error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+
+	s.reads++
+	s.lastRead = now
+	if s.compressed == nil {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(s.compressed.blob))
+	if err != nil {
+		return fmt.Errorf("decompress segment: %w", err)
+	}
+
+	for i, size := range s.compressed.sizes {
+		if size == 0 {
+			// gap or empty payload
+			continue
+		}
+
+		data := make([]byte, size)
+		if _, err = io.ReadFull(zr, data); err != nil {
+			return fmt.Errorf("decompress segment: %w", err)
+		}
+		s.data[i].Data = data
+	}
+
+	s.compressed = nil
+	return nil
+}
+
+// payloadSize returns the uncompressed payload size of the record at the given
+// offset, also for compressed segments. Must be protected with a write lock on
+// the log by the caller.
+func (s *segment) payloadSize(offset Offset) int {
+	i := offset - s.start
+	if s.compressed != nil {
+		return s.compressed.sizes[i]
+	}
+	return len(s.data[i].Data)
+}
+
+// compress compresses the payloads of the segment. Must be protected with a
+// write lock on the log by the caller.
+func (s *segment) compress() error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	sizes := make([]int, len(s.data))
+	for i, r := range s.data {
+		sizes[i] = len(r.Data)
+		if _, err := zw.Write(r.Data); err != nil {
+			return fmt.Errorf("compress segment: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress segment: %w", err)
+	}
+
+	for i := range s.data {
+		s.data[i].Data = nil
+	}
+	s.compressed = &segmentPayloads{
+		blob:  buf.Bytes(),
+		sizes: sizes,
+	}
+	return nil
+}
+
+// CompressIdle compresses the record payloads of sealed segments which have
+// not been read for at least the given idle duration and returns the number of
+// compressed segments. Compressed payloads are transparently decompressed on
+// the next read of the segment, trading CPU for memory on logs with cold
+// history. Writes and reads are blocked during compression.
+//
+// Compression can not be used with payload deduplication or off-heap storage.
+//
+// Safe for concurrent use.
+func (l *Log) CompressIdle(ctx context.Context, idle time.Duration) (int, error) {
+	return l.CompressSegments(ctx, func(s SegmentInfo) bool {
+		return l.clock.Since(s.LastRead) >= idle
+	})
+}
+
+// SegmentPolicy decides whether a segment is selected, e.g. for compression
+// based on its access statistics
+type SegmentPolicy func(SegmentInfo) bool
+
+// CompressSegments compresses the record payloads of sealed segments selected
+// by the given policy and returns the number of compressed segments. Segments
+// are offered to the policy ordered by last read time, i.e. least recently
+// read segments first. Already compressed segments are skipped. See
+// CompressIdle() for the compression semantics.
+//
+// Safe for concurrent use.
+func (l *Log) CompressSegments(ctx context.Context, policy SegmentPolicy) (int, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	if policy == nil {
+		return 0, errors.New("policy must not be nil")
+	}
+
+	if l.payloads != nil || l.conf.offHeap {
+		return 0, errors.New("compression not supported with payload deduplication or off-heap storage")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var candidates []*segment
+	for _, s := range []*segment{l.history, l.active} {
+		if s != nil && s.sealed && s.compressed == nil {
+			candidates = append(candidates, s)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].lastRead.Before(candidates[j].lastRead)
+	})
+
+	compressed := 0
+	for _, s := range candidates {
+		if !policy(s.info()) {
+			continue
+		}
+
+		if err := s.compress(); err != nil {
+			return compressed, err
+		}
+		compressed++
+	}
+
+	return compressed, nil
+}
+
+// RunCompression compresses idle sealed segments (see CompressIdle()) at the
+// given interval until the context is cancelled, e.g. started as a background
+// goroutine by the application. The context error is returned on
+// cancellation.
+func (l *Log) RunCompression(ctx context.Context, interval, idle time.Duration) error {
+	if interval <= 0 {
+		return errors.New("interval must be greater than 0")
+	}
+
+	ticker := l.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.Chan():
+			if _, err := l.CompressIdle(ctx, idle); err != nil && ctx.Err() == nil {
+				return err
+			}
+		}
+	}
+}
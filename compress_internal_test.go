@@ -0,0 +1,202 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_CompressIdle(t *testing.T) {
+	t.Run("fails with payload deduplication", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithPayloadDeduplication())
+		assert.NilError(t, err)
+
+		_, err = l.CompressIdle(ctx, time.Minute)
+		assert.ErrorContains(t, err, "not supported")
+	})
+
+	t.Run("compresses idle history and decompresses on read", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(5))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 8)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		// not idle yet
+		n, err := l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 0)
+
+		mockClock.Add(time.Minute * 2)
+		n, err = l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 1)
+		assert.Assert(t, l.history.compressed != nil)
+		assert.Assert(t, l.history.data[0].Data == nil)
+
+		// already compressed
+		n, err = l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 0)
+
+		for offset := Offset(0); offset < 8; offset++ {
+			r, err := l.Read(ctx, offset)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, r.Data, data[offset])
+		}
+		assert.Assert(t, l.history.compressed == nil)
+
+		// recently read
+		n, err = l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 0)
+	})
+
+	t.Run("compresses in background until cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 3) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		err = l.RunCompression(ctx, 0, time.Minute)
+		assert.ErrorContains(t, err, "greater than 0")
+
+		done := make(chan error)
+		go func() {
+			done <- l.RunCompression(ctx, time.Minute, time.Minute)
+		}()
+
+		compressed := func() bool {
+			l.mu.RLock()
+			defer l.mu.RUnlock()
+			return l.history.compressed != nil
+		}
+
+		for !compressed() {
+			mockClock.Add(time.Minute)
+		}
+
+		cancel()
+		assert.Assert(t, errors.Is(<-done, context.Canceled))
+	})
+
+	t.Run("decompresses with concurrent readers", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(5), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 8)
+		for i, d := range data {
+			// gaps make readers scan the compressed segment
+			_, err = l.WriteAt(ctx, Offset(i*2), d)
+			assert.NilError(t, err)
+		}
+
+		mockClock.Add(time.Minute * 2)
+		n, err := l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Assert(t, n > 0)
+
+		earliest, _ := l.Range(ctx)
+
+		var wg sync.WaitGroup
+		readers := []func() error{
+			func() error {
+				_, err := l.ReadEarliest(ctx)
+				return err
+			},
+			func() error {
+				_, err := l.Tail(ctx, 8)
+				return err
+			},
+			func() error {
+				_, err := l.ReadBatch(ctx, earliest, 8)
+				return err
+			},
+			func() error {
+				_, err := l.OffsetAfter(ctx, mockClock.Now())
+				return err
+			},
+			func() error {
+				_ = l.Stats(ctx)
+				return nil
+			},
+		}
+		for _, read := range readers {
+			read := read
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Check(t, read())
+			}()
+		}
+		wg.Wait()
+
+		records, err := l.ReadBatch(ctx, earliest, 8)
+		assert.NilError(t, err)
+		for _, r := range records {
+			assert.DeepEqual(t, r.Data, data[r.Metadata.Offset/2])
+		}
+	})
+}
+
+func TestLog_CompressSegments(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clock.NewMock()
+	l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(2))
+	assert.NilError(t, err)
+
+	_, err = l.CompressSegments(ctx, nil)
+	assert.ErrorContains(t, err, "policy must not be nil")
+
+	for _, d := range NewTestDataSlice(t, 3) {
+		_, err = l.Write(ctx, d)
+		assert.NilError(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = l.Read(ctx, 0)
+		assert.NilError(t, err)
+	}
+
+	// frequently read segment is not compressed
+	rarelyRead := func(s SegmentInfo) bool {
+		return s.Reads < 3
+	}
+	n, err := l.CompressSegments(ctx, rarelyRead)
+	assert.NilError(t, err)
+	assert.Equal(t, n, 0)
+
+	var offered []SegmentInfo
+	n, err = l.CompressSegments(ctx, func(s SegmentInfo) bool {
+		offered = append(offered, s)
+		return true
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, n, 1)
+
+	// active segment is not sealed
+	assert.Equal(t, len(offered), 1)
+	assert.Equal(t, offered[0].Start, Offset(0))
+	assert.Equal(t, offered[0].Reads, uint64(3))
+	assert.Assert(t, l.history.compressed != nil)
+}
@@ -0,0 +1,32 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOffsetConflict is returned by a conditional write when the next write
+// offset of the log does not match the expected offset, i.e. another writer
+// appended in between
+var ErrOffsetConflict = errors.New("offset conflict")
+
+// WithExpectedOffset makes the write conditional on the next write offset of
+// the log, i.e. the write fails with ErrOffsetConflict if the record would not
+// be written at the given offset, e.g. for optimistic concurrency between
+// multiple writers. Batch writes are conditional on the offset of the first
+// record of the batch.
+func WithExpectedOffset(next Offset) WriteOption {
+	return func(wc *writeConfig) {
+		wc.expected = &next
+	}
+}
+
+// WriteIf creates a new record in the log with the given data if the next
+// write offset of the log is the expected offset, i.e. a compare-and-swap on
+// the offset. If another writer appended in between, ErrOffsetConflict is
+// returned and no record is written. See Write() for the write semantics.
+//
+// Safe for concurrent use.
+func (l *Log) WriteIf(ctx context.Context, data []byte, expectedNext Offset, options ...WriteOption) (Offset, error) {
+	return l.Write(ctx, data, append(options[:len(options):len(options)], WithExpectedOffset(expectedNext))...)
+}
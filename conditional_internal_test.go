@@ -0,0 +1,78 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_WriteIf(t *testing.T) {
+	t.Run("writes at expected offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		offset, err := l.WriteIf(ctx, []byte("first"), 10)
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(10))
+
+		_, err = l.WriteIf(ctx, []byte("stale"), 10)
+		assert.Assert(t, errors.Is(err, ErrOffsetConflict))
+
+		offset, err = l.WriteIf(ctx, []byte("second"), 11, WithKey([]byte("k")))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(11))
+
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Key, []byte("k"))
+	})
+
+	t.Run("conditional batch", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 3)
+		_, err = l.WriteBatch(ctx, data, WithExpectedOffset(1))
+		assert.Assert(t, errors.Is(err, ErrOffsetConflict))
+		assert.Assert(t, l.IsEmpty(ctx))
+
+		offsets, err := l.WriteBatch(ctx, data, WithExpectedOffset(0))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, offsets, []Offset{0, 1, 2})
+	})
+
+	t.Run("only one concurrent writer wins", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		const writers = 10
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := l.WriteIf(ctx, []byte("data"), 0)
+				if err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+					return
+				}
+				assert.Check(t, errors.Is(err, ErrOffsetConflict))
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, succeeded, 1)
+	})
+}
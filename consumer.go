@@ -0,0 +1,444 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultConsumerTimeout is the duration after the last heartbeat when a
+// consumer is considered dead unless explicitly specified
+const DefaultConsumerTimeout = time.Second * 30
+
+// ErrConsumerNotFound is returned when a consumer is not registered
+var ErrConsumerNotFound = errors.New("consumer not found")
+
+// ConsumerStatus describes the liveness of a registered consumer
+type ConsumerStatus struct {
+	// Name is the unique name of the consumer
+	Name string `json:"name"`
+	// LastHeartbeat is the UTC time of the last heartbeat
+	LastHeartbeat time.Time `json:"lastHeartbeat"`
+	// Alive is false when no heartbeat was received within the consumer timeout
+	Alive bool `json:"alive"`
+	// Committed is the last committed offset or -1 if nothing has been
+	// committed, see Commit()
+	Committed Offset `json:"committed"`
+	// Hold is the offset of the purge barrier of the consumer or -1 if the
+	// consumer does not hold purging, see HoldPurge()
+	Hold Offset `json:"hold"`
+}
+
+// consumerRegistry tracks consumers of a log. Safe for concurrent use.
+type consumerRegistry struct {
+	mu        sync.Mutex
+	timeout   time.Duration
+	consumers map[string]*consumerState
+}
+
+type consumerState struct {
+	lastHeartbeat time.Time
+	committed     Offset
+	hold          Offset // purge barrier
+}
+
+func newConsumerRegistry(timeout time.Duration) *consumerRegistry {
+	return &consumerRegistry{
+		timeout:   timeout,
+		consumers: make(map[string]*consumerState),
+	}
+}
+
+// register returns the state of the named consumer, registering it if
+// needed. Must be protected with a lock by the caller.
+func (r *consumerRegistry) register(consumer string) *consumerState {
+	c, ok := r.consumers[consumer]
+	if !ok {
+		c = &consumerState{committed: -1, hold: -1}
+		r.consumers[consumer] = c
+	}
+	return c
+}
+
+// alive returns true if the consumer sent a heartbeat within the timeout
+func (r *consumerRegistry) alive(c *consumerState, now time.Time) bool {
+	return now.Sub(c.lastHeartbeat) <= r.timeout
+}
+
+// liveness returns the number of alive and dead consumers. Must be protected
+// with a lock by the caller.
+func (r *consumerRegistry) liveness(now time.Time) (alive, dead int) {
+	for _, c := range r.consumers {
+		if r.alive(c, now) {
+			alive++
+		} else {
+			dead++
+		}
+	}
+	return alive, dead
+}
+
+// Heartbeat signals that the named consumer is alive. Consumers are registered
+// with their first heartbeat. A consumer without a heartbeat within the
+// consumer timeout (see WithConsumerTimeout()) is considered dead.
+//
+// Safe for concurrent use.
+func (l *Log) Heartbeat(ctx context.Context, consumer string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if consumer == "" {
+		return errors.New("consumer name must not be empty")
+	}
+
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.register(consumer)
+	c.lastHeartbeat = l.clock.Now().UTC()
+
+	return nil
+}
+
+// Commit reports the offset of the last record processed by the named
+// consumer, e.g. after persisting a checkpoint. Consumers are registered with
+// their first commit, which also counts as a heartbeat. Commits lower than the
+// last committed offset are ignored. The committed offsets are used by Drain()
+// to determine whether consumers have caught up with the log. Consumers
+// created with NewConsumer() report their commits automatically.
+//
+// Safe for concurrent use.
+func (l *Log) Commit(ctx context.Context, consumer string, offset Offset) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if consumer == "" {
+		return errors.New("consumer name must not be empty")
+	}
+
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.register(consumer)
+	c.lastHeartbeat = l.clock.Now().UTC()
+	if offset > c.committed {
+		c.committed = offset
+	}
+
+	return nil
+}
+
+// Unregister removes the named consumer from the log. If the consumer is not
+// registered, ErrConsumerNotFound is returned.
+//
+// Safe for concurrent use.
+func (l *Log) Unregister(ctx context.Context, consumer string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.consumers[consumer]; !ok {
+		return fmt.Errorf("%w: %s", ErrConsumerNotFound, consumer)
+	}
+
+	delete(r.consumers, consumer)
+	return nil
+}
+
+// Consumers returns the status of all registered consumers ordered by name
+//
+// Safe for concurrent use.
+func (l *Log) Consumers(_ context.Context) []ConsumerStatus {
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := l.clock.Now()
+	status := make([]ConsumerStatus, 0, len(r.consumers))
+	for name, c := range r.consumers {
+		status = append(status, ConsumerStatus{
+			Name:          name,
+			LastHeartbeat: c.lastHeartbeat,
+			Alive:         r.alive(c, now),
+			Committed:     c.committed,
+			Hold:          c.hold,
+		})
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Name < status[j].Name
+	})
+
+	return status
+}
+
+// ConsumerOption customizes a Consumer
+type ConsumerOption func(*Consumer) error
+
+// WithAutoCommit periodically commits the offset of the last processed record
+// to the checkpoint store. A commit is only performed if the offset changed
+// since the last commit, batching many processed records into a single save.
+func WithAutoCommit(interval time.Duration) ConsumerOption {
+	return func(c *Consumer) error {
+		if interval <= 0 {
+			return errors.New("interval must be greater than 0")
+		}
+
+		c.autoCommit = interval
+		return nil
+	}
+}
+
+// WithResyncOnMismatch resumes a consumer at the earliest record of the log if
+// its checkpoint was created against another log incarnation (see Log.ID())
+// instead of failing with ErrLogMismatch
+func WithResyncOnMismatch() ConsumerOption {
+	return func(c *Consumer) error {
+		c.resync = true
+		return nil
+	}
+}
+
+// Consumer reads records sequentially from a log and commits its progress to
+// a CheckpointStore. A new consumer resumes after its last checkpoint or at
+// the earliest record of the log if no checkpoint exists.
+//
+// Safe for concurrent use.
+type Consumer struct {
+	log        *Log
+	name       string
+	store      CheckpointStore
+	autoCommit time.Duration
+	resync     bool
+
+	mu        sync.Mutex
+	next      Offset // next offset to read
+	returned  Offset // last offset returned by Next
+	marked    Offset // last processed offset
+	committed Offset // last committed offset
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewConsumer creates a consumer with the given name reading from the log.
+// The consumer is registered with the log (see Heartbeat() and Commit()) and
+// resumes from its last checkpoint in the store. If the checkpoint was created
+// against another log incarnation, e.g. after a restart of the process,
+// ErrLogMismatch is returned unless WithResyncOnMismatch() is specified.
+func (l *Log) NewConsumer(ctx context.Context, name string, store CheckpointStore, options ...ConsumerOption) (*Consumer, error) {
+	if name == "" {
+		return nil, errors.New("consumer name must not be empty")
+	}
+
+	if store == nil {
+		return nil, errors.New("checkpoint store must not be nil")
+	}
+
+	c := Consumer{
+		log:       l,
+		name:      name,
+		store:     store,
+		returned:  -1,
+		marked:    -1,
+		committed: -1,
+	}
+
+	for _, opt := range options {
+		if err := opt(&c); err != nil {
+			return nil, fmt.Errorf("configure consumer option: %v", err)
+		}
+	}
+
+	committed, err := l.resume(ctx, name, store, c.resync)
+	if err != nil {
+		return nil, err
+	}
+	c.next = committed + 1
+	c.marked = committed
+	c.committed = committed
+	if committed == -1 {
+		c.next = l.resumeStart(ctx)
+	}
+
+	if err = l.Commit(ctx, name, c.committed); err != nil {
+		return nil, err
+	}
+
+	if c.autoCommit > 0 {
+		c.stop = make(chan struct{})
+		c.done = make(chan struct{})
+		go c.runAutoCommit()
+	}
+
+	return &c, nil
+}
+
+// resume returns the offset of the checkpoint of the named consumer in the
+// store or -1 if there is no checkpoint. If the checkpoint was created against
+// another log incarnation, ErrLogMismatch is returned unless resync is true.
+func (l *Log) resume(ctx context.Context, name string, store CheckpointStore, resync bool) (Offset, error) {
+	checkpoint, err := store.Load(ctx, name)
+	if err == nil {
+		if err = checkpoint.verify(l); err != nil && !resync {
+			return -1, err
+		}
+	}
+
+	switch {
+	case err == nil:
+		return checkpoint.Offset, nil
+	case errors.Is(err, ErrCheckpointNotFound), errors.Is(err, ErrLogMismatch):
+		return -1, nil
+	default:
+		return -1, fmt.Errorf("load checkpoint: %w", err)
+	}
+}
+
+// resumeStart returns the offset a consumer without a checkpoint starts
+// reading at, i.e. the earliest record of the log or the next write offset if
+// the log is empty
+func (l *Log) resumeStart(ctx context.Context) Offset {
+	earliest, _ := l.Range(ctx)
+	if earliest == -1 {
+		l.mu.RLock()
+		earliest = l.offset
+		l.mu.RUnlock()
+	}
+	return earliest
+}
+
+// Name returns the name of the consumer
+func (c *Consumer) Name() string {
+	return c.name
+}
+
+// Next marks the record previously returned by Next as processed and reads the
+// next record. Gaps in logs with sparse offsets are skipped. If no new record
+// is available, ErrFutureOffset is returned. If the next record has been
+// purged, ErrOutOfRange is returned and Seek() can be used to recover.
+func (c *Consumer) Next(ctx context.Context) (Record, error) {
+	if err := c.log.Heartbeat(ctx, c.name); err != nil {
+		return Record{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.returned > c.marked {
+		c.marked = c.returned
+	}
+
+	for {
+		r, err := c.log.Read(ctx, c.next)
+		if errors.Is(err, ErrOffsetGap) {
+			c.next++
+			continue
+		}
+		if err != nil {
+			return Record{}, err
+		}
+
+		if err = c.log.recordAccess(ctx, c.name, r.Metadata.Offset); err != nil {
+			return Record{}, err
+		}
+
+		c.next++
+		c.returned = r.Metadata.Offset
+		return r, nil
+	}
+}
+
+// Mark marks all records up to and including the given offset as processed.
+// Use Mark to commit a record returned by Next before calling Next again.
+func (c *Consumer) Mark(offset Offset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if offset > c.marked {
+		c.marked = offset
+	}
+}
+
+// Seek sets the offset of the next record returned by Next
+func (c *Consumer) Seek(offset Offset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next = offset
+}
+
+// Committed returns the last committed offset or -1 if nothing has been
+// committed
+func (c *Consumer) Committed() Offset {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.committed
+}
+
+// Commit saves the offset of the last processed record to the checkpoint
+// store. Commit is a no-op if the offset has not changed since the last
+// commit.
+func (c *Consumer) Commit(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.commit(ctx)
+}
+
+func (c *Consumer) commit(ctx context.Context) error {
+	if c.marked == c.committed {
+		return nil
+	}
+
+	checkpoint := Checkpoint{
+		LogID:  c.log.ID(),
+		Offset: c.marked,
+	}
+	if err := c.store.Save(ctx, c.name, checkpoint); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	c.committed = c.marked
+	return c.log.Commit(ctx, c.name, c.committed)
+}
+
+// Close stops auto-committing and commits the last processed offset
+func (c *Consumer) Close(ctx context.Context) error {
+	if c.stop != nil {
+		c.stopOnce.Do(func() { close(c.stop) })
+		<-c.done
+	}
+
+	return c.Commit(ctx)
+}
+
+func (c *Consumer) runAutoCommit() {
+	defer close(c.done)
+
+	ticker := c.log.clock.NewTicker(c.autoCommit)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.Chan():
+			// errors are retried on the next tick and surfaced by Commit/Close
+			_ = c.Commit(context.Background())
+		}
+	}
+}
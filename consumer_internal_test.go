@@ -0,0 +1,183 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Heartbeat(t *testing.T) {
+	t.Run("fails with invalid input", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.Heartbeat(ctx, "")
+		assert.ErrorContains(t, err, "must not be empty")
+
+		err = l.Unregister(ctx, "unknown")
+		assert.Assert(t, errors.Is(err, ErrConsumerNotFound))
+
+		_, err = New(ctx, WithConsumerTimeout(0))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("tracks consumer liveness", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithConsumerTimeout(time.Second*10))
+		assert.NilError(t, err)
+
+		assert.NilError(t, l.Heartbeat(ctx, "billing"))
+		assert.NilError(t, l.Heartbeat(ctx, "audit"))
+
+		mockClock.Add(time.Second * 8)
+		assert.NilError(t, l.Heartbeat(ctx, "billing"))
+
+		mockClock.Add(time.Second * 5)
+		status := l.Consumers(ctx)
+		assert.Equal(t, len(status), 2)
+		assert.Equal(t, status[0].Name, "audit")
+		assert.Equal(t, status[0].Alive, false)
+		assert.Equal(t, status[1].Name, "billing")
+		assert.Equal(t, status[1].Alive, true)
+
+		// dead consumer comes back
+		assert.NilError(t, l.Heartbeat(ctx, "audit"))
+		assert.Equal(t, l.Consumers(ctx)[0].Alive, true)
+
+		assert.NilError(t, l.Unregister(ctx, "audit"))
+		assert.Equal(t, len(l.Consumers(ctx)), 1)
+	})
+}
+
+func TestLog_NewConsumer(t *testing.T) {
+	t.Run("fails with invalid input", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.NewConsumer(ctx, "", NewMemoryCheckpointStore())
+		assert.ErrorContains(t, err, "must not be empty")
+
+		_, err = l.NewConsumer(ctx, "c", nil)
+		assert.ErrorContains(t, err, "must not be nil")
+
+		_, err = l.NewConsumer(ctx, "c", NewMemoryCheckpointStore(), WithAutoCommit(0))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("commits manually and resumes after checkpoint", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		c, err := l.NewConsumer(ctx, "billing", store)
+		assert.NilError(t, err)
+		assert.Equal(t, c.Committed(), Offset(-1))
+
+		for i := 0; i < 5; i++ {
+			r, nextErr := c.Next(ctx)
+			assert.NilError(t, nextErr)
+			assert.Equal(t, r.Metadata.Offset, Offset(10+i))
+		}
+
+		// offset 14 returned but not processed yet
+		assert.NilError(t, c.Commit(ctx))
+		assert.Equal(t, c.Committed(), Offset(13))
+
+		c.Mark(14)
+		assert.NilError(t, c.Close(ctx))
+		assert.Equal(t, store.Saves(), 2)
+
+		resumed, err := l.NewConsumer(ctx, "billing", store)
+		assert.NilError(t, err)
+
+		r, err := resumed.Next(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(15))
+
+		for i := 0; i < 4; i++ {
+			_, err = resumed.Next(ctx)
+			assert.NilError(t, err)
+		}
+
+		_, err = resumed.Next(ctx)
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+		assert.Equal(t, l.Consumers(ctx)[0].Name, "billing")
+	})
+
+	t.Run("auto-commit batches commits", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 100) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		c, err := l.NewConsumer(ctx, "audit", store, WithAutoCommit(time.Millisecond*20))
+		assert.NilError(t, err)
+
+		for i := 0; i < 100; i++ {
+			_, err = c.Next(ctx)
+			assert.NilError(t, err)
+		}
+		c.Mark(99)
+
+		poll := func() bool {
+			checkpoint, loadErr := store.Load(ctx, "audit")
+			return loadErr == nil && checkpoint.Offset == 99
+		}
+		for !poll() {
+			time.Sleep(time.Millisecond * 5)
+		}
+
+		assert.NilError(t, c.Close(ctx))
+		assert.Assert(t, store.Saves() < 100)
+	})
+
+	t.Run("auto-commit uses clock of log", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		c, err := l.NewConsumer(ctx, "audit", store, WithAutoCommit(time.Hour))
+		assert.NilError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = c.Next(ctx)
+			assert.NilError(t, err)
+		}
+		c.Mark(4)
+
+		for store.Saves() == 0 {
+			mockClock.Add(time.Hour)
+			time.Sleep(time.Millisecond)
+		}
+
+		checkpoint, err := store.Load(ctx, "audit")
+		assert.NilError(t, err)
+		assert.Equal(t, checkpoint.Offset, Offset(4))
+		assert.NilError(t, c.Close(ctx))
+	})
+}
@@ -0,0 +1,268 @@
+package memlog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header conventions of records converted from and to other systems. Bridges
+// should use these headers, so that records written by one bridge can be read
+// by another.
+const (
+	// HeaderContentType is the media type of the record payload, e.g.
+	// ContentTypeJSON
+	HeaderContentType = "content-type"
+	// HeaderKafkaTopic is the topic of a record converted from a Kafka message
+	HeaderKafkaTopic = "memlog-kafka-topic"
+	// HeaderKafkaPartition is the partition of a record converted from a Kafka
+	// message
+	HeaderKafkaPartition = "memlog-kafka-partition"
+	// HeaderKafkaOffset is the offset of a record converted from a Kafka
+	// message
+	HeaderKafkaOffset = "memlog-kafka-offset"
+	// HeaderCloudEventPrefix is the prefix of headers holding CloudEvents
+	// attributes, e.g. "ce_id", following the binary content mode of the
+	// CloudEvents Kafka protocol binding
+	HeaderCloudEventPrefix = "ce_"
+)
+
+// CloudEventsSpecVersion is the CloudEvents specification version of events
+// created by ToCloudEvent()
+const CloudEventsSpecVersion = "1.0"
+
+// cloudEventPartitionKey is the CloudEvents extension attribute holding the
+// record key, see the CloudEvents partitioning extension
+const cloudEventPartitionKey = "partitionkey"
+
+// KafkaHeader is a header of a Kafka message
+type KafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// KafkaMessage is a Kafka message independent of a specific Kafka client
+// library. Convert client specific messages field by field.
+type KafkaMessage struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []KafkaHeader
+	Timestamp time.Time
+}
+
+// FromKafkaMessage returns the payload and write options of a record
+// converted from the Kafka message: the message key becomes the record key,
+// message headers become record headers (the last value of a repeated header
+// wins), the message timestamp becomes the creation time of the record and
+// the topic, partition and offset of the message are stored in the
+// HeaderKafkaTopic, HeaderKafkaPartition and HeaderKafkaOffset headers.
+func FromKafkaMessage(m KafkaMessage) ([]byte, []WriteOption) {
+	options := []WriteOption{
+		WithHeader(HeaderKafkaTopic, []byte(m.Topic)),
+		WithHeader(HeaderKafkaPartition, []byte(strconv.FormatInt(int64(m.Partition), 10))),
+		WithHeader(HeaderKafkaOffset, []byte(strconv.FormatInt(m.Offset, 10))),
+	}
+
+	if m.Key != nil {
+		options = append(options, WithKey(m.Key))
+	}
+
+	for _, h := range m.Headers {
+		options = append(options, WithHeader(h.Key, h.Value))
+	}
+
+	if !m.Timestamp.IsZero() {
+		options = append(options, withCreated(m.Timestamp))
+	}
+
+	return m.Value, options
+}
+
+// ToKafkaMessage converts the record into a Kafka message for the given topic:
+// the record key becomes the message key, record headers become message
+// headers ordered by key and the creation time of the record becomes the
+// message timestamp. Partition and offset are -1, i.e. assigned by the
+// producer and broker.
+func ToKafkaMessage(r Record, topic string) KafkaMessage {
+	m := KafkaMessage{
+		Topic:     topic,
+		Partition: -1,
+		Offset:    -1,
+		Key:       copyBytes(r.Metadata.Key),
+		Value:     copyBytes(r.Data),
+		Timestamp: r.Metadata.Created,
+	}
+
+	for _, k := range sortedHeaderKeys(r.Metadata.Headers) {
+		m.Headers = append(m.Headers, KafkaHeader{Key: k, Value: copyBytes(r.Metadata.Headers[k])})
+	}
+
+	return m
+}
+
+// CloudEvent is a CloudEvents event independent of a specific CloudEvents SDK
+type CloudEvent struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	Data            []byte
+	// Extensions are the extension attributes of the event. Names must
+	// consist of lower-case letters and digits.
+	Extensions map[string]string
+}
+
+// validate returns an error if a required attribute is missing or an
+// extension attribute name is invalid
+func (e CloudEvent) validate() error {
+	for name, v := range map[string]string{"specversion": e.SpecVersion, "id": e.ID, "source": e.Source, "type": e.Type} {
+		if v == "" {
+			return fmt.Errorf("cloudevent attribute %q must not be empty", name)
+		}
+	}
+
+	for name := range e.Extensions {
+		if !validCloudEventAttribute(name) {
+			return fmt.Errorf("invalid cloudevent extension attribute %q", name)
+		}
+	}
+	return nil
+}
+
+// FromCloudEvent returns the payload and write options of a record converted
+// from the event: the event data becomes the payload, the content type is
+// stored in the HeaderContentType header, the event time becomes the creation
+// time of the record, the "partitionkey" extension becomes the record key and
+// all other attributes are stored in headers with the HeaderCloudEventPrefix,
+// e.g. "ce_id". An error is returned if a required attribute is missing.
+func FromCloudEvent(e CloudEvent) ([]byte, []WriteOption, error) {
+	if err := e.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	attributes := map[string]string{
+		"specversion": e.SpecVersion,
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+		"subject":     e.Subject,
+	}
+	for name, v := range e.Extensions {
+		if name == cloudEventPartitionKey {
+			continue
+		}
+		attributes[name] = v
+	}
+
+	var options []WriteOption
+	for name, v := range attributes {
+		if v != "" {
+			options = append(options, WithHeader(HeaderCloudEventPrefix+name, []byte(v)))
+		}
+	}
+
+	if e.DataContentType != "" {
+		options = append(options, WithHeader(HeaderContentType, []byte(e.DataContentType)))
+	}
+
+	if key, ok := e.Extensions[cloudEventPartitionKey]; ok {
+		options = append(options, WithKey([]byte(key)))
+	}
+
+	if !e.Time.IsZero() {
+		options = append(options, withCreated(e.Time))
+	}
+
+	return e.Data, options, nil
+}
+
+// ToCloudEvent converts the record into a CloudEvents event. Attributes are
+// read from the headers written by FromCloudEvent(). For records without
+// attribute headers, the ID is the record offset and the given source and type
+// are used. The creation time of the record becomes the event time, the
+// HeaderContentType header the content type and the record key the
+// "partitionkey" extension. Other headers are not converted. An error is
+// returned if a required attribute is missing.
+func ToCloudEvent(r Record, source, typ string) (CloudEvent, error) {
+	e := CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              strconv.FormatInt(int64(r.Metadata.Offset), 10),
+		Source:          source,
+		Type:            typ,
+		Time:            r.Metadata.Created,
+		DataContentType: string(r.Metadata.Headers[HeaderContentType]),
+		Data:            copyBytes(r.Data),
+	}
+
+	for _, k := range sortedHeaderKeys(r.Metadata.Headers) {
+		name := strings.TrimPrefix(k, HeaderCloudEventPrefix)
+		if name == k || !validCloudEventAttribute(name) {
+			continue
+		}
+
+		v := string(r.Metadata.Headers[k])
+		switch name {
+		case "specversion":
+			e.SpecVersion = v
+		case "id":
+			e.ID = v
+		case "source":
+			e.Source = v
+		case "type":
+			e.Type = v
+		case "subject":
+			e.Subject = v
+		default:
+			if e.Extensions == nil {
+				e.Extensions = make(map[string]string)
+			}
+			e.Extensions[name] = v
+		}
+	}
+
+	if r.Metadata.Key != nil {
+		if e.Extensions == nil {
+			e.Extensions = make(map[string]string)
+		}
+		e.Extensions[cloudEventPartitionKey] = string(r.Metadata.Key)
+	}
+
+	if err := e.validate(); err != nil {
+		return CloudEvent{}, err
+	}
+	return e, nil
+}
+
+// validCloudEventAttribute returns true if the name consists of lower-case
+// letters and digits
+func validCloudEventAttribute(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedHeaderKeys returns the keys of the headers in order
+func sortedHeaderKeys(headers map[string][]byte) []string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,144 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestKafkaMessage(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	data, options := FromKafkaMessage(KafkaMessage{
+		Topic:     "orders",
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers: []KafkaHeader{
+			{Key: "trace", Value: []byte("1")},
+			{Key: "trace", Value: []byte("2")},
+		},
+		Timestamp: ts,
+	})
+
+	offset, err := l.Write(ctx, data, options...)
+	assert.NilError(t, err)
+
+	r, err := l.Read(ctx, offset)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, []byte("value"))
+	assert.DeepEqual(t, r.Metadata.Key, []byte("key"))
+	assert.Equal(t, r.Metadata.Created, ts)
+	assert.DeepEqual(t, r.Metadata.Headers, map[string][]byte{
+		"trace":              []byte("2"),
+		HeaderKafkaTopic:     []byte("orders"),
+		HeaderKafkaPartition: []byte("3"),
+		HeaderKafkaOffset:    []byte("42"),
+	})
+
+	m := ToKafkaMessage(r, "mirror")
+	assert.DeepEqual(t, m, KafkaMessage{
+		Topic:     "mirror",
+		Partition: -1,
+		Offset:    -1,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers: []KafkaHeader{
+			{Key: HeaderKafkaOffset, Value: []byte("42")},
+			{Key: HeaderKafkaPartition, Value: []byte("3")},
+			{Key: HeaderKafkaTopic, Value: []byte("orders")},
+			{Key: "trace", Value: []byte("2")},
+		},
+		Timestamp: ts,
+	})
+}
+
+func TestCloudEvent(t *testing.T) {
+	t.Run("fails with invalid events", func(t *testing.T) {
+		valid := CloudEvent{SpecVersion: "1.0", ID: "1", Source: "test", Type: "created"}
+
+		testCases := []struct {
+			name  string
+			event func(e CloudEvent) CloudEvent
+			error string
+		}{
+			{name: "missing id", event: func(e CloudEvent) CloudEvent { e.ID = ""; return e }, error: `"id" must not be empty`},
+			{name: "missing source", event: func(e CloudEvent) CloudEvent { e.Source = ""; return e }, error: `"source" must not be empty`},
+			{name: "invalid extension", event: func(e CloudEvent) CloudEvent {
+				e.Extensions = map[string]string{"Trace-ID": "1"}
+				return e
+			}, error: "invalid cloudevent extension"},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				_, _, err := FromCloudEvent(tc.event(valid))
+				assert.ErrorContains(t, err, tc.error)
+			})
+		}
+
+		_, err := ToCloudEvent(Record{}, "", "created")
+		assert.ErrorContains(t, err, `"source" must not be empty`)
+	})
+
+	t.Run("converts events without loss", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		want := CloudEvent{
+			SpecVersion:     "1.0",
+			ID:              "abc",
+			Source:          "/orders",
+			Type:            "order.created",
+			Subject:         "42",
+			Time:            time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			DataContentType: ContentTypeJSON,
+			Data:            []byte(`{"id":42}`),
+			Extensions:      map[string]string{"partitionkey": "42", "traceparent": "00-1"},
+		}
+
+		data, options, err := FromCloudEvent(want)
+		assert.NilError(t, err)
+		offset, err := l.Write(ctx, data, options...)
+		assert.NilError(t, err)
+
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Key, []byte("42"))
+		assert.DeepEqual(t, r.Metadata.Headers["ce_id"], []byte("abc"))
+		assert.DeepEqual(t, r.Metadata.Headers[HeaderContentType], []byte(ContentTypeJSON))
+
+		got, err := ToCloudEvent(r, "ignored", "ignored")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, want)
+	})
+
+	t.Run("converts plain records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, []byte("data"), WithHeader("trace", []byte("1")))
+		assert.NilError(t, err)
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+
+		got, err := ToCloudEvent(r, "/memlog", "record")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, CloudEvent{
+			SpecVersion: CloudEventsSpecVersion,
+			ID:          "0",
+			Source:      "/memlog",
+			Type:        "record",
+			Time:        r.Metadata.Created,
+			Data:        []byte("data"),
+		})
+	})
+}
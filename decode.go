@@ -0,0 +1,139 @@
+package memlog
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedRecord is a record with its data decoded into a value of type T
+type TypedRecord[T any] struct {
+	Metadata Header `json:"metadata"`
+	Data     T      `json:"data"`
+}
+
+// DecodeError is sent on the error channel of Decode when a record cannot be
+// decoded. A DecodeError does not terminate the stream.
+type DecodeError struct {
+	Offset Offset
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode record at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decode converts a record stream, e.g. created with Stream(), into a stream
+// of typed records using the given codec.
+//
+// Records which cannot be decoded are skipped and reported as *DecodeError on
+// the returned error channel without terminating the stream. Any other error,
+// i.e. an error received from the source stream or a cancelled context, is
+// sent on the error channel and terminates the stream. Both returned channels
+// are closed when the stream terminates.
+func Decode[T any](ctx context.Context, stream <-chan StreamRecord, errs <-chan error, codec Codec) (<-chan TypedRecord[T], <-chan error) {
+	if codec == nil {
+		typedCh := make(chan TypedRecord[T])
+		errCh := make(chan error, 1)
+		errCh <- fmt.Errorf("codec must not be nil")
+		close(typedCh)
+		close(errCh)
+		return typedCh, errCh
+	}
+
+	decode := func(r Record) (TypedRecord[T], error) {
+		var v T
+		if err := codec.Unmarshal(r.Data, &v); err != nil {
+			return TypedRecord[T]{}, &DecodeError{Offset: r.Metadata.Offset, Err: err}
+		}
+		return TypedRecord[T]{Metadata: r.Metadata, Data: v}, nil
+	}
+
+	return decodeStream(ctx, stream, errs, decode)
+}
+
+// decodeStream converts a record stream into a stream of typed records using
+// the given decode function returning *DecodeError for records which cannot be
+// decoded, see Decode()
+func decodeStream[T any](ctx context.Context, stream <-chan StreamRecord, errs <-chan error, decode func(Record) (TypedRecord[T], error)) (<-chan TypedRecord[T], <-chan error) {
+	var (
+		typedCh = make(chan TypedRecord[T], streamBuffer)
+		errCh   = make(chan error)
+	)
+
+	go func() {
+		defer func() {
+			close(typedCh)
+			close(errCh)
+		}()
+
+		sendErr := func(err error) bool {
+			select {
+			case errCh <- err:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// forward decodes and sends the record, returns false if the context
+		// is done
+		forward := func(r StreamRecord) bool {
+			tr, err := decode(r.Record)
+			if err != nil {
+				if !sendErr(err) {
+					errCh <- ctx.Err()
+					return false
+				}
+				return true
+			}
+
+			select {
+			case typedCh <- tr:
+				return true
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+
+			case err, ok := <-errs:
+				// deliver records buffered before the stream terminated
+				if stream != nil {
+					for r := range stream {
+						if !forward(r) {
+							return
+						}
+					}
+				}
+
+				if ok {
+					sendErr(err)
+				}
+				return
+
+			case r, ok := <-stream:
+				if !ok {
+					// source stream terminated, wait for its error
+					stream = nil
+					continue
+				}
+
+				if !forward(r) {
+					return
+				}
+			}
+		}
+	}()
+
+	return typedCh, errCh
+}
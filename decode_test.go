@@ -0,0 +1,117 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDecode(t *testing.T) {
+	type event struct {
+		ID string `json:"id"`
+	}
+
+	t.Run("decodes records and reports decode errors without terminating stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		data := [][]byte{
+			newTestData(t, "1"),
+			[]byte("not json"),
+			newTestData(t, "3"),
+		}
+
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		stream, errs := l.Stream(ctx, 0)
+		typedCh, errCh := Decode[event](ctx, stream, errs, JSONCodec{})
+
+		var (
+			ids       []string
+			decodeErr *DecodeError
+		)
+
+	LOOP:
+		for {
+			select {
+			case r := <-typedCh:
+				ids = append(ids, r.Data.ID)
+				if len(ids) == 2 {
+					cancel()
+				}
+			case streamErr := <-errCh:
+				if errors.As(streamErr, &decodeErr) {
+					continue
+				}
+				assert.Assert(t, errors.Is(streamErr, context.Canceled))
+				break LOOP
+			}
+		}
+
+		assert.DeepEqual(t, ids, []string{"1", "3"})
+		assert.Assert(t, decodeErr != nil)
+		assert.Equal(t, decodeErr.Offset, Offset(1))
+	})
+
+	t.Run("forwards terminal stream error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "1"))
+		assert.NilError(t, err)
+
+		stream, errs := l.Stream(ctx, 0)
+		_, errCh := Decode[event](ctx, stream, errs, JSONCodec{})
+
+		streamErr := <-errCh
+		assert.Assert(t, errors.Is(streamErr, ErrOutOfRange))
+	})
+
+	t.Run("delivers buffered records before terminal stream error", func(t *testing.T) {
+		ctx := context.Background()
+
+		// both channels are ready, repeat to exercise the select order
+		for i := 0; i < 50; i++ {
+			stream := make(chan StreamRecord, 3)
+			errs := make(chan error, 1)
+			for offset := Offset(0); offset < 3; offset++ {
+				stream <- StreamRecord{Record: Record{Metadata: Header{Offset: offset}, Data: newTestData(t, "1")}}
+			}
+			errs <- ErrSlowReader
+			close(stream)
+			close(errs)
+
+			typedCh, errCh := Decode[event](ctx, stream, errs, JSONCodec{})
+
+			var (
+				got       []Offset
+				streamErr error
+			)
+			for streamErr == nil {
+				select {
+				case tr := <-typedCh:
+					got = append(got, tr.Metadata.Offset)
+				case streamErr = <-errCh:
+				}
+			}
+
+			// records sent before the error are buffered
+			for tr := range typedCh {
+				got = append(got, tr.Metadata.Offset)
+			}
+			assert.DeepEqual(t, got, []Offset{0, 1, 2})
+			assert.Assert(t, errors.Is(streamErr, ErrSlowReader))
+		}
+	})
+}
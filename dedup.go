@@ -0,0 +1,74 @@
+package memlog
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// PayloadStore stores identical record payloads once. Payloads are reference
+// counted by the records using them and freed when the last record is purged.
+// A PayloadStore can be shared by multiple logs, see WithPayloadStore() and
+// Manager.
+//
+// Safe for concurrent use.
+type PayloadStore struct {
+	mu       sync.Mutex
+	payloads map[[sha256.Size]byte]*payload
+}
+
+type payload struct {
+	data []byte
+	refs int
+}
+
+// NewPayloadStore creates an empty payload store
+func NewPayloadStore() *PayloadStore {
+	return &PayloadStore{
+		payloads: make(map[[sha256.Size]byte]*payload),
+	}
+}
+
+// acquire returns the stored payload for data, storing a copy of data if it is
+// not stored yet, and increments its reference count
+func (s *PayloadStore) acquire(data []byte) []byte {
+	sum := sha256.Sum256(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payloads[sum]
+	if !ok {
+		p = &payload{data: append([]byte(nil), data...)}
+		s.payloads[sum] = p
+	}
+
+	p.refs++
+	return p.data
+}
+
+// release decrements the reference count of the stored payload for data and
+// frees it when it is no longer referenced
+func (s *PayloadStore) release(data []byte) {
+	sum := sha256.Sum256(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payloads[sum]
+	if !ok {
+		return
+	}
+
+	p.refs--
+	if p.refs <= 0 {
+		delete(s.payloads, sum)
+	}
+}
+
+// Len returns the number of distinct stored payloads
+func (s *PayloadStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.payloads)
+}
@@ -0,0 +1,60 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_PayloadDeduplication(t *testing.T) {
+	t.Run("identical payloads are stored once", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithPayloadDeduplication(), WithMaxSegmentSize(10))
+		assert.NilError(t, err)
+
+		d := newTestData(t, "1")
+		for i := 0; i < 15; i++ {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		assert.Equal(t, l.payloads.Len(), 1)
+		assert.Equal(t, &l.history.data[0].Data[0], &l.active.data[0].Data[0])
+
+		r, err := l.Read(ctx, 3)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data, d)
+
+		// reads return copies
+		r.Data[0] = 'x'
+		r, err = l.Read(ctx, 4)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data, d)
+	})
+
+	t.Run("payloads are freed when purged", func(t *testing.T) {
+		const segSize = 5
+
+		ctx := context.Background()
+		l, err := New(ctx, WithPayloadDeduplication(), WithMaxSegmentSize(segSize))
+		assert.NilError(t, err)
+
+		// fill first segment with the same payload
+		for i := 0; i < segSize; i++ {
+			_, err = l.Write(ctx, newTestData(t, "dup"))
+			assert.NilError(t, err)
+		}
+
+		// purge first segment
+		for _, d := range NewTestDataSlice(t, segSize*2) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		assert.Equal(t, l.payloads.Len(), segSize*2)
+		for _, p := range l.payloads.payloads {
+			assert.Equal(t, p.refs, 1)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DownsamplePolicy decides whether a record of a purged segment is kept in the
+// downsampled history of the log, see WithDownsampling(). Records are offered
+// in offset order.
+type DownsamplePolicy func(Record) bool
+
+// DownsampleEvery keeps every n-th record by offset, i.e. records with an
+// offset divisible by n. Returns nil if n is not greater than 0.
+func DownsampleEvery(n int) DownsamplePolicy {
+	if n <= 0 {
+		return nil
+	}
+
+	return func(r Record) bool {
+		return r.Metadata.Offset%Offset(n) == 0
+	}
+}
+
+// DownsamplePerKey keeps the first record per key within each interval, e.g.
+// one sample per metric and minute. Intervals are aligned to the creation time
+// of records (see time.Time.Truncate()). Records without a key are sampled as
+// one key. Returns nil if interval is not greater than 0.
+func DownsamplePerKey(interval time.Duration) DownsamplePolicy {
+	if interval <= 0 {
+		return nil
+	}
+
+	kept := make(map[string]time.Time) // key to interval of last kept record
+	return func(r Record) bool {
+		window := r.Metadata.Created.Truncate(interval)
+		key := string(r.Metadata.Key)
+
+		if last, ok := kept[key]; ok && !window.After(last) {
+			return false
+		}
+		kept[key] = window
+		return true
+	}
+}
+
+// WithDownsampling keeps a downsampled subset of the records of purged
+// segments selected by the given policy in a coarse history tier instead of
+// dropping them entirely, e.g. for metrics-like event streams. The coarse
+// history holds at most maxRecords records. When it is full, the oldest
+// records are dropped. See DownsampledHistory().
+func WithDownsampling(policy DownsamplePolicy, maxRecords int) Option {
+	return func(log *Log) error {
+		if policy == nil {
+			return errors.New("downsample policy must not be nil")
+		}
+
+		if maxRecords <= 0 {
+			return errors.New("max records must be greater than 0")
+		}
+
+		log.downsampling = &downsampler{
+			policy: policy,
+			max:    maxRecords,
+		}
+		return nil
+	}
+}
+
+// downsampler keeps a downsampled subset of purged records
+type downsampler struct {
+	policy  DownsamplePolicy
+	max     int
+	records []Record // ordered by offset
+}
+
+// DownsampledHistory returns the records kept from purged segments with
+// WithDownsampling() ordered by offset. The records are older than the
+// earliest record of the log. Without downsampling, an empty slice is
+// returned.
+//
+// Safe for concurrent use.
+func (l *Log) DownsampledHistory(_ context.Context) []Record {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.downsampling == nil {
+		return []Record{}
+	}
+
+	records := make([]Record, 0, len(l.downsampling.records))
+	for _, r := range l.downsampling.records {
+		records = append(records, r.deepCopy())
+	}
+	return records
+}
+
+// downsample keeps the records of the given segment selected by the downsample
+// policy before the segment is purged. Must be protected with a lock by the
+// caller.
+func (l *Log) downsample(s *segment) error {
+	d := l.downsampling
+	if d == nil {
+		return nil
+	}
+
+	// payloads of compressed segments are needed for the copies
+	if err := s.touch(l.clock.Now()); err != nil {
+		return err
+	}
+
+	for i, r := range s.data {
+		if r.Metadata.Offset != s.start+Offset(i) {
+			// gap
+			continue
+		}
+
+		if d.policy(r) {
+			// copy, payloads of the segment are released on purge
+			d.records = append(d.records, r.deepCopy())
+		}
+	}
+
+	if drop := len(d.records) - d.max; drop > 0 {
+		d.records = append([]Record(nil), d.records[drop:]...)
+	}
+
+	return nil
+}
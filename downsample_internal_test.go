@@ -0,0 +1,143 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithDownsampling(t *testing.T) {
+	testCases := []struct {
+		name    string
+		policy  DownsamplePolicy
+		max     int
+		wantErr string
+	}{
+		{name: "fails with nil policy", policy: nil, max: 10, wantErr: "policy must not be nil"},
+		{name: "fails with invalid every", policy: DownsampleEvery(0), max: 10, wantErr: "policy must not be nil"},
+		{name: "fails with invalid interval", policy: DownsamplePerKey(0), max: 10, wantErr: "policy must not be nil"},
+		{name: "fails with invalid max", policy: DownsampleEvery(2), max: 0, wantErr: "max records must be greater than 0"},
+		{name: "succeeds", policy: DownsampleEvery(2), max: 10},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(context.Background(), WithDownsampling(tc.policy, tc.max))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestLog_DownsampledHistory(t *testing.T) {
+	t.Run("empty without downsampling", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.DeepEqual(t, l.DownsampledHistory(ctx), []Record{})
+	})
+
+	t.Run("keeps every n-th purged record", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(5), WithDownsampling(DownsampleEvery(3), 100))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 20)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		// offsets 0-9 purged
+		history := l.DownsampledHistory(ctx)
+		assert.Equal(t, len(history), 4)
+		for i, want := range []Offset{0, 3, 6, 9} {
+			assert.Equal(t, history[i].Metadata.Offset, want)
+			assert.DeepEqual(t, history[i].Data, data[want])
+		}
+	})
+
+	t.Run("drops oldest records when full", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(5), WithDownsampling(DownsampleEvery(1), 3))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 20) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		history := l.DownsampledHistory(ctx)
+		assert.Equal(t, len(history), 3)
+		assert.Equal(t, history[0].Metadata.Offset, Offset(7))
+		assert.Equal(t, history[2].Metadata.Offset, Offset(9))
+	})
+
+	t.Run("keeps one record per key and interval", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx,
+			WithClock(mockClock),
+			WithMaxSegmentSize(6),
+			WithDownsampling(DownsamplePerKey(time.Minute), 100),
+		)
+		assert.NilError(t, err)
+
+		for i := 0; i < 6; i++ {
+			for _, key := range []string{"cpu", "mem"} {
+				_, err = l.Write(ctx, []byte(key), WithKey([]byte(key)))
+				assert.NilError(t, err)
+			}
+			mockClock.Add(30 * time.Second)
+		}
+
+		// purge offsets 0-5, i.e. 1m30s of samples
+		for _, d := range NewTestDataSlice(t, 6) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		var got []Offset
+		for _, r := range l.DownsampledHistory(ctx) {
+			got = append(got, r.Metadata.Offset)
+		}
+		assert.DeepEqual(t, got, []Offset{0, 1, 4, 5})
+	})
+
+	t.Run("downsamples compressed segments", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(2), WithDownsampling(DownsampleEvery(1), 10))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 4)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		mockClock.Add(time.Hour)
+		n, err := l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 1)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		history := l.DownsampledHistory(ctx)
+		assert.Equal(t, len(history), 2)
+		assert.DeepEqual(t, history[0].Data, data[0])
+	})
+}
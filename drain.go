@@ -0,0 +1,100 @@
+package memlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DrainError is returned by Drain when registered consumers did not commit
+// the latest offset of the log before the context expired
+type DrainError struct {
+	// Latest is the latest offset of the log when draining started
+	Latest Offset
+	// Behind are the consumers which have not committed the latest offset
+	Behind []ConsumerStatus
+	// Err is the context error
+	Err error
+}
+
+func (e *DrainError) Error() string {
+	names := make([]string, 0, len(e.Behind))
+	for _, c := range e.Behind {
+		names = append(names, fmt.Sprintf("%s (committed %d)", c.Name, c.Committed))
+	}
+
+	return fmt.Sprintf("drain log: consumers behind latest offset %d: %s: %v", e.Latest, strings.Join(names, ", "), e.Err)
+}
+
+func (e *DrainError) Unwrap() error {
+	return e.Err
+}
+
+// Drain stops accepting writes and blocks until all registered consumers have
+// committed the latest offset of the log (see Commit()) or the context
+// expires, e.g. to cleanly hand over before a restart. Writes to a drained log
+// fail with ErrDraining. Consumers are not required to be alive, i.e. a dead
+// consumer blocks Drain until it is unregistered (see Unregister()).
+//
+// If the context expires before all consumers have caught up, a *DrainError
+// reporting the consumers still behind is returned and the log accepts writes
+// again. If the log is closed while draining, ErrClosed is returned.
+//
+// Safe for concurrent use.
+func (l *Log) Drain(ctx context.Context) error {
+	l.mu.Lock()
+	l.draining++
+	// purged records count as written, i.e. consumers must commit them
+	latest, unwritten := l.offset-1, l.unwritten()
+	l.mu.Unlock()
+
+	// finish stops draining and keeps rejecting writes if drained
+	finish := func(drained bool) {
+		l.mu.Lock()
+		l.draining--
+		l.drained = l.drained || drained
+		l.mu.Unlock()
+	}
+
+	if unwritten {
+		finish(true)
+		return nil
+	}
+
+	ticker := l.clock.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		behind := l.consumersBehind(ctx, latest)
+		if len(behind) == 0 {
+			finish(true)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			finish(false)
+			return &DrainError{
+				Latest: latest,
+				Behind: behind,
+				Err:    ctx.Err(),
+			}
+		case <-l.done:
+			finish(false)
+			return ErrClosed
+		case <-ticker.Chan():
+		}
+	}
+}
+
+// consumersBehind returns the status of all registered consumers which have
+// not committed the given offset ordered by name
+func (l *Log) consumersBehind(ctx context.Context, offset Offset) []ConsumerStatus {
+	var behind []ConsumerStatus
+	for _, c := range l.Consumers(ctx) {
+		if c.Committed < offset {
+			behind = append(behind, c)
+		}
+	}
+	return behind
+}
@@ -0,0 +1,136 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Drain(t *testing.T) {
+	t.Run("returns immediately for empty log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		assert.NilError(t, l.Heartbeat(ctx, "billing"))
+		assert.NilError(t, l.Drain(ctx))
+
+		_, err = l.Write(ctx, newTestData(t, "1"))
+		assert.Assert(t, errors.Is(err, ErrDraining))
+	})
+
+	t.Run("reports consumers behind latest offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		assert.NilError(t, l.Commit(ctx, "billing", 4))
+		assert.NilError(t, l.Commit(ctx, "audit", 2))
+		assert.NilError(t, l.Heartbeat(ctx, "metrics"))
+
+		drainCtx, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+		defer cancel()
+
+		err = l.Drain(drainCtx)
+		assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+
+		var drainErr *DrainError
+		assert.Assert(t, errors.As(err, &drainErr))
+		assert.Equal(t, drainErr.Latest, Offset(4))
+		assert.Equal(t, len(drainErr.Behind), 2)
+		assert.Equal(t, drainErr.Behind[0].Name, "audit")
+		assert.Equal(t, drainErr.Behind[0].Committed, Offset(2))
+		assert.Equal(t, drainErr.Behind[1].Name, "metrics")
+		assert.Equal(t, drainErr.Behind[1].Committed, Offset(-1))
+		assert.ErrorContains(t, err, "audit (committed 2), metrics (committed -1)")
+
+		// accepts writes again after the failed drain
+		_, err = l.Write(ctx, newTestData(t, "after drain"))
+		assert.NilError(t, err)
+	})
+
+	t.Run("waits for consumers of purged records", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d, WithTTL(time.Second))
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.Commit(ctx, "billing", 2))
+
+		mockClock.Add(time.Second)
+		n, err := l.PurgeExpired(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 5)
+		assert.Assert(t, l.IsEmpty(ctx))
+
+		drainCtx, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+		defer cancel()
+
+		err = l.Drain(drainCtx)
+		var drainErr *DrainError
+		assert.Assert(t, errors.As(err, &drainErr))
+		assert.Equal(t, drainErr.Latest, Offset(4))
+		assert.Equal(t, len(drainErr.Behind), 1)
+
+		assert.NilError(t, l.Commit(ctx, "billing", 4))
+		assert.NilError(t, l.Drain(ctx))
+	})
+
+	t.Run("waits until consumers have committed", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		c, err := l.NewConsumer(ctx, "billing", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+
+		drained := make(chan error)
+		go func() {
+			drained <- l.Drain(ctx)
+		}()
+
+		// wait until draining to avoid race with Write
+		for {
+			if _, err = l.Write(ctx, newTestData(t, "late")); errors.Is(err, ErrDraining) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		_, latest := l.Range(ctx)
+		for {
+			r, err := c.Next(ctx)
+			if errors.Is(err, ErrFutureOffset) {
+				break
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, r.Metadata.Offset <= latest)
+		}
+		assert.NilError(t, c.Close(ctx))
+
+		select {
+		case err = <-drained:
+			assert.NilError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("drain did not return")
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package memlog
+
+import "context"
+
+// ErrEmptyLog is returned when reading from a log without any record, i.e. no
+// record has been written or all records have been purged.
+// For compatibility, ErrEmptyLog also matches ErrFutureOffset with errors.Is(),
+// since the read offset will be written in the future.
+var ErrEmptyLog error = emptyLogError{}
+
+type emptyLogError struct{}
+
+func (emptyLogError) Error() string {
+	return "empty log"
+}
+
+func (emptyLogError) Is(target error) bool {
+	return target == ErrFutureOffset
+}
+
+// IsEmpty returns true if the log holds no record, i.e. no record has been
+// written or all records have been purged
+//
+// Safe for concurrent use.
+func (l *Log) IsEmpty(_ context.Context) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.isEmpty()
+}
+
+// Bounds returns the earliest and latest available record offset in the log
+// like Range() but returns ErrEmptyLog instead of invalid offsets if the log
+// holds no record.
+//
+// Safe for concurrent use.
+func (l *Log) Bounds(_ context.Context) (earliest, latest Offset, err error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.isEmpty() {
+		return -1, -1, ErrEmptyLog
+	}
+
+	earliest, latest = l.offsetRange()
+	return earliest, latest, nil
+}
+
+// isEmpty returns true if the log holds no record, i.e. no record has been
+// written or all records have been purged. Must be protected with a lock by the
+// caller.
+func (l *Log) isEmpty() bool {
+	return l.history == nil && l.active.currentOffset() == -1
+}
+
+// unwritten returns true if no record has been written to the log since it was
+// created. Unlike isEmpty(), it is false after all records have been purged.
+// Must be protected with a lock by the caller.
+func (l *Log) unwritten() bool {
+	return l.writes == 0
+}
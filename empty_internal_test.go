@@ -0,0 +1,41 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_IsEmpty(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx, WithStartOffset(10))
+	assert.NilError(t, err)
+
+	assert.Assert(t, l.IsEmpty(ctx))
+
+	_, _, err = l.Bounds(ctx)
+	assert.Assert(t, errors.Is(err, ErrEmptyLog))
+
+	_, err = l.Read(ctx, 10)
+	assert.Assert(t, errors.Is(err, ErrEmptyLog))
+	assert.Assert(t, errors.Is(err, ErrFutureOffset))
+
+	_, err = l.Read(ctx, 5)
+	assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+	_, err = l.Write(ctx, newTestData(t, "1"))
+	assert.NilError(t, err)
+
+	assert.Assert(t, !l.IsEmpty(ctx))
+
+	earliest, latest, err := l.Bounds(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, earliest, Offset(10))
+	assert.Equal(t, latest, Offset(10))
+
+	_, err = l.Read(ctx, 11)
+	assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	assert.Assert(t, !errors.Is(err, ErrEmptyLog))
+}
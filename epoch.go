@@ -0,0 +1,46 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrStaleEpoch is returned when a writer epoch is lower than or does not
+// match the current epoch of the log
+var ErrStaleEpoch = errors.New("stale epoch")
+
+// SetEpoch advances the writer epoch of the log, e.g. when a new leader or
+// exclusive writer takes over. Records written afterwards carry the new epoch
+// in their Header, so consumers can detect leadership changes. Writes fenced
+// with an older epoch (see WithEpoch()) are rejected with ErrStaleEpoch. The
+// epoch must be greater than the current epoch, otherwise ErrStaleEpoch is
+// returned.
+//
+// Safe for concurrent use.
+func (l *Log) SetEpoch(ctx context.Context, epoch uint64) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if epoch <= l.epoch {
+		return fmt.Errorf("%w: epoch %d not greater than current epoch %d", ErrStaleEpoch, epoch, l.epoch)
+	}
+
+	l.epoch = epoch
+	return nil
+}
+
+// Epoch returns the current writer epoch of the log. The epoch of a new log is
+// 0.
+//
+// Safe for concurrent use.
+func (l *Log) Epoch() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.epoch
+}
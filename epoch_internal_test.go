@@ -0,0 +1,53 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_SetEpoch(t *testing.T) {
+	t.Run("fails when epoch does not advance", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.SetEpoch(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrStaleEpoch))
+
+		assert.NilError(t, l.SetEpoch(ctx, 5))
+		err = l.SetEpoch(ctx, 3)
+		assert.Assert(t, errors.Is(err, ErrStaleEpoch))
+		assert.Equal(t, l.Epoch(), uint64(5))
+	})
+
+	t.Run("stamps records and fences stale writers", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		first, err := l.Write(ctx, newTestData(t, "1"))
+		assert.NilError(t, err)
+
+		// writer 1 takes over
+		assert.NilError(t, l.SetEpoch(ctx, 1))
+		second, err := l.Write(ctx, newTestData(t, "2"), WithEpoch(1))
+		assert.NilError(t, err)
+
+		// writer 2 takes over, writer 1 is fenced
+		assert.NilError(t, l.SetEpoch(ctx, 2))
+		_, err = l.Write(ctx, newTestData(t, "3"), WithEpoch(1))
+		assert.Assert(t, errors.Is(err, ErrStaleEpoch))
+
+		third, err := l.Write(ctx, newTestData(t, "3"), WithEpoch(2))
+		assert.NilError(t, err)
+
+		for offset, want := range map[Offset]uint64{first: 0, second: 1, third: 2} {
+			r, readErr := l.Read(ctx, offset)
+			assert.NilError(t, readErr)
+			assert.Equal(t, r.Metadata.Epoch, want)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package memlog
+
+import (
+	"errors"
+	"time"
+)
+
+// EventType is the type of a lifecycle event of a log
+type EventType string
+
+const (
+	// EventCreated is emitted when a log is created. Start is the start offset
+	// of the log.
+	EventCreated EventType = "created"
+	// EventSealed is emitted when the active segment becomes read-only before it
+	// is rolled. Start and End are the offsets of the first and last record in
+	// the sealed segment.
+	EventSealed EventType = "sealed"
+	// EventSegmentRolled is emitted when a new active segment is created. Start
+	// is the start offset of the new active segment.
+	EventSegmentRolled EventType = "segmentRolled"
+	// EventPurged is emitted when a segment is removed from the log. Start and
+	// End are the offsets of the first and last record in the purged segment.
+	EventPurged EventType = "purged"
+	// EventClosed is emitted when a log is closed, e.g. removed from a Manager
+	EventClosed EventType = "closed"
+)
+
+// Event describes a state transition of a log
+type Event struct {
+	// Type is the type of the event
+	Type EventType `json:"type"`
+	// Time is the UTC time of the event
+	Time time.Time `json:"time"`
+	// Start is an offset depending on the event type or -1 if not applicable
+	Start Offset `json:"start"`
+	// End is an offset depending on the event type or -1 if not applicable
+	End Offset `json:"end"`
+}
+
+// EventHandler is called with lifecycle events of a log
+type EventHandler func(Event)
+
+// WithEventHandler calls fn on lifecycle events of the log, e.g. to react to
+// segment rolls and purges without polling the log. fn is called
+// synchronously while the log is locked, i.e. fn must not block or call
+// methods of the log.
+func WithEventHandler(fn EventHandler) Option {
+	return func(log *Log) error {
+		if fn == nil {
+			return errors.New("event handler must not be nil")
+		}
+
+		log.events = fn
+		return nil
+	}
+}
+
+// emit calls the event handler, if configured. Must be protected with a lock
+// by the caller.
+func (l *Log) emit(typ EventType, start, end Offset) {
+	if l.events == nil {
+		return
+	}
+
+	l.events(Event{
+		Type:  typ,
+		Time:  l.clock.Now().UTC(),
+		Start: start,
+		End:   end,
+	})
+}
@@ -0,0 +1,61 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Events(t *testing.T) {
+	t.Run("fails with nil handler", func(t *testing.T) {
+		_, err := New(context.Background(), WithEventHandler(nil))
+		assert.ErrorContains(t, err, "must not be nil")
+	})
+
+	t.Run("emits lifecycle events", func(t *testing.T) {
+		ctx := context.Background()
+
+		var events []Event
+		handler := func(e Event) {
+			events = append(events, e)
+		}
+
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		l, err := m.Create(ctx, "orders", WithStartOffset(10), WithMaxSegmentSize(2), WithEventHandler(handler))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, m.Delete(ctx, "orders"))
+
+		type event struct {
+			typ        EventType
+			start, end Offset
+		}
+
+		want := []event{
+			{typ: EventCreated, start: 10, end: -1},
+			{typ: EventSealed, start: 10, end: 11},
+			{typ: EventSegmentRolled, start: 12, end: -1},
+			{typ: EventSealed, start: 12, end: 13},
+			{typ: EventPurged, start: 10, end: 11},
+			{typ: EventSegmentRolled, start: 14, end: -1},
+			{typ: EventPurged, start: 12, end: 13},
+			{typ: EventPurged, start: 14, end: 14},
+			{typ: EventClosed, start: -1, end: -1},
+		}
+
+		assert.Equal(t, len(events), len(want))
+		for i, e := range events {
+			assert.Equal(t, e.Type, want[i].typ, "event %d", i)
+			assert.Equal(t, e.Start, want[i].start, "event %d", i)
+			assert.Equal(t, e.End, want[i].end, "event %d", i)
+			assert.Assert(t, !e.Time.IsZero())
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package memlog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EvictionCandidate describes a retained record which can be evicted when the
+// log exceeds its byte limit (see WithMaxLogBytes())
+type EvictionCandidate struct {
+	// Offset is the offset of the record
+	Offset Offset
+	// Size is the payload size of the record in bytes
+	Size int
+	// Priority is the priority class the record was written with, see
+	// WithPriority()
+	Priority Priority
+	// LastRead is the time of the last read of the record or the zero time if
+	// the record has not been read
+	LastRead time.Time
+}
+
+// EvictionStrategy decides which records are evicted first when the log
+// exceeds its byte limit (see WithEvictionStrategy())
+type EvictionStrategy interface {
+	// Less returns true if record a should be evicted before record b
+	Less(a, b EvictionCandidate) bool
+}
+
+// EvictOldest evicts the records with the lowest offset first. This is the
+// default strategy of WithMaxLogBytes().
+func EvictOldest() EvictionStrategy {
+	return oldestFirst{}
+}
+
+type oldestFirst struct{}
+
+func (oldestFirst) Less(a, b EvictionCandidate) bool {
+	return a.Offset < b.Offset
+}
+
+// EvictLeastRecentlyRead evicts the records which have not been read for the
+// longest time first. Records which have never been read are evicted before
+// read records, the oldest record first.
+func EvictLeastRecentlyRead() EvictionStrategy {
+	return leastRecentlyRead{}
+}
+
+type leastRecentlyRead struct{}
+
+func (leastRecentlyRead) Less(a, b EvictionCandidate) bool {
+	if !a.LastRead.Equal(b.LastRead) {
+		return a.LastRead.Before(b.LastRead)
+	}
+	return a.Offset < b.Offset
+}
+
+// EvictByPriority evicts the records with the lowest priority class (see
+// WithPriority()) first, the oldest record of a priority class first, e.g. to
+// retain critical records at the expense of low priority records.
+func EvictByPriority() EvictionStrategy {
+	return lowestPriority{}
+}
+
+type lowestPriority struct{}
+
+func (lowestPriority) Less(a, b EvictionCandidate) bool {
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	return a.Offset < b.Offset
+}
+
+// WithEvictionStrategy sets the strategy deciding which records are evicted
+// when the log exceeds the limit set with WithMaxLogBytes(), e.g.
+// EvictByPriority() for logs with mixed-criticality data. Unless the strategy
+// is EvictOldest(), evicted records are removed independent of their position
+// in the log and read like gaps of logs with sparse offsets (see
+// ErrOffsetGap). The latest record and records held by a consumer (see
+// HoldPurge()) are never evicted.
+func WithEvictionStrategy(s EvictionStrategy) Option {
+	return func(log *Log) error {
+		if s == nil {
+			return errors.New("eviction strategy must not be nil")
+		}
+
+		log.eviction = &evictor{
+			strategy: s,
+			lastRead: make(map[Offset]time.Time),
+			priority: make(map[Offset]Priority),
+		}
+		return nil
+	}
+}
+
+// evictor tracks the record state used by an eviction strategy. Safe for
+// concurrent use.
+type evictor struct {
+	strategy EvictionStrategy
+
+	mu       sync.Mutex // records are read under the read lock of the log
+	lastRead map[Offset]time.Time
+	priority map[Offset]Priority // records with a priority other than PriorityNormal
+}
+
+// wrote records the priority of a written record
+func (e *evictor) wrote(offset Offset, p Priority) {
+	if p == PriorityNormal {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.priority[offset] = p
+}
+
+// read records the read time of a record
+func (e *evictor) read(offset Offset, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastRead[offset] = now
+}
+
+// candidate returns the eviction candidate for the given record
+func (e *evictor) candidate(r Record) EvictionCandidate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return EvictionCandidate{
+		Offset:   r.Metadata.Offset,
+		Size:     len(r.Data),
+		Priority: e.priority[r.Metadata.Offset],
+		LastRead: e.lastRead[r.Metadata.Offset],
+	}
+}
+
+// forget removes the state of the given record
+func (e *evictor) forget(offset Offset) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.lastRead, offset)
+	delete(e.priority, offset)
+}
+
+// prune removes the state of purged records, i.e. with an offset lower than
+// the given offset, once the tracked records exceed twice the given number of
+// retained records
+func (e *evictor) prune(before Offset, retained int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.lastRead)+len(e.priority) <= 2*retained {
+		return
+	}
+
+	for offset := range e.lastRead {
+		if offset < before {
+			delete(e.lastRead, offset)
+		}
+	}
+	for offset := range e.priority {
+		if offset < before {
+			delete(e.priority, offset)
+		}
+	}
+}
+
+// evict removes retained records in the order of the eviction strategy until
+// the given number of excess bytes is freed. Must be protected with a lock by
+// the caller.
+func (l *Log) evict(excess int64) error {
+	_, latest := l.offsetRange()
+
+	var candidates []EvictionCandidate
+	for _, s := range []*segment{l.history, l.active} {
+		if s == nil {
+			continue
+		}
+
+		// payloads of compressed segments are compressed as a whole
+		if err := s.touch(l.clock.Now()); err != nil {
+			return err
+		}
+
+		for i, r := range s.data {
+			offset := s.start + Offset(i)
+			if r.Metadata.Offset != offset || offset == latest || l.checkPurge(offset) != nil {
+				continue
+			}
+			candidates = append(candidates, l.eviction.candidate(r))
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return l.eviction.strategy.Less(candidates[i], candidates[j])
+	})
+
+	for _, c := range candidates {
+		if excess <= 0 {
+			break
+		}
+
+		s, err := l.getSegment(c.Offset)
+		if err != nil {
+			return err
+		}
+
+		i := c.Offset - s.start
+		l.emit(EventPurged, c.Offset, c.Offset)
+		s.bytes -= len(s.data[i].Data)
+		l.releasePayload(s.data[i].Data)
+		s.data[i] = gapRecord
+		l.eviction.forget(c.Offset)
+		l.purged++
+		excess -= int64(c.Size)
+	}
+
+	// advance the earliest offset past evicted records
+	earliest, _ := l.offsetRange()
+	if before := l.nextWritten(earliest); before > earliest {
+		if err := l.trim(before); err != nil {
+			return fmt.Errorf("purge evicted records: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithEvictionStrategy(t *testing.T) {
+	t.Run("fails without strategy", func(t *testing.T) {
+		_, err := New(context.Background(), WithMaxLogBytes(10), WithEvictionStrategy(nil))
+		assert.ErrorContains(t, err, "must not be nil")
+	})
+
+	t.Run("fails without max log bytes", func(t *testing.T) {
+		_, err := New(context.Background(), WithEvictionStrategy(EvictOldest()))
+		assert.ErrorContains(t, err, "requires max log bytes")
+	})
+}
+
+func TestLog_evict(t *testing.T) {
+	type write struct {
+		priority Priority
+		read     bool
+	}
+
+	testCases := []struct {
+		name     string
+		strategy EvictionStrategy
+		writes   []write
+		retained []Offset
+	}{
+		{
+			name:     "oldest first",
+			strategy: EvictOldest(),
+			writes:   []write{{}, {}, {}, {}},
+			retained: []Offset{2, 3},
+		},
+		{
+			name:     "least recently read",
+			strategy: EvictLeastRecentlyRead(),
+			writes:   []write{{read: true}, {}, {read: true}, {}},
+			retained: []Offset{2, 3},
+		},
+		{
+			name:     "never read before read records",
+			strategy: EvictLeastRecentlyRead(),
+			writes:   []write{{read: true}, {}, {}, {}},
+			retained: []Offset{0, 3},
+		},
+		{
+			name:     "lowest priority first",
+			strategy: EvictByPriority(),
+			writes: []write{
+				{priority: PriorityHigh},
+				{priority: PriorityNormal},
+				{priority: PriorityLow},
+				{priority: PriorityNormal},
+			},
+			retained: []Offset{0, 3},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClock := clock.NewMock()
+			l, err := New(ctx,
+				WithClock(mockClock),
+				WithMaxSegmentSize(10),
+				WithMaxLogBytes(15),
+				WithEvictionStrategy(tc.strategy),
+			)
+			assert.NilError(t, err)
+
+			for _, w := range tc.writes[:3] {
+				offset, err := l.Write(ctx, make([]byte, 6), WithPriority(w.priority))
+				assert.NilError(t, err)
+				mockClock.Add(time.Second)
+
+				if w.read {
+					_, err = l.Read(ctx, offset)
+					assert.NilError(t, err)
+				}
+			}
+
+			// exceeds the limit by 9 bytes
+			_, err = l.Write(ctx, make([]byte, 6), WithPriority(tc.writes[3].priority))
+			assert.NilError(t, err)
+			assert.Equal(t, l.RetainedBytes(), 12)
+
+			var retained []Offset
+			for offset := Offset(0); offset < 4; offset++ {
+				_, err := l.Read(ctx, offset)
+				if err == nil {
+					retained = append(retained, offset)
+					continue
+				}
+				assert.Assert(t, errors.Is(err, ErrOffsetGap) || errors.Is(err, ErrOutOfRange), err)
+			}
+			assert.DeepEqual(t, retained, tc.retained)
+
+			earliest, _ := l.Range(ctx)
+			assert.Equal(t, earliest, tc.retained[0])
+		})
+	}
+
+	t.Run("does not evict held records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxLogBytes(10), WithEvictionStrategy(EvictByPriority()))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, make([]byte, 6), WithPriority(PriorityLow))
+		assert.NilError(t, err)
+		assert.NilError(t, l.HoldPurge(ctx, "consumer", 0))
+
+		_, err = l.Write(ctx, make([]byte, 6), WithPriority(PriorityHigh))
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 12)
+
+		assert.NilError(t, l.ReleasePurge(ctx, "consumer"))
+		_, err = l.Write(ctx, make([]byte, 2), WithPriority(PriorityHigh))
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 8)
+
+		earliest, _ := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(1))
+	})
+}
@@ -0,0 +1,89 @@
+package memlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// exportBatchSize is the number of records read per lock acquisition during
+// an export
+const exportBatchSize = 256
+
+// Format is the output format of Export()
+type Format int
+
+const (
+	// FormatJSONLines writes one JSON object per record and line with the
+	// offset, the creation time (RFC 3339) and the base64-encoded payload of
+	// the record
+	FormatJSONLines Format = iota
+)
+
+// exportRecord is a record written with FormatJSONLines
+type exportRecord struct {
+	Offset    Offset    `json:"offset"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      []byte    `json:"data"`
+}
+
+// Export writes the records retained at the time of the call in the given
+// format to w, e.g. to dump the log for offline debugging. Gaps are skipped.
+// Records are read in batches, i.e. writes are not blocked by a slow writer
+// w. If records are purged during the export, ErrOutOfRange is returned.
+//
+// Safe for concurrent use.
+func (l *Log) Export(ctx context.Context, w io.Writer, format Format) error {
+	if w == nil {
+		return errors.New("writer must not be nil")
+	}
+
+	if format != FormatJSONLines {
+		return fmt.Errorf("unsupported export format %d", format)
+	}
+
+	l.mu.RLock()
+	closed := l.closed
+	earliest, latest := l.offsetRange()
+	l.mu.RUnlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	if earliest == -1 {
+		return nil
+	}
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	for next := earliest; next <= latest; {
+		records, err := l.ReadBatch(ctx, next, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("read batch at offset %d: %w", next, err)
+		}
+
+		for _, r := range records {
+			if r.Metadata.Offset > latest {
+				break
+			}
+
+			er := exportRecord{Offset: r.Metadata.Offset, Timestamp: r.Metadata.Created, Data: r.Data}
+			if err = enc.Encode(er); err != nil {
+				return fmt.Errorf("write record %d: %w", r.Metadata.Offset, err)
+			}
+		}
+
+		if len(records) == 0 {
+			break
+		}
+		next = records[len(records)-1].Metadata.Offset + 1
+	}
+
+	return buf.Flush()
+}
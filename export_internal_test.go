@@ -0,0 +1,102 @@
+package memlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Export(t *testing.T) {
+	t.Run("fails with invalid input", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.Export(ctx, nil, FormatJSONLines)
+		assert.ErrorContains(t, err, "writer must not be nil")
+
+		err = l.Export(ctx, &bytes.Buffer{}, Format(42))
+		assert.ErrorContains(t, err, "unsupported export format")
+
+		assert.NilError(t, l.Close())
+		err = l.Export(ctx, &bytes.Buffer{}, FormatJSONLines)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+	})
+
+	t.Run("empty log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		var buf bytes.Buffer
+		assert.NilError(t, l.Export(ctx, &buf, FormatJSONLines))
+		assert.Equal(t, buf.Len(), 0)
+	})
+
+	t.Run("writes retained records as json lines", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(10), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		// purge first segment
+		for i := 0; i < 20; i++ {
+			_, err = l.Write(ctx, []byte("purged"))
+			assert.NilError(t, err)
+		}
+		_, err = l.WriteAt(ctx, 25, []byte("after gap"))
+		assert.NilError(t, err)
+
+		var buf bytes.Buffer
+		assert.NilError(t, l.Export(ctx, &buf, FormatJSONLines))
+
+		var offsets []Offset
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var r struct {
+				Offset    Offset    `json:"offset"`
+				Timestamp time.Time `json:"timestamp"`
+				Data      []byte    `json:"data"`
+			}
+			assert.NilError(t, json.Unmarshal(scanner.Bytes(), &r))
+			assert.Assert(t, r.Timestamp.Equal(mockClock.Now().UTC()))
+			offsets = append(offsets, r.Offset)
+
+			if r.Offset == 25 {
+				assert.Equal(t, string(r.Data), "after gap")
+			}
+		}
+		assert.NilError(t, scanner.Err())
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, offsets[0], earliest)
+		assert.Equal(t, offsets[len(offsets)-1], latest)
+		assert.Equal(t, latest, Offset(25))
+		for i := 1; i < len(offsets); i++ {
+			assert.Assert(t, offsets[i] > offsets[i-1])
+		}
+	})
+
+	t.Run("reads in batches", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(exportBatchSize*2))
+		assert.NilError(t, err)
+
+		n := exportBatchSize + 10
+		for i := 0; i < n; i++ {
+			_, err = l.Write(ctx, []byte("data"))
+			assert.NilError(t, err)
+		}
+
+		var buf bytes.Buffer
+		assert.NilError(t, l.Export(ctx, &buf, FormatJSONLines))
+		assert.Equal(t, bytes.Count(buf.Bytes(), []byte("\n")), n)
+	})
+}
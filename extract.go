@@ -0,0 +1,62 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ContextExtractor returns record headers derived from the context of a write,
+// e.g. a request ID or tenant stored in the context by a middleware
+type ContextExtractor func(ctx context.Context) map[string][]byte
+
+// WithContextExtractor adds the headers returned by fn for the context of
+// every write to the written record, so that provenance metadata is captured
+// consistently without every caller specifying it. Headers specified with
+// write options take precedence over extracted headers. Multiple extractors
+// are applied in order.
+func WithContextExtractor(fn ContextExtractor) Option {
+	return func(log *Log) error {
+		if fn == nil {
+			return errors.New("context extractor must not be nil")
+		}
+
+		log.extractors = append(log.extractors, fn)
+		return nil
+	}
+}
+
+// ContextValueExtractor returns a ContextExtractor which stores the context
+// value of the given key in the named header. Values of type string, []byte
+// and fmt.Stringer are supported, other values and missing keys are ignored.
+func ContextValueExtractor(header string, key interface{}) ContextExtractor {
+	return func(ctx context.Context) map[string][]byte {
+		var value []byte
+		switch v := ctx.Value(key).(type) {
+		case string:
+			value = []byte(v)
+		case []byte:
+			value = v
+		case fmt.Stringer:
+			value = []byte(v.String())
+		default:
+			return nil
+		}
+
+		return map[string][]byte{header: value}
+	}
+}
+
+// extractHeaders adds the headers of the configured context extractors to the
+// write configuration unless already set
+func (l *Log) extractHeaders(ctx context.Context, wc *writeConfig) {
+	for _, fn := range l.extractors {
+		for k, v := range fn(ctx) {
+			if _, ok := wc.headers[k]; ok {
+				continue
+			}
+
+			WithHeader(k, v)(wc)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type ctxKey string
+
+type tenant struct {
+	id string
+}
+
+func (t tenant) String() string {
+	return "tenant-" + t.id
+}
+
+func TestLog_ContextExtractor(t *testing.T) {
+	t.Run("fails with nil extractor", func(t *testing.T) {
+		_, err := New(context.Background(), WithContextExtractor(nil))
+		assert.ErrorContains(t, err, "must not be nil")
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey("request"), "req-1")
+	ctx = context.WithValue(ctx, ctxKey("tenant"), tenant{id: "a"})
+	ctx = context.WithValue(ctx, ctxKey("user"), []byte("alice"))
+	ctx = context.WithValue(ctx, ctxKey("ignored"), 42)
+
+	newLog := func(t *testing.T) *Log {
+		l, err := New(ctx,
+			WithContextExtractor(ContextValueExtractor("request-id", ctxKey("request"))),
+			WithContextExtractor(ContextValueExtractor("tenant", ctxKey("tenant"))),
+			WithContextExtractor(ContextValueExtractor("user", ctxKey("user"))),
+			WithContextExtractor(ContextValueExtractor("ignored", ctxKey("ignored"))),
+			WithContextExtractor(ContextValueExtractor("missing", ctxKey("missing"))),
+		)
+		assert.NilError(t, err)
+		return l
+	}
+
+	want := map[string][]byte{
+		"request-id": []byte("req-1"),
+		"tenant":     []byte("tenant-a"),
+		"user":       []byte("bob"), // explicit header takes precedence
+	}
+
+	t.Run("captures context values on write", func(t *testing.T) {
+		l := newLog(t)
+
+		offset, err := l.Write(ctx, newTestData(t, "1"), WithHeader("user", []byte("bob")))
+		assert.NilError(t, err)
+
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Headers, want)
+	})
+
+	t.Run("captures context values on buffered producer write", func(t *testing.T) {
+		l := newLog(t)
+
+		p, err := l.NewProducer(WithLinger(time.Hour))
+		assert.NilError(t, err)
+
+		assert.NilError(t, p.Write(ctx, newTestData(t, "1"), WithHeader("user", []byte("bob"))))
+		assert.NilError(t, p.Close(context.Background()))
+
+		r, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Headers, want)
+	})
+}
@@ -0,0 +1,71 @@
+package memlog
+
+import (
+	"fmt"
+	"time"
+)
+
+// writeRateWeight is the weight of the latest interval between writes in the
+// moving average of the write interval
+const writeRateWeight = 0.2
+
+// FutureOffsetError is returned when reading an offset which has not been
+// written yet. It matches ErrFutureOffset with errors.Is() and provides hints
+// for polling readers to back off instead of reading in a tight loop.
+type FutureOffsetError struct {
+	// Offset is the requested offset
+	Offset Offset
+	// Latest is the latest offset of the log
+	Latest Offset
+	// WaitHint is the estimated duration until the requested offset is written
+	// based on the recent write rate of the log. Zero if the write rate is
+	// unknown.
+	WaitHint time.Duration
+}
+
+func (e *FutureOffsetError) Error() string {
+	return fmt.Sprintf("%v: offset %d, latest offset %d, wait hint %v", ErrFutureOffset, e.Offset, e.Latest, e.WaitHint)
+}
+
+func (e *FutureOffsetError) Unwrap() error {
+	return ErrFutureOffset
+}
+
+// writeRate tracks the moving average of the interval between writes
+type writeRate struct {
+	last     time.Time     // time of the last write
+	interval time.Duration // zero until two writes have been observed
+}
+
+// observe records a write at the given time
+func (w *writeRate) observe(now time.Time) {
+	if !w.last.IsZero() {
+		d := now.Sub(w.last)
+		if w.interval == 0 {
+			w.interval = d
+		} else {
+			w.interval = time.Duration(writeRateWeight*float64(d) + (1-writeRateWeight)*float64(w.interval))
+		}
+	}
+	w.last = now
+}
+
+// futureOffsetError returns the error for reading the given future offset.
+// Must be protected with a lock by the caller.
+func (l *Log) futureOffsetError(offset Offset) error {
+	err := FutureOffsetError{
+		Offset: offset,
+		Latest: l.offset - 1,
+	}
+
+	if interval := l.rate.interval; interval > 0 {
+		pending := time.Duration(offset-l.offset+1) * interval
+		err.WaitHint = pending - l.clock.Since(l.rate.last)
+		if err.WaitHint <= 0 {
+			// overdue, expect the next write within one interval
+			err.WaitHint = interval
+		}
+	}
+
+	return &err
+}
@@ -0,0 +1,62 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_FutureOffsetError(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clock.NewMock()
+	l, err := New(ctx, WithClock(mockClock))
+	assert.NilError(t, err)
+
+	_, err = l.Write(ctx, newTestData(t, "data"))
+	assert.NilError(t, err)
+
+	// write rate unknown
+	_, err = l.Read(ctx, 1)
+	var futureErr *FutureOffsetError
+	assert.Assert(t, errors.As(err, &futureErr))
+	assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	assert.DeepEqual(t, *futureErr, FutureOffsetError{Offset: 1, Latest: 0})
+
+	// one write per second
+	for i := 0; i < 5; i++ {
+		mockClock.Add(time.Second)
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+	}
+
+	mockClock.Add(time.Millisecond * 400)
+	_, err = l.Read(ctx, 8)
+	assert.Assert(t, errors.As(err, &futureErr))
+	assert.DeepEqual(t, *futureErr, FutureOffsetError{Offset: 8, Latest: 5, WaitHint: time.Millisecond * 2600})
+
+	// overdue writes
+	mockClock.Add(time.Minute)
+	_, err = l.Read(ctx, 6)
+	assert.Assert(t, errors.As(err, &futureErr))
+	assert.Equal(t, futureErr.WaitHint, time.Second)
+	assert.ErrorContains(t, err, "future offset: offset 6, latest offset 5")
+}
+
+func Test_writeRate(t *testing.T) {
+	var w writeRate
+	start := time.Now()
+
+	w.observe(start)
+	assert.Equal(t, w.interval, time.Duration(0))
+
+	w.observe(start.Add(time.Second))
+	assert.Equal(t, w.interval, time.Second)
+
+	// moving average
+	w.observe(start.Add(time.Second * 3))
+	assert.Equal(t, w.interval, time.Millisecond*1200)
+}
@@ -0,0 +1,307 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrMemberNotFound is returned when a member has left its consumer group
+var ErrMemberNotFound = errors.New("group member not found")
+
+// GroupOption customizes a ConsumerGroup
+type GroupOption func(*ConsumerGroup) error
+
+// WithGroupResyncOnMismatch resumes a consumer group at the earliest record of
+// the log if its checkpoint was created against another log incarnation (see
+// Log.ID()) instead of failing with ErrLogMismatch
+func WithGroupResyncOnMismatch() GroupOption {
+	return func(g *ConsumerGroup) error {
+		g.resync = true
+		return nil
+	}
+}
+
+// ConsumerGroup distributes the records of a log among its members, i.e. each
+// record is delivered to one member of the group. Members acknowledge
+// processed records with Ack(). The group commits its progress, i.e. the
+// highest offset up to which all delivered records have been acknowledged, to
+// a CheckpointStore with the name of the group. A group created with the name
+// of an existing checkpoint resumes after the committed offset, e.g. when the
+// members rejoin after a restart. Records delivered to a member which leaves
+// the group before acknowledging them are delivered to the remaining members
+// again.
+//
+// Safe for concurrent use.
+type ConsumerGroup struct {
+	log    *Log
+	name   string
+	store  CheckpointStore
+	resync bool
+
+	mu        sync.Mutex
+	next      Offset            // next offset to deliver
+	committed Offset            // last committed offset
+	pending   map[Offset]string // delivered, unacknowledged offsets by member
+	redeliver []Offset          // unacknowledged offsets of members which left, in order
+	members   map[string]*GroupMember
+}
+
+// GroupMember is a member of a consumer group reading records of the log,
+// see ConsumerGroup.Join()
+type GroupMember struct {
+	group *ConsumerGroup
+	name  string
+}
+
+// NewConsumerGroup creates a consumer group with the given name reading from
+// the log. The group is registered with the log as a consumer (see Commit())
+// and resumes after its last checkpoint in the store or at the earliest record
+// of the log if no checkpoint exists. If the checkpoint was created against
+// another log incarnation, ErrLogMismatch is returned unless
+// WithGroupResyncOnMismatch() is specified.
+func (l *Log) NewConsumerGroup(ctx context.Context, name string, store CheckpointStore, options ...GroupOption) (*ConsumerGroup, error) {
+	if name == "" {
+		return nil, errors.New("group name must not be empty")
+	}
+
+	if store == nil {
+		return nil, errors.New("checkpoint store must not be nil")
+	}
+
+	g := ConsumerGroup{
+		log:     l,
+		name:    name,
+		store:   store,
+		pending: make(map[Offset]string),
+		members: make(map[string]*GroupMember),
+	}
+
+	for _, opt := range options {
+		if err := opt(&g); err != nil {
+			return nil, fmt.Errorf("configure group option: %v", err)
+		}
+	}
+
+	committed, err := l.resume(ctx, name, store, g.resync)
+	if err != nil {
+		return nil, err
+	}
+	g.committed = committed
+	g.next = committed + 1
+	if committed == -1 {
+		g.next = l.resumeStart(ctx)
+	}
+
+	if err = l.Commit(ctx, name, g.committed); err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// Name returns the name of the group
+func (g *ConsumerGroup) Name() string {
+	return g.name
+}
+
+// Join adds the named member to the group. Joining with the name of a current
+// member returns that member.
+func (g *ConsumerGroup) Join(member string) (*GroupMember, error) {
+	if member == "" {
+		return nil, errors.New("member name must not be empty")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	m, ok := g.members[member]
+	if !ok {
+		m = &GroupMember{group: g, name: member}
+		g.members[member] = m
+	}
+	return m, nil
+}
+
+// Members returns the names of the current members ordered by name
+func (g *ConsumerGroup) Members() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	members := make([]string, 0, len(g.members))
+	for name := range g.members {
+		members = append(members, name)
+	}
+	sort.Strings(members)
+
+	return members
+}
+
+// Committed returns the last committed offset or -1 if nothing has been
+// committed
+func (g *ConsumerGroup) Committed() Offset {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.committed
+}
+
+// Seek sets the offset of the next record delivered to the members, e.g. to
+// recover from ErrOutOfRange. Unacknowledged records are not delivered again.
+func (g *ConsumerGroup) Seek(offset Offset) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.next = offset
+	g.pending = make(map[Offset]string)
+	g.redeliver = nil
+}
+
+// Commit saves the highest offset up to which all delivered records have been
+// acknowledged to the checkpoint store. Commit is a no-op if the offset has
+// not changed since the last commit.
+func (g *ConsumerGroup) Commit(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	offset := g.acknowledged()
+	if offset <= g.committed {
+		return nil
+	}
+
+	checkpoint := Checkpoint{
+		LogID:  g.log.ID(),
+		Offset: offset,
+	}
+	if err := g.store.Save(ctx, g.name, checkpoint); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	g.committed = offset
+	return g.log.Commit(ctx, g.name, g.committed)
+}
+
+// Close commits the progress of the group, see Commit()
+func (g *ConsumerGroup) Close(ctx context.Context) error {
+	return g.Commit(ctx)
+}
+
+// acknowledged returns the highest offset up to which all delivered records
+// have been acknowledged. Must be protected with a lock by the caller.
+func (g *ConsumerGroup) acknowledged() Offset {
+	lowest := g.next
+	for offset := range g.pending {
+		if offset < lowest {
+			lowest = offset
+		}
+	}
+
+	if len(g.redeliver) > 0 && g.redeliver[0] < lowest {
+		lowest = g.redeliver[0]
+	}
+	return lowest - 1
+}
+
+// Name returns the name of the member
+func (m *GroupMember) Name() string {
+	return m.name
+}
+
+// Next reads the next record of the group which has not been delivered to
+// another member. Records of members which left the group without
+// acknowledging them are delivered first. Gaps in logs with sparse offsets are
+// skipped. If no new record is available, ErrFutureOffset is returned. If the
+// next record has been purged, ErrOutOfRange is returned and
+// ConsumerGroup.Seek() can be used to recover.
+func (m *GroupMember) Next(ctx context.Context) (Record, error) {
+	g := m.group
+	if err := g.log.Heartbeat(ctx, g.name); err != nil {
+		return Record{}, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.members[m.name] != m {
+		return Record{}, fmt.Errorf("%w: %s", ErrMemberNotFound, m.name)
+	}
+
+	for {
+		redelivery := len(g.redeliver) > 0
+		offset := g.next
+		if redelivery {
+			offset = g.redeliver[0]
+		}
+
+		r, err := g.log.Read(ctx, offset)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrOffsetGap), redelivery && errors.Is(err, ErrOutOfRange):
+			// purged records can not be delivered again
+			g.advance(redelivery)
+			continue
+		default:
+			return Record{}, err
+		}
+
+		g.advance(redelivery)
+		g.pending[offset] = m.name
+		return r, nil
+	}
+}
+
+// advance moves past the offset delivered by Next. Must be protected with a
+// lock by the caller.
+func (g *ConsumerGroup) advance(redelivery bool) {
+	if redelivery {
+		g.redeliver = g.redeliver[1:]
+		return
+	}
+	g.next++
+}
+
+// Ack acknowledges the record with the given offset delivered to the member
+// as processed
+func (m *GroupMember) Ack(offset Offset) error {
+	g := m.group
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.members[m.name] != m {
+		return fmt.Errorf("%w: %s", ErrMemberNotFound, m.name)
+	}
+
+	if owner, ok := g.pending[offset]; !ok || owner != m.name {
+		return fmt.Errorf("offset %d not delivered to member %s", offset, m.name)
+	}
+
+	delete(g.pending, offset)
+	return nil
+}
+
+// Leave removes the member from the group. Unacknowledged records of the
+// member are delivered to the remaining members again.
+func (m *GroupMember) Leave() error {
+	g := m.group
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.members[m.name] != m {
+		return fmt.Errorf("%w: %s", ErrMemberNotFound, m.name)
+	}
+
+	for offset, owner := range g.pending {
+		if owner == m.name {
+			g.redeliver = append(g.redeliver, offset)
+			delete(g.pending, offset)
+		}
+	}
+	sort.Slice(g.redeliver, func(i, j int) bool {
+		return g.redeliver[i] < g.redeliver[j]
+	})
+
+	delete(g.members, m.name)
+	return nil
+}
@@ -0,0 +1,191 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_NewConsumerGroup(t *testing.T) {
+	t.Run("fails with invalid input", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.NewConsumerGroup(ctx, "", NewMemoryCheckpointStore())
+		assert.ErrorContains(t, err, "must not be empty")
+
+		_, err = l.NewConsumerGroup(ctx, "group", nil)
+		assert.ErrorContains(t, err, "must not be nil")
+
+		g, err := l.NewConsumerGroup(ctx, "group", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+
+		_, err = g.Join("")
+		assert.ErrorContains(t, err, "must not be empty")
+	})
+
+	t.Run("fails with checkpoint of another log unless resynced", func(t *testing.T) {
+		ctx := context.Background()
+		store := NewMemoryCheckpointStore()
+		assert.NilError(t, store.Save(ctx, "group", Checkpoint{LogID: "other", Offset: 5}))
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.NewConsumerGroup(ctx, "group", store)
+		assert.Assert(t, errors.Is(err, ErrLogMismatch))
+
+		g, err := l.NewConsumerGroup(ctx, "group", store, WithGroupResyncOnMismatch())
+		assert.NilError(t, err)
+		assert.Equal(t, g.Committed(), Offset(-1))
+	})
+}
+
+func TestConsumerGroup(t *testing.T) {
+	setup := func(t *testing.T, records int) (context.Context, *Log) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, records) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		return ctx, l
+	}
+
+	t.Run("distributes records among members", func(t *testing.T) {
+		ctx, l := setup(t, 4)
+		g, err := l.NewConsumerGroup(ctx, "group", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+
+		a, err := g.Join("a")
+		assert.NilError(t, err)
+		b, err := g.Join("b")
+		assert.NilError(t, err)
+
+		again, err := g.Join("a")
+		assert.NilError(t, err)
+		assert.Equal(t, again, a)
+		assert.DeepEqual(t, g.Members(), []string{"a", "b"})
+
+		var offsets []Offset
+		for _, m := range []*GroupMember{a, b, a, b} {
+			r, err := m.Next(ctx)
+			assert.NilError(t, err)
+			offsets = append(offsets, r.Metadata.Offset)
+		}
+		assert.DeepEqual(t, offsets, []Offset{0, 1, 2, 3})
+
+		_, err = a.Next(ctx)
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+
+		err = a.Ack(1)
+		assert.ErrorContains(t, err, "not delivered to member a")
+	})
+
+	t.Run("commits acknowledged offsets and resumes after restart", func(t *testing.T) {
+		ctx, l := setup(t, 4)
+		store := NewMemoryCheckpointStore()
+		g, err := l.NewConsumerGroup(ctx, "group", store)
+		assert.NilError(t, err)
+
+		a, err := g.Join("a")
+		assert.NilError(t, err)
+		b, err := g.Join("b")
+		assert.NilError(t, err)
+
+		for _, m := range []*GroupMember{a, b, a} {
+			_, err = m.Next(ctx)
+			assert.NilError(t, err)
+		}
+
+		// offset 1 is not acknowledged
+		assert.NilError(t, a.Ack(0))
+		assert.NilError(t, a.Ack(2))
+		assert.NilError(t, g.Commit(ctx))
+		assert.Equal(t, g.Committed(), Offset(0))
+
+		assert.NilError(t, b.Ack(1))
+		assert.NilError(t, g.Close(ctx))
+		assert.Equal(t, g.Committed(), Offset(2))
+		assert.Equal(t, store.Saves(), 2)
+
+		status := l.Consumers(ctx)
+		assert.Equal(t, status[0].Name, "group")
+		assert.Equal(t, status[0].Committed, Offset(2))
+
+		g, err = l.NewConsumerGroup(ctx, "group", store)
+		assert.NilError(t, err)
+		a, err = g.Join("a")
+		assert.NilError(t, err)
+
+		r, err := a.Next(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(3))
+	})
+
+	t.Run("delivers unacknowledged records of leaving members again", func(t *testing.T) {
+		ctx, l := setup(t, 4)
+		g, err := l.NewConsumerGroup(ctx, "group", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+
+		a, err := g.Join("a")
+		assert.NilError(t, err)
+		b, err := g.Join("b")
+		assert.NilError(t, err)
+
+		for _, m := range []*GroupMember{a, a, b} {
+			_, err = m.Next(ctx)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, a.Ack(0))
+		assert.NilError(t, a.Leave())
+
+		err = a.Leave()
+		assert.Assert(t, errors.Is(err, ErrMemberNotFound))
+		_, err = a.Next(ctx)
+		assert.Assert(t, errors.Is(err, ErrMemberNotFound))
+
+		var offsets []Offset
+		for i := 0; i < 2; i++ {
+			r, err := b.Next(ctx)
+			assert.NilError(t, err)
+			offsets = append(offsets, r.Metadata.Offset)
+		}
+		assert.DeepEqual(t, offsets, []Offset{1, 3})
+
+		// rejoining member gets a new handle
+		rejoined, err := g.Join("a")
+		assert.NilError(t, err)
+		assert.Assert(t, rejoined != a)
+	})
+
+	t.Run("recovers from purged records with seek", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		g, err := l.NewConsumerGroup(ctx, "group", NewMemoryCheckpointStore())
+		assert.NilError(t, err)
+		m, err := g.Join("a")
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		_, err = m.Next(ctx)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		earliest, _ := l.Range(ctx)
+		g.Seek(earliest)
+		r, err := m.Next(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, earliest)
+	})
+}
@@ -0,0 +1,41 @@
+package memlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// newLogID returns a random log identity
+func newLogID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// WithID sets the identity of the log instead of a random identity, e.g. when
+// a log is restored from a snapshot of a previous incarnation so that existing
+// checkpoints remain valid
+func WithID(id string) Option {
+	return func(log *Log) error {
+		if id == "" {
+			return errors.New("id must not be empty")
+		}
+
+		log.id = id
+		return nil
+	}
+}
+
+// ID returns the identity of the log. Unless specified with WithID(), every
+// log created with New() has a random identity, i.e. a log recreated after a
+// crash or restart has a different identity. Checkpoints store the identity
+// of their log, so that consumers detect when they resume against another log
+// incarnation (ErrLogMismatch) instead of silently reading wrong records.
+//
+// Safe for concurrent use.
+func (l *Log) ID() string {
+	return l.id
+}
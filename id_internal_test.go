@@ -0,0 +1,75 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_ID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fails with empty id", func(t *testing.T) {
+		_, err := New(ctx, WithID(""))
+		assert.ErrorContains(t, err, "must not be empty")
+	})
+
+	t.Run("generates unique ids", func(t *testing.T) {
+		l1, err := New(ctx)
+		assert.NilError(t, err)
+		l2, err := New(ctx)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(l1.ID()), 32)
+		assert.Assert(t, l1.ID() != l2.ID())
+
+		l3, err := New(ctx, WithID("orders-v1"))
+		assert.NilError(t, err)
+		assert.Equal(t, l3.ID(), "orders-v1")
+	})
+
+	t.Run("detects checkpoint of another log incarnation", func(t *testing.T) {
+		store := NewMemoryCheckpointStore()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		c, err := l.NewConsumer(ctx, "billing", store)
+		assert.NilError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err = c.Next(ctx)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, c.Close(ctx))
+
+		// crash: log recreated with different records
+		l, err = New(ctx, WithStartOffset(100))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, newTestData(t, "new"))
+		assert.NilError(t, err)
+
+		_, err = l.NewConsumer(ctx, "billing", store)
+		assert.Assert(t, errors.Is(err, ErrLogMismatch))
+
+		c, err = l.NewConsumer(ctx, "billing", store, WithResyncOnMismatch())
+		assert.NilError(t, err)
+		assert.Equal(t, c.Committed(), Offset(-1))
+
+		r, err := c.Next(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(100))
+		c.Mark(r.Metadata.Offset)
+		assert.NilError(t, c.Close(ctx))
+
+		checkpoint, err := store.Load(ctx, "billing")
+		assert.NilError(t, err)
+		assert.Equal(t, checkpoint, Checkpoint{LogID: l.ID(), Offset: 100})
+	})
+}
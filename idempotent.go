@@ -0,0 +1,100 @@
+package memlog
+
+import (
+	"errors"
+	"fmt"
+)
+
+// sequenceWindow is the number of recent sequence numbers per producer which
+// are deduplicated
+const sequenceWindow = 16
+
+// ErrOutOfOrderSequence is returned when the sequence number of an idempotent
+// write does not follow the last sequence number of the producer or is too old
+// to be deduplicated, see WithSequence()
+var ErrOutOfOrderSequence = errors.New("out of order sequence")
+
+// WithSequence makes the write idempotent for the given producer, i.e. a
+// retried write with the same producer ID and sequence number is deduplicated
+// and returns the offset of the original record, e.g. to offer exactly-once
+// append semantics in network-facing wrappers. Sequence numbers of a producer
+// must increase by one with every new record, starting at any value. The
+// offsets of the last 16 sequence numbers of a producer are kept for
+// deduplication. Otherwise, the write fails with ErrOutOfOrderSequence.
+//
+// Idempotent writes are supported by Write() and WriteAt(). Batch writes with
+// a sequence number are rejected.
+func WithSequence(producerID string, seq uint64) WriteOption {
+	return func(wc *writeConfig) {
+		wc.sequence = &sequence{producer: producerID, seq: seq}
+	}
+}
+
+// sequence identifies an idempotent write
+type sequence struct {
+	producer string
+	seq      uint64
+}
+
+// producerSequences tracks the recent sequence numbers of an idempotent
+// producer
+type producerSequences struct {
+	last  uint64 // last written sequence number
+	slots [sequenceWindow]sequenceSlot
+}
+
+// sequenceSlot is the offset of a written sequence number
+type sequenceSlot struct {
+	seq     uint64
+	offset  Offset
+	written bool
+}
+
+// duplicate returns the offset of the original record and true if the write
+// with the given configuration is a retry of an idempotent write. An error is
+// returned if the sequence number is invalid. Must be protected with a lock by
+// the caller.
+func (l *Log) duplicate(wc writeConfig) (Offset, bool, error) {
+	s := wc.sequence
+	if s == nil {
+		return -1, false, nil
+	}
+
+	if s.producer == "" {
+		return -1, false, errors.New("producer id must not be empty")
+	}
+
+	p, ok := l.sequences[s.producer]
+	switch {
+	case !ok || s.seq == p.last+1:
+		return -1, false, nil
+	case s.seq <= p.last && p.last-s.seq < sequenceWindow:
+		// sequence numbers before the first sequence were never written
+		if slot := p.slots[s.seq%sequenceWindow]; slot.written && slot.seq == s.seq {
+			return slot.offset, true, nil
+		}
+		fallthrough
+	default:
+		return -1, false, fmt.Errorf("%w: producer %q sequence %d, last sequence %d", ErrOutOfOrderSequence, s.producer, s.seq, p.last)
+	}
+}
+
+// recordSequence records the offset of a written idempotent record. Must be
+// protected with a lock by the caller.
+func (l *Log) recordSequence(s *sequence, offset Offset) {
+	if s == nil {
+		return
+	}
+
+	if l.sequences == nil {
+		l.sequences = make(map[string]*producerSequences)
+	}
+
+	p, ok := l.sequences[s.producer]
+	if !ok {
+		p = &producerSequences{}
+		l.sequences[s.producer] = p
+	}
+	p.last = s.seq
+	p.slots[s.seq%sequenceWindow] = sequenceSlot{seq: s.seq, offset: offset, written: true}
+}
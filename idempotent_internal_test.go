@@ -0,0 +1,115 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_WriteWithSequence(t *testing.T) {
+	t.Run("deduplicates retried writes", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		first, err := l.Write(ctx, []byte("first"), WithSequence("p1", 7))
+		assert.NilError(t, err)
+
+		// other producers do not interfere
+		_, err = l.Write(ctx, []byte("other"), WithSequence("p2", 7))
+		assert.NilError(t, err)
+
+		second, err := l.Write(ctx, []byte("second"), WithSequence("p1", 8))
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, []byte("first"), WithSequence("p1", 7))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, first)
+
+		offset, err = l.Write(ctx, []byte("second"), WithSequence("p1", 8))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, second)
+
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(2))
+	})
+
+	t.Run("rejects out of order sequences", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("data"), WithSequence("", 0))
+		assert.ErrorContains(t, err, "must not be empty")
+
+		for seq := uint64(0); seq < sequenceWindow+1; seq++ {
+			_, err = l.Write(ctx, []byte("data"), WithSequence("p1", seq))
+			assert.NilError(t, err)
+		}
+
+		// gap
+		_, err = l.Write(ctx, []byte("data"), WithSequence("p1", sequenceWindow+2))
+		assert.Assert(t, errors.Is(err, ErrOutOfOrderSequence))
+
+		// outside of deduplication window
+		_, err = l.Write(ctx, []byte("data"), WithSequence("p1", 0))
+		assert.Assert(t, errors.Is(err, ErrOutOfOrderSequence))
+
+		offset, err := l.Write(ctx, []byte("data"), WithSequence("p1", 1))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(1))
+	})
+
+	t.Run("rejects sequences before the first sequence", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = l.Write(ctx, []byte("data"))
+			assert.NilError(t, err)
+		}
+
+		offset, err := l.Write(ctx, []byte("data"), WithSequence("p1", 100))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+
+		_, err = l.Write(ctx, []byte("data"), WithSequence("p1", 95))
+		assert.Assert(t, errors.Is(err, ErrOutOfOrderSequence))
+
+		_, err = l.Write(ctx, []byte("data"), WithSequence("p1", 100-sequenceWindow))
+		assert.Assert(t, errors.Is(err, ErrOutOfOrderSequence))
+
+		offset, err = l.Write(ctx, []byte("data"), WithSequence("p1", 100))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+	})
+
+	t.Run("deduplicates sparse writes", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets())
+		assert.NilError(t, err)
+
+		offset, err := l.WriteAt(ctx, 5, []byte("data"), WithSequence("p1", 0))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+
+		offset, err = l.WriteAt(ctx, 10, []byte("data"), WithSequence("p1", 0))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(5))
+	})
+
+	t.Run("rejects batch writes", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.WriteBatch(ctx, NewTestDataSlice(t, 2), WithSequence("p1", 0))
+		assert.ErrorContains(t, err, "not supported")
+	})
+}
@@ -0,0 +1,420 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RecordSource provides records to import into a log
+type RecordSource interface {
+	// Next returns the next record of the source. io.EOF is returned when the
+	// source has no more records.
+	Next(ctx context.Context) (Record, error)
+}
+
+// SliceSource is a RecordSource returning the records of a slice in order
+type SliceSource struct {
+	records []Record
+}
+
+// NewSliceSource creates a RecordSource for the given records
+func NewSliceSource(records []Record) *SliceSource {
+	return &SliceSource{records: records}
+}
+
+// Next returns the next record of the slice
+func (s *SliceSource) Next(ctx context.Context) (Record, error) {
+	if ctx.Err() != nil {
+		return Record{}, ctx.Err()
+	}
+
+	if len(s.records) == 0 {
+		return Record{}, io.EOF
+	}
+
+	r := s.records[0]
+	s.records = s.records[1:]
+	return r, nil
+}
+
+// LogSource is a RecordSource reading all records retained in a log at the
+// time of the first call to Next, e.g. to migrate a log
+type LogSource struct {
+	log    Reader
+	next   Offset
+	latest Offset
+	init   bool
+}
+
+// NewLogSource creates a RecordSource reading the records of the given log,
+// e.g. a Log or another Reader implementation
+func NewLogSource(l Reader) *LogSource {
+	return &LogSource{log: l}
+}
+
+// Next returns the next record of the log. Gaps of logs with sparse offsets are
+// skipped. If the next record has been purged in the meantime, ErrOutOfRange
+// is returned.
+func (s *LogSource) Next(ctx context.Context) (Record, error) {
+	if !s.init {
+		s.next, s.latest = s.log.Range(ctx)
+		s.init = true
+	}
+
+	for {
+		if s.latest == -1 || s.next > s.latest {
+			return Record{}, io.EOF
+		}
+
+		r, err := s.log.Read(ctx, s.next)
+		s.next++
+		if errors.Is(err, ErrOffsetGap) {
+			continue
+		}
+		return r, err
+	}
+}
+
+// ImportOption customizes ImportFrom
+type ImportOption func(*importConfig) error
+
+type importConfig struct {
+	preserveOffsets bool
+	preserveMeta    bool   // preserve expiry time and writer epoch, e.g. on restore
+	sourceID        string // optional, scopes the offset translation
+	provenance      string // optional, source log id
+
+	progress      func(ImportProgress) // optional
+	progressEvery int
+	pace          time.Duration     // optional, minimum duration between records
+	checkpoint    *importCheckpoint // optional
+}
+
+// ImportProgress describes the progress of an import, see WithImportProgress()
+type ImportProgress struct {
+	// Imported is the number of records imported by the current call to
+	// ImportFrom, excluding records skipped when resuming from a checkpoint
+	Imported int
+	// Skipped is the number of source records skipped when resuming from a
+	// checkpoint
+	Skipped int
+	// SourceOffset is the offset of the last imported source record
+	SourceOffset Offset
+	// Offset is the offset of the last imported record in the log
+	Offset Offset
+	// Done is true for the final progress report of a completed import
+	Done bool
+}
+
+// importCheckpoint tracks the last imported source offset of a resumable
+// import
+type importCheckpoint struct {
+	store CheckpointStore
+	name  string
+	every int
+}
+
+// WithImportProgress calls fn after every n imported records and when the
+// import completes, e.g. to report the progress of a large backfill. fn is
+// called synchronously, i.e. a slow fn slows down the import.
+func WithImportProgress(fn func(ImportProgress), n int) ImportOption {
+	return func(ic *importConfig) error {
+		if fn == nil {
+			return errors.New("progress function must not be nil")
+		}
+
+		if n <= 0 {
+			return errors.New("progress interval must be greater than 0")
+		}
+
+		ic.progress = fn
+		ic.progressEvery = n
+		return nil
+	}
+}
+
+// WithImportRateLimit limits the import to the given number of records per
+// second, so that backfills into a live log do not starve regular writers.
+// Durations are measured with the clock of the log.
+func WithImportRateLimit(recordsPerSec float64) ImportOption {
+	return func(ic *importConfig) error {
+		if recordsPerSec <= 0 {
+			return errors.New("rate limit must be greater than 0")
+		}
+
+		ic.pace = time.Duration(float64(time.Second) / recordsPerSec)
+		return nil
+	}
+}
+
+// WithImportCheckpoint makes the import resumable. The offset of the last
+// imported source record is saved in the store under the given name every n
+// records and when the import stops. When a checkpoint exists, source records
+// up to and including the checkpoint offset are skipped, e.g. to retry a
+// failed or cancelled import. If the checkpoint was created by an import into
+// another log incarnation (see Log.ID()), the import fails with
+// ErrLogMismatch.
+func WithImportCheckpoint(store CheckpointStore, name string, n int) ImportOption {
+	return func(ic *importConfig) error {
+		if store == nil {
+			return errors.New("checkpoint store must not be nil")
+		}
+
+		if name == "" {
+			return errors.New("checkpoint name must not be empty")
+		}
+
+		if n <= 0 {
+			return errors.New("checkpoint interval must be greater than 0")
+		}
+
+		ic.checkpoint = &importCheckpoint{store: store, name: name, every: n}
+		return nil
+	}
+}
+
+// WithPreserveOffsets imports records at their original offsets, so that
+// consumer checkpoints created against the source remain valid. A log without
+// any written record is rebased to the offset of the first imported record.
+// Otherwise, records with an offset lower than the next offset of the log are
+// rejected with ErrOutOfRange, also if all records have been purged, i.e.
+// offsets are never reused. Gaps between source offsets require a log created
+// with WithSparseOffsets().
+func WithPreserveOffsets() ImportOption {
+	return func(ic *importConfig) error {
+		ic.preserveOffsets = true
+		return nil
+	}
+}
+
+// withPreservedMetadata imports records with their expiry time and writer
+// epoch, e.g. to restore a snapshot of the log
+func withPreservedMetadata() ImportOption {
+	return func(ic *importConfig) error {
+		ic.preserveMeta = true
+		return nil
+	}
+}
+
+// WithSourceID records the offset translation of an import without
+// WithPreserveOffsets() under the given source id, e.g. the identity of the
+// source log (see Log.ID()), so that the translations of imports from several
+// sources are retained, see TranslateFrom(). Imports without source id share
+// a translation, i.e. an import from another source replaces the translation
+// of an earlier import.
+func WithSourceID(id string) ImportOption {
+	return func(ic *importConfig) error {
+		if id == "" {
+			return errors.New("source id must not be empty")
+		}
+
+		ic.sourceID = id
+		return nil
+	}
+}
+
+// ImportFrom writes all records of the source to the log, preserving their
+// key, headers and creation time. Unless WithPreserveOffsets() is specified,
+// records are appended at the next offsets of the log and the mapping from
+// source to log offsets is recorded, see Translate() and OffsetTranslation().
+//
+// Records are written one by one, i.e. concurrent writes are interleaved with
+// imported records. When offsets are preserved, concurrent writes can cause
+// the import to fail. Large imports into a live log can be throttled with
+// WithImportRateLimit(), observed with WithImportProgress() and resumed with
+// WithImportCheckpoint().
+//
+// Safe for concurrent use.
+func (l *Log) ImportFrom(ctx context.Context, source RecordSource, options ...ImportOption) error {
+	if source == nil {
+		return errors.New("source must not be nil")
+	}
+
+	var ic importConfig
+	for _, opt := range options {
+		if err := opt(&ic); err != nil {
+			return fmt.Errorf("configure import option: %v", err)
+		}
+	}
+
+	resume := Offset(-1)
+	if ic.checkpoint != nil {
+		checkpoint, err := ic.checkpoint.store.Load(ctx, ic.checkpoint.name)
+		switch {
+		case errors.Is(err, ErrCheckpointNotFound):
+		case err != nil:
+			return fmt.Errorf("load import checkpoint: %w", err)
+		default:
+			if err = checkpoint.verify(l); err != nil {
+				return err
+			}
+			resume = checkpoint.Offset
+		}
+	}
+
+	var (
+		progress = ImportProgress{SourceOffset: -1, Offset: -1}
+		saved    = progress.SourceOffset // last checkpointed source offset
+		last     time.Time               // rate limit
+	)
+
+	// save persists the import checkpoint if the import made progress
+	save := func(ctx context.Context) error {
+		if ic.checkpoint == nil || progress.SourceOffset == saved {
+			return nil
+		}
+
+		checkpoint := Checkpoint{LogID: l.ID(), Offset: progress.SourceOffset}
+		if err := ic.checkpoint.store.Save(ctx, ic.checkpoint.name, checkpoint); err != nil {
+			return fmt.Errorf("save import checkpoint: %w", err)
+		}
+		saved = progress.SourceOffset
+		return nil
+	}
+
+	// stop persists the import checkpoint before returning the given error,
+	// also when the import is stopped due to a cancelled context
+	stop := func(err error) error {
+		if saveErr := save(context.Background()); saveErr != nil && err == nil {
+			return saveErr
+		}
+		return err
+	}
+
+	for {
+		r, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			if err = stop(nil); err != nil {
+				return err
+			}
+
+			if ic.progress != nil {
+				progress.Done = true
+				ic.progress(progress)
+			}
+			return nil
+		}
+		if err != nil {
+			return stop(fmt.Errorf("read from source: %w", err))
+		}
+
+		if r.Metadata.Offset <= resume {
+			progress.Skipped++
+			continue
+		}
+
+		if ic.pace > 0 && !last.IsZero() {
+			if err = sleep(ctx, l.clock, ic.pace-l.clock.Since(last)); err != nil {
+				return stop(err)
+			}
+		}
+
+		offset, err := l.importRecord(ctx, r, ic, progress.Imported == 0)
+		if err != nil {
+			return stop(fmt.Errorf("import record with offset %d: %w", r.Metadata.Offset, err))
+		}
+		last = l.clock.Now()
+
+		progress.Imported++
+		progress.SourceOffset = r.Metadata.Offset
+		progress.Offset = offset
+
+		if ic.checkpoint != nil && progress.Imported%ic.checkpoint.every == 0 {
+			if err = save(ctx); err != nil {
+				return err
+			}
+		}
+
+		if ic.progress != nil && progress.Imported%ic.progressEvery == 0 {
+			ic.progress(progress)
+		}
+	}
+}
+
+// sleep waits for the given duration on the given clock unless the context is
+// done first
+func sleep(ctx context.Context, c TimeSource, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := c.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.Chan():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// importRecord writes the source record to the log. first is true for the first
+// record written by an import.
+func (l *Log) importRecord(ctx context.Context, r Record, ic importConfig, first bool) (Offset, error) {
+	opts := []WriteOption{
+		WithKey(r.Metadata.Key),
+		WithHeaders(r.Metadata.Headers),
+		withCreated(r.Metadata.Created),
+	}
+
+	if ic.preserveMeta {
+		opts = append(opts, withExpires(r.Metadata.Expires), withWrittenEpoch(r.Metadata.Epoch))
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return -1, err
+	}
+	defer l.mu.Unlock()
+
+	if ic.provenance != "" {
+		p, err := appendProvenance(r, ProvenanceEntry{
+			LogID:  ic.provenance,
+			Offset: r.Metadata.Offset,
+			Time:   l.clock.Now().UTC(),
+		})
+		if err != nil {
+			return -1, err
+		}
+		opts = append(opts, WithHeader(HeaderProvenance, p))
+	}
+
+	if !ic.preserveOffsets {
+		if !first && r.Metadata.Offset < l.translations[ic.sourceID].end() {
+			return -1, fmt.Errorf("record offset translation: source offset %d not increasing", r.Metadata.Offset)
+		}
+
+		offset, err := l.write(ctx, r.Data, opts...)
+		if err != nil {
+			return -1, err
+		}
+
+		l.translate(ic.sourceID, r.Metadata.Offset, offset, first)
+		return offset, nil
+	}
+
+	offset := r.Metadata.Offset
+	if offset < 0 {
+		return -1, fmt.Errorf("%w: invalid offset %d", ErrOutOfRange, offset)
+	}
+
+	switch {
+	case offset == l.offset:
+		return l.write(ctx, r.Data, opts...)
+	case l.unwritten():
+		if err := l.rebase(offset); err != nil {
+			return -1, err
+		}
+		return l.write(ctx, r.Data, opts...)
+	case offset < l.offset:
+		// includes logs emptied by purges, offsets must not be reused
+		return -1, fmt.Errorf("%w: offset %d lower than next offset %d", ErrOutOfRange, offset, l.offset)
+	case l.conf.sparse:
+		return l.writeAt(ctx, offset, r.Data, opts...)
+	default:
+		return -1, fmt.Errorf("%w: source offsets have a gap at %d, sparse offsets required", ErrOffsetGap, l.offset)
+	}
+}
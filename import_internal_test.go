@@ -0,0 +1,255 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func newTestRecords(t *testing.T, offsets ...Offset) []Record {
+	t.Helper()
+
+	created := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	records := make([]Record, len(offsets))
+	for i, o := range offsets {
+		records[i] = Record{
+			Metadata: Header{
+				Offset:  o,
+				Created: created.Add(time.Duration(i) * time.Second),
+				Key:     []byte{byte(i)},
+			},
+			Data: newTestData(t, strconv.Itoa(int(o))),
+		}
+	}
+	return records
+}
+
+func TestLog_ImportFrom(t *testing.T) {
+	t.Run("preserves offsets with non-zero start and gaps", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets(), WithMaxSegmentSize(10))
+		assert.NilError(t, err)
+
+		source := newTestRecords(t, 100, 101, 105, 130)
+		err = l.ImportFrom(ctx, NewSliceSource(source), WithPreserveOffsets())
+		assert.NilError(t, err)
+
+		for _, want := range source {
+			got, readErr := l.Read(ctx, want.Metadata.Offset)
+			assert.NilError(t, readErr)
+			assert.DeepEqual(t, got, want)
+		}
+
+		_, err = l.Read(ctx, 102)
+		assert.Assert(t, errors.Is(err, ErrOffsetGap))
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(100))
+		assert.Equal(t, latest, Offset(130))
+	})
+
+	t.Run("preserves offsets below configured start offset of empty log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(100))
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 10, 11, 12)), WithPreserveOffsets())
+		assert.NilError(t, err)
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(10))
+		assert.Equal(t, latest, Offset(12))
+
+		_, err = l.Read(ctx, 10)
+		assert.NilError(t, err)
+	})
+
+	t.Run("does not reuse offsets of purged log", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d, WithTTL(time.Second))
+			assert.NilError(t, err)
+		}
+
+		mockClock.Add(time.Second)
+		n, err := l.PurgeExpired(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 5)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 1)), WithPreserveOffsets())
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 5)), WithPreserveOffsets())
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, newTestData(t, "next"))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(6))
+	})
+
+	t.Run("preserving gaps requires sparse offsets", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 5, 6, 8)), WithPreserveOffsets())
+		assert.Assert(t, errors.Is(err, ErrOffsetGap))
+	})
+
+	t.Run("appends records without preserving offsets", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithClock(clock.NewMock()))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "existing"))
+		assert.NilError(t, err)
+
+		source := newTestRecords(t, 100, 105)
+		err = l.ImportFrom(ctx, NewSliceSource(source))
+		assert.NilError(t, err)
+
+		for i, want := range source {
+			got, readErr := l.Read(ctx, Offset(i+1))
+			assert.NilError(t, readErr)
+			assert.DeepEqual(t, got.Data, want.Data)
+			assert.DeepEqual(t, got.Metadata.Key, want.Metadata.Key)
+			assert.Equal(t, got.Metadata.Created, want.Metadata.Created)
+		}
+	})
+
+	t.Run("migrates log with preserved offsets", func(t *testing.T) {
+		ctx := context.Background()
+		src, err := New(ctx, WithStartOffset(10), WithMaxSegmentSize(5))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 12) {
+			_, err = src.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		dst, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = dst.ImportFrom(ctx, NewLogSource(src), WithPreserveOffsets())
+		assert.NilError(t, err)
+
+		srcEarliest, srcLatest := src.Range(ctx)
+		dstEarliest, dstLatest := dst.Range(ctx)
+		assert.Equal(t, dstEarliest, srcEarliest)
+		assert.Equal(t, dstLatest, srcLatest)
+
+		for o := srcEarliest; o <= srcLatest; o++ {
+			want, readErr := src.Read(ctx, o)
+			assert.NilError(t, readErr)
+			got, readErr := dst.Read(ctx, o)
+			assert.NilError(t, readErr)
+			assert.DeepEqual(t, got, want)
+		}
+	})
+}
+
+func TestLog_ImportFrom_Progress(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	var reports []ImportProgress
+	source := NewSliceSource(newTestRecords(t, 10, 11, 12, 13, 14))
+	err = l.ImportFrom(ctx, source, WithImportProgress(func(p ImportProgress) {
+		reports = append(reports, p)
+	}, 2))
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, reports, []ImportProgress{
+		{Imported: 2, SourceOffset: 11, Offset: 1},
+		{Imported: 4, SourceOffset: 13, Offset: 3},
+		{Imported: 5, SourceOffset: 14, Offset: 4, Done: true},
+	})
+}
+
+func TestLog_ImportFrom_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	store := NewMemoryCheckpointStore()
+	records := newTestRecords(t, 10, 11, 12, 13, 14)
+
+	// fails after the third record
+	failing := NewSliceSource(append(records[:3:3], Record{Metadata: Header{Offset: 13}}))
+	err = l.ImportFrom(ctx, failing, WithImportCheckpoint(store, "backfill", 10))
+	assert.ErrorContains(t, err, "import record with offset 13")
+
+	checkpoint, err := store.Load(ctx, "backfill")
+	assert.NilError(t, err)
+	assert.Equal(t, checkpoint, Checkpoint{LogID: l.ID(), Offset: 12})
+
+	var final ImportProgress
+	err = l.ImportFrom(ctx, NewSliceSource(records),
+		WithImportCheckpoint(store, "backfill", 10),
+		WithImportProgress(func(p ImportProgress) { final = p }, 10),
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, final, ImportProgress{Imported: 2, Skipped: 3, SourceOffset: 14, Offset: 4, Done: true})
+
+	_, latest := l.Range(ctx)
+	assert.Equal(t, latest, Offset(4))
+
+	t.Run("fails with checkpoint of another log", func(t *testing.T) {
+		other, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = other.ImportFrom(ctx, NewSliceSource(records), WithImportCheckpoint(store, "backfill", 10))
+		assert.Assert(t, errors.Is(err, ErrLogMismatch))
+	})
+}
+
+func TestLog_ImportFrom_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clock.NewMock()
+	l, err := New(ctx, WithClock(mockClock))
+	assert.NilError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 0, 1, 2)), WithImportRateLimit(10))
+	}()
+
+	for imported := 1; imported <= 3; imported++ {
+		waitForOffset(t, l, Offset(imported-1))
+		mockClock.Add(100 * time.Millisecond)
+	}
+	assert.NilError(t, <-done)
+
+	t.Run("cancelled while throttled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		go func() {
+			waitForOffset(t, l, 3)
+			cancel()
+		}()
+
+		err := l.ImportFrom(cctx, NewSliceSource(newTestRecords(t, 3, 4)), WithImportRateLimit(10))
+		assert.Assert(t, errors.Is(err, context.Canceled))
+	})
+}
+
+// waitForOffset waits until the given offset has been written to the log
+func waitForOffset(t *testing.T, l *Log, offset Offset) {
+	t.Helper()
+
+	for {
+		if _, latest := l.Range(context.Background()); latest >= offset {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
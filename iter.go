@@ -0,0 +1,160 @@
+//go:build go1.23
+
+package memlog
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// iterBatchSize is the number of records read under a single lock acquisition
+// by an iterator
+const iterBatchSize = 64
+
+// IterOption customizes an iterator created with Records()
+type IterOption func(*iterConfig)
+
+type iterConfig struct {
+	follow        bool
+	streamOptions []StreamOption
+}
+
+// WithIterFollow tails the log after the latest offset, i.e. the iterator
+// yields new records until the context is cancelled or the loop is exited.
+// The records are streamed with Stream() using the given stream options.
+func WithIterFollow(options ...StreamOption) IterOption {
+	return func(ic *iterConfig) {
+		ic.follow = true
+		ic.streamOptions = options
+	}
+}
+
+// Records returns an iterator over the records of the log starting at the
+// given offset for use with range-over-func, e.g.:
+//
+//	for r, err := range l.Records(ctx, start) {
+//		if err != nil {
+//			return err
+//		}
+//		// process r
+//	}
+//
+// By default, the iterator stops after the latest offset of the log. Use
+// WithIterFollow() to tail the log instead. Gaps of logs with sparse offsets
+// are skipped. If an error occurs, e.g. because the start offset has been
+// purged (ErrOutOfRange) or the context is cancelled, the error is yielded
+// and the iteration stops.
+//
+// Safe for concurrent use.
+func (l *Log) Records(ctx context.Context, start Offset, options ...IterOption) iter.Seq2[Record, error] {
+	var ic iterConfig
+	for _, opt := range options {
+		opt(&ic)
+	}
+
+	if ic.follow {
+		return l.followRecords(ctx, start, ic.streamOptions)
+	}
+
+	return func(yield func(Record, error) bool) {
+		offset := start
+		for {
+			records, err := l.ReadBatch(ctx, offset, iterBatchSize)
+			if errors.Is(err, ErrFutureOffset) {
+				// end of log
+				return
+			}
+
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			if len(records) == 0 {
+				// only gaps until end of log
+				return
+			}
+
+			for _, r := range records {
+				if !yield(r, nil) {
+					return
+				}
+			}
+			offset = records[len(records)-1].Metadata.Offset + 1
+		}
+	}
+}
+
+// Backward returns an iterator over the records of the log starting at the
+// given offset towards the earliest offset for use with range-over-func, i.e.
+// records are yielded ordered by descending offset. The iterator stops after
+// the earliest retained record, also if older records are purged during the
+// iteration. Gaps of logs with sparse offsets are skipped. If an error occurs,
+// e.g. because the start offset is not readable, the error is yielded and the
+// iteration stops.
+//
+// Safe for concurrent use.
+func (l *Log) Backward(ctx context.Context, start Offset) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		offset := start
+		for first := true; ; first = false {
+			records, err := l.ReadBackward(ctx, offset, iterBatchSize)
+			if !first && errors.Is(err, ErrOutOfRange) {
+				// older records purged
+				return
+			}
+
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			for _, r := range records {
+				if !yield(r, nil) {
+					return
+				}
+			}
+
+			if len(records) < iterBatchSize {
+				// earliest record reached
+				return
+			}
+			offset = records[len(records)-1].Metadata.Offset - 1
+		}
+	}
+}
+
+// followRecords returns an iterator over a stream starting at the given offset
+func (l *Log) followRecords(ctx context.Context, start Offset, options []StreamOption) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		stream, errs := l.Stream(ctx, start, options...)
+		defer func() {
+			cancel()
+			// the stream terminates with the cancelled context
+			for range errs {
+			}
+		}()
+
+		for {
+			select {
+			case r := <-stream:
+				if !yield(r.Record, nil) {
+					return
+				}
+
+			case err := <-errs:
+				// deliver records buffered before the stream terminated
+				for r := range stream {
+					if !yield(r.Record, nil) {
+						return
+					}
+				}
+
+				yield(Record{}, err)
+				return
+			}
+		}
+	}
+}
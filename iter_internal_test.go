@@ -0,0 +1,192 @@
+//go:build go1.23
+
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Records(t *testing.T) {
+	t.Run("iterates until latest offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10), WithMaxSegmentSize(iterBatchSize))
+		assert.NilError(t, err)
+
+		for i := 0; i < iterBatchSize+10; i++ {
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		want := Offset(12)
+		for r, err := range l.Records(ctx, 12) {
+			assert.NilError(t, err)
+			assert.Equal(t, r.Metadata.Offset, want)
+			want++
+		}
+		assert.Equal(t, want, Offset(10+iterBatchSize+10))
+	})
+
+	t.Run("stops on empty log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for range l.Records(ctx, 0) {
+			t.Fatal("unexpected record")
+		}
+	})
+
+	t.Run("stops on break", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		count := 0
+		for _, err := range l.Records(ctx, 0) {
+			assert.NilError(t, err)
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		assert.Equal(t, count, 2)
+	})
+
+	t.Run("skips gaps", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets())
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{1, 5} {
+			_, err = l.WriteAt(ctx, offset, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		var offsets []Offset
+		for r, err := range l.Records(ctx, 1) {
+			assert.NilError(t, err)
+			offsets = append(offsets, r.Metadata.Offset)
+		}
+		assert.DeepEqual(t, offsets, []Offset{1, 5})
+	})
+
+	t.Run("yields error for purged offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		var errs []error
+		for _, err := range l.Records(ctx, 0) {
+			errs = append(errs, err)
+		}
+		assert.Equal(t, len(errs), 1)
+		assert.Assert(t, errors.Is(errs[0], ErrOutOfRange))
+	})
+
+	t.Run("follows new writes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		want := Offset(0)
+		for r, err := range l.Records(ctx, 0, WithIterFollow()) {
+			assert.NilError(t, err)
+			assert.Equal(t, r.Metadata.Offset, want)
+			want++
+
+			if want == 3 {
+				break
+			}
+
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		// stream subscription released after break
+		assert.Equal(t, len(l.Stats(ctx).Subscriptions), 0)
+	})
+
+	t.Run("follow yields context error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+		cancel()
+
+		var errs []error
+		for _, err := range l.Records(ctx, 0, WithIterFollow()) {
+			errs = append(errs, err)
+		}
+		assert.Equal(t, len(errs), 1)
+		assert.Assert(t, errors.Is(errs[0], context.Canceled))
+	})
+}
+
+func TestLog_Backward(t *testing.T) {
+	t.Run("iterates until earliest offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10), WithMaxSegmentSize(iterBatchSize))
+		assert.NilError(t, err)
+
+		for i := 0; i < iterBatchSize+10; i++ {
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		want := Offset(10 + iterBatchSize + 9)
+		for r, err := range l.Backward(ctx, want) {
+			assert.NilError(t, err)
+			assert.Equal(t, r.Metadata.Offset, want)
+			want--
+		}
+		assert.Equal(t, want, Offset(9))
+	})
+
+	t.Run("stops at earliest offset of full batch", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for i := 0; i < iterBatchSize; i++ {
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		count := 0
+		for _, err := range l.Backward(ctx, iterBatchSize-1) {
+			assert.NilError(t, err)
+			count++
+		}
+		assert.Equal(t, count, iterBatchSize)
+	})
+
+	t.Run("yields error for invalid start", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		var errs []error
+		for _, err := range l.Backward(ctx, 0) {
+			errs = append(errs, err)
+		}
+		assert.Equal(t, len(errs), 1)
+		assert.Assert(t, errors.Is(errs[0], ErrEmptyLog))
+	})
+}
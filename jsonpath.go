@@ -0,0 +1,252 @@
+package memlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a compiled path expression selecting values of JSON documents,
+// e.g. "$.order.items[0].sku" or "order.items[*].sku". Supported are object
+// keys (".key" or "['key']"), array indexes ("[0]", negative indexes count
+// from the end) and wildcards (".*" or "[*]") selecting all object or array
+// values. The leading "$" is optional.
+type JSONPath struct {
+	expr     string
+	steps    []pathStep
+	wildcard bool
+}
+
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// ParseJSONPath compiles a JSONPath expression
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	p := JSONPath{expr: expr}
+
+	rest := strings.TrimPrefix(expr, "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		// allow omitting the leading dot, e.g. "order.id"
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		var (
+			step pathStep
+			err  error
+		)
+
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("parse json path %q: empty key", expr)
+			}
+
+			step = pathStep{key: key, wildcard: key == "*"}
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("parse json path %q: missing ']'", expr)
+			}
+
+			sel := rest[1:end]
+			rest = rest[end+1:]
+			if step, err = parseBracket(sel); err != nil {
+				return nil, fmt.Errorf("parse json path %q: %w", expr, err)
+			}
+
+		default:
+			return nil, fmt.Errorf("parse json path %q: unexpected character %q", expr, rest[0])
+		}
+
+		if step.wildcard {
+			p.wildcard = true
+		}
+		p.steps = append(p.steps, step)
+	}
+
+	if len(p.steps) == 0 {
+		return nil, fmt.Errorf("parse json path %q: no field selected", expr)
+	}
+
+	return &p, nil
+}
+
+func parseBracket(sel string) (pathStep, error) {
+	switch {
+	case sel == "*":
+		return pathStep{wildcard: true}, nil
+
+	case len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0]:
+		return pathStep{key: sel[1 : len(sel)-1]}, nil
+
+	default:
+		i, err := strconv.Atoi(sel)
+		if err != nil {
+			return pathStep{}, fmt.Errorf("invalid selector %q", sel)
+		}
+		return pathStep{index: i, isIndex: true}, nil
+	}
+}
+
+// String returns the expression of the path
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Eval returns the value selected by the path from the given JSON document.
+// If the path contains wildcards, a slice of all selected values is returned.
+// If the document is not valid JSON or does not contain the selected value,
+// false is returned.
+func (p *JSONPath) Eval(data []byte) (interface{}, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+
+	return p.eval(doc)
+}
+
+func (p *JSONPath) eval(doc interface{}) (interface{}, bool) {
+	nodes := []interface{}{doc}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, n := range nodes {
+			next = append(next, step.selectFrom(n)...)
+		}
+		nodes = next
+	}
+
+	if p.wildcard {
+		if nodes == nil {
+			nodes = []interface{}{}
+		}
+		return nodes, true
+	}
+
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+// selectFrom returns the values selected by the step from a decoded JSON node
+func (s pathStep) selectFrom(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			values := make([]interface{}, 0, len(n))
+			for _, k := range keys {
+				values = append(values, n[k])
+			}
+			return values
+		}
+
+		if s.isIndex {
+			return nil
+		}
+
+		if v, ok := n[s.key]; ok {
+			return []interface{}{v}
+		}
+
+	case []interface{}:
+		if s.wildcard {
+			return n
+		}
+
+		if !s.isIndex {
+			return nil
+		}
+
+		i := s.index
+		if i < 0 {
+			i += len(n)
+		}
+		if i >= 0 && i < len(n) {
+			return []interface{}{n[i]}
+		}
+	}
+
+	return nil
+}
+
+// ProjectedRecord is a record with the values selected by JSONPath
+// expressions instead of its data, see Project()
+type ProjectedRecord struct {
+	Metadata Header `json:"metadata"`
+	// Fields maps expressions to their selected values. Expressions without a
+	// selected value are omitted.
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// Project evaluates the given JSONPath expressions (see ParseJSONPath()) over
+// the JSON data of records and returns the selected values instead of the full
+// data, e.g. to reduce transfer and decode cost. Records without valid JSON
+// data are skipped. The records can be bounded with the options of Search().
+//
+// Safe for concurrent use.
+func (l *Log) Project(ctx context.Context, exprs []string, options ...SearchOption) ([]ProjectedRecord, error) {
+	if len(exprs) == 0 {
+		return nil, errors.New("no expressions provided")
+	}
+
+	paths := make([]*JSONPath, 0, len(exprs))
+	for _, e := range exprs {
+		p, err := ParseJSONPath(e)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+
+	projected := []ProjectedRecord{}
+	matcher := MatcherFunc(func(r Record) (bool, error) {
+		var doc interface{}
+		if err := json.Unmarshal(r.Data, &doc); err != nil {
+			return false, nil
+		}
+
+		fields := make(map[string]interface{}, len(paths))
+		for _, p := range paths {
+			if v, ok := p.eval(doc); ok {
+				fields[p.String()] = v
+			}
+		}
+
+		projected = append(projected, ProjectedRecord{
+			Metadata: r.Metadata,
+			Fields:   fields,
+		})
+		return true, nil
+	})
+
+	if _, err := l.Search(ctx, matcher, options...); err != nil {
+		return nil, err
+	}
+
+	return projected, nil
+}
@@ -0,0 +1,103 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	doc := []byte(`{"order":{"id":"order-1","items":[{"sku":"a","qty":1},{"sku":"b","qty":2}],"tags":{"x":1,"y":2}},"odd.key":true}`)
+
+	testCases := []struct {
+		name    string
+		expr    string
+		want    interface{}
+		wantOK  bool
+		wantErr string
+	}{
+		{name: "fails with empty path", expr: "$", wantErr: "no field selected"},
+		{name: "fails with empty key", expr: "$.order..id", wantErr: "empty key"},
+		{name: "fails with unterminated bracket", expr: "$.order[0", wantErr: "missing ']'"},
+		{name: "fails with invalid selector", expr: "$.order[x]", wantErr: "invalid selector"},
+		{name: "key", expr: "$.order.id", want: "order-1", wantOK: true},
+		{name: "key without leading dollar", expr: "order.id", want: "order-1", wantOK: true},
+		{name: "quoted key", expr: "$['odd.key']", want: true, wantOK: true},
+		{name: "array index", expr: "$.order.items[1].sku", want: "b", wantOK: true},
+		{name: "negative array index", expr: "$.order.items[-1].qty", want: float64(2), wantOK: true},
+		{name: "array wildcard", expr: "$.order.items[*].sku", want: []interface{}{"a", "b"}, wantOK: true},
+		{name: "object wildcard", expr: "$.order.tags.*", want: []interface{}{float64(1), float64(2)}, wantOK: true},
+		{name: "wildcard without match", expr: "$.order.id[*]", want: []interface{}{}, wantOK: true},
+		{name: "missing key", expr: "$.order.missing", wantOK: false},
+		{name: "index out of range", expr: "$.order.items[5]", wantOK: false},
+		{name: "index on object", expr: "$.order[0]", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseJSONPath(tc.expr)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, p.String(), tc.expr)
+
+			got, ok := p.Eval(doc)
+			assert.Equal(t, ok, tc.wantOK)
+			assert.DeepEqual(t, got, tc.want)
+		})
+	}
+
+	t.Run("invalid json document", func(t *testing.T) {
+		p, err := ParseJSONPath("$.id")
+		assert.NilError(t, err)
+
+		_, ok := p.Eval([]byte("not json"))
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestLog_Project(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	for _, d := range []string{
+		`{"id":"order-1","customer":{"name":"alice"},"total":10}`,
+		`not json`,
+		`{"id":"order-2","total":20}`,
+	} {
+		_, err = l.Write(ctx, []byte(d))
+		assert.NilError(t, err)
+	}
+
+	t.Run("fails with invalid input", func(t *testing.T) {
+		_, err := l.Project(ctx, nil)
+		assert.ErrorContains(t, err, "no expressions")
+
+		_, err = l.Project(ctx, []string{"$.id["})
+		assert.ErrorContains(t, err, "missing ']'")
+	})
+
+	t.Run("projects selected fields", func(t *testing.T) {
+		got, err := l.Project(ctx, []string{"$.id", "$.customer.name"})
+		assert.NilError(t, err)
+		assert.Equal(t, len(got), 2)
+
+		assert.Equal(t, got[0].Metadata.Offset, Offset(0))
+		assert.DeepEqual(t, got[0].Fields, map[string]interface{}{"$.id": "order-1", "$.customer.name": "alice"})
+
+		assert.Equal(t, got[1].Metadata.Offset, Offset(2))
+		assert.DeepEqual(t, got[1].Fields, map[string]interface{}{"$.id": "order-2"})
+	})
+
+	t.Run("projects records within search window", func(t *testing.T) {
+		got, err := l.Project(ctx, []string{"total"}, WithSearchOffsets(1, 2))
+		assert.NilError(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.DeepEqual(t, got[0].Fields, map[string]interface{}{"total": float64(20)})
+	})
+}
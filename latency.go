@@ -0,0 +1,166 @@
+package memlog
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// latencySubBits is the number of bits of linear sub-buckets per power of
+	// two, i.e. recorded latencies have a relative error below 1/16
+	latencySubBits    = 4
+	latencySubBuckets = 1 << latencySubBits
+	latencyBuckets    = (64 - latencySubBits + 1) * latencySubBuckets
+)
+
+// Percentiles are latency percentiles of the operations recorded since the log
+// was created. Percentiles are upper bounds with a relative error below 1/16.
+type Percentiles struct {
+	Count uint64
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// Latencies are the latency percentiles of log operations, see Latencies()
+type Latencies struct {
+	// Write is the latency of Write(), WriteAt() and WriteBatch() calls
+	// including waiting for the log lock
+	Write Percentiles
+	// Read is the latency of Read() calls including waiting for the log lock
+	Read Percentiles
+	// Stream is the delivery latency of streams, i.e. the duration between
+	// the creation of a record and its delivery to the stream channel
+	Stream Percentiles
+}
+
+// WithLatencyTracking records the latency of writes, reads and stream
+// deliveries in HDR-style histograms with constant memory usage, see
+// Latencies(). Durations are measured with the clock of the log.
+func WithLatencyTracking() Option {
+	return func(log *Log) error {
+		log.latency = &latencyTracker{}
+		return nil
+	}
+}
+
+// Latencies returns the p50, p95 and p99 latencies of writes, reads and stream
+// deliveries since the log was created. Without WithLatencyTracking(), all
+// percentiles are zero.
+//
+// Safe for concurrent use.
+func (l *Log) Latencies() Latencies {
+	if l.latency == nil {
+		return Latencies{}
+	}
+
+	return Latencies{
+		Write:  l.latency.write.percentiles(),
+		Read:   l.latency.read.percentiles(),
+		Stream: l.latency.stream.percentiles(),
+	}
+}
+
+// latencyTracker holds the latency histograms of a log
+type latencyTracker struct {
+	write  latencyHistogram
+	read   latencyHistogram
+	stream latencyHistogram
+}
+
+// latencyHistogram is a log-linear histogram of durations in nanoseconds, i.e.
+// every power of two is divided into linear sub-buckets. Safe for concurrent
+// use.
+type latencyHistogram struct {
+	counts [latencyBuckets]uint64
+	max    int64
+}
+
+// record adds the given duration to the histogram. Negative durations, e.g.
+// due to clock adjustments, are recorded as zero.
+func (h *latencyHistogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	atomic.AddUint64(&h.counts[latencyBucket(uint64(d))], 1)
+	for {
+		max := atomic.LoadInt64(&h.max)
+		if int64(d) <= max || atomic.CompareAndSwapInt64(&h.max, max, int64(d)) {
+			return
+		}
+	}
+}
+
+// since records the duration since the given start time measured with the
+// given clock
+func (h *latencyHistogram) since(c TimeSource, start time.Time) {
+	h.record(c.Since(start))
+}
+
+// percentiles returns a snapshot of the percentiles of the histogram
+func (h *latencyHistogram) percentiles() Percentiles {
+	var (
+		counts [latencyBuckets]uint64
+		total  uint64
+	)
+	for i := range h.counts {
+		counts[i] = atomic.LoadUint64(&h.counts[i])
+		total += counts[i]
+	}
+
+	if total == 0 {
+		return Percentiles{}
+	}
+
+	max := time.Duration(atomic.LoadInt64(&h.max))
+	quantile := func(q float64) time.Duration {
+		target := uint64(math.Ceil(q * float64(total)))
+		var seen uint64
+		for i, n := range counts {
+			seen += n
+			if seen >= target {
+				// bucket upper bound overestimates the maximum
+				if d := time.Duration(latencyBucketMax(i)); d < max {
+					return d
+				}
+				return max
+			}
+		}
+		return max
+	}
+
+	return Percentiles{
+		Count: total,
+		P50:   quantile(0.50),
+		P95:   quantile(0.95),
+		P99:   quantile(0.99),
+		Max:   max,
+	}
+}
+
+// latencyBucket returns the histogram bucket of the given value
+func latencyBucket(v uint64) int {
+	if v < latencySubBuckets {
+		return int(v)
+	}
+
+	exp := bits.Len64(v) - 1 // >= latencySubBits
+	sub := (v >> (exp - latencySubBits)) & (latencySubBuckets - 1)
+	return (exp-latencySubBits+1)*latencySubBuckets + int(sub)
+}
+
+// latencyBucketMax returns the largest value of the given histogram bucket
+func latencyBucketMax(i int) uint64 {
+	if i < latencySubBuckets {
+		return uint64(i)
+	}
+
+	exp := i/latencySubBuckets + latencySubBits - 1
+	sub := uint64(i % latencySubBuckets)
+	lower := (latencySubBuckets + sub) << (exp - latencySubBits)
+	return lower + 1<<(exp-latencySubBits) - 1
+}
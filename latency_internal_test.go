@@ -0,0 +1,101 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLatencyBucket(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value uint64
+	}{
+		{name: "zero", value: 0},
+		{name: "linear", value: 15},
+		{name: "first log bucket", value: 16},
+		{name: "microsecond", value: 1000},
+		{name: "second", value: uint64(time.Second)},
+		{name: "max", value: 1<<63 - 1},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b := latencyBucket(tc.value)
+			assert.Assert(t, b < latencyBuckets)
+
+			upper := latencyBucketMax(b)
+			assert.Assert(t, upper >= tc.value)
+			assert.Assert(t, upper-tc.value <= tc.value/latencySubBuckets)
+			if b > 0 {
+				assert.Assert(t, latencyBucketMax(b-1) < tc.value)
+			}
+		})
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	assert.Equal(t, h.percentiles(), Percentiles{})
+
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+
+	p := h.percentiles()
+	assert.Equal(t, p.Count, uint64(100))
+	assert.Equal(t, p.Max, 100*time.Millisecond)
+
+	within := func(got, want time.Duration) {
+		t.Helper()
+		assert.Assert(t, got >= want && got <= want+want/latencySubBuckets, "got %v, want %v", got, want)
+	}
+	within(p.P50, 50*time.Millisecond)
+	within(p.P95, 95*time.Millisecond)
+	within(p.P99, 99*time.Millisecond)
+}
+
+func TestLog_Latencies(t *testing.T) {
+	t.Run("zero without latency tracking", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("data"))
+		assert.NilError(t, err)
+		assert.Equal(t, l.Latencies(), Latencies{})
+	})
+
+	t.Run("records write, read and stream latencies", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithLatencyTracking())
+		assert.NilError(t, err)
+
+		offsets, err := l.WriteBatch(ctx, [][]byte{[]byte("a"), []byte("b")})
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("c"))
+		assert.NilError(t, err)
+		_, err = l.Read(ctx, offsets[0])
+		assert.NilError(t, err)
+
+		mockClock.Add(50 * time.Millisecond)
+		stream, _ := l.Stream(ctx, offsets[0])
+		for i := 0; i < 3; i++ {
+			<-stream
+		}
+
+		latencies := l.Latencies()
+		assert.Equal(t, latencies.Write.Count, uint64(2))
+		assert.Equal(t, latencies.Read.Count, uint64(1))
+		assert.Equal(t, latencies.Stream.Count, uint64(3))
+		assert.Equal(t, latencies.Stream.P50, 50*time.Millisecond)
+		assert.Equal(t, latencies.Stream.P99, 50*time.Millisecond)
+	})
+}
@@ -0,0 +1,41 @@
+package memlog
+
+import "context"
+
+// Appender appends records to a log
+type Appender interface {
+	// Write creates a new record with the given data and returns its offset
+	Write(ctx context.Context, data []byte, options ...WriteOption) (Offset, error)
+}
+
+// Reader reads records from a log
+type Reader interface {
+	// Read returns the record at the given offset
+	Read(ctx context.Context, offset Offset) (Record, error)
+	// Range returns the earliest and latest available record offset or -1 for
+	// both if the log is empty
+	Range(ctx context.Context) (earliest, latest Offset)
+	// Stream streams records starting at the given offset
+	Stream(ctx context.Context, start Offset, options ...StreamOption) (<-chan StreamRecord, <-chan error)
+}
+
+// BatchReader is a Reader which can read multiple records with a single
+// call, e.g. a remote log fetching records with a single request
+type BatchReader interface {
+	Reader
+	// ReadBatch reads up to max records starting at the given offset
+	ReadBatch(ctx context.Context, start Offset, max int) ([]Record, error)
+}
+
+// Logger is a log which can be appended to and read from. Log implements
+// Logger, so that applications can depend on Logger to swap the in-memory log
+// with other implementations, e.g. a remote or persistent log.
+type Logger interface {
+	Appender
+	Reader
+}
+
+var (
+	_ Logger      = (*Log)(nil)
+	_ BatchReader = (*Log)(nil)
+)
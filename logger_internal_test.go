@@ -0,0 +1,47 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// countingReader is a custom Reader backend counting reads
+type countingReader struct {
+	Reader
+	reads int
+}
+
+func (r *countingReader) Read(ctx context.Context, offset Offset) (Record, error) {
+	r.reads++
+	return r.Reader.Read(ctx, offset)
+}
+
+func TestLogger(t *testing.T) {
+	ctx := context.Background()
+
+	var logger Logger
+	l, err := New(ctx)
+	assert.NilError(t, err)
+	logger = l
+
+	for _, d := range NewTestDataSlice(t, 5) {
+		_, err = logger.Write(ctx, d)
+		assert.NilError(t, err)
+	}
+
+	t.Run("imports from custom reader", func(t *testing.T) {
+		src := &countingReader{Reader: logger}
+
+		dst, err := New(ctx)
+		assert.NilError(t, err)
+
+		assert.NilError(t, dst.ImportFrom(ctx, NewLogSource(src)))
+		assert.Equal(t, src.reads, 5)
+
+		earliest, latest := dst.Range(ctx)
+		assert.Equal(t, earliest, Offset(0))
+		assert.Equal(t, latest, Offset(4))
+	})
+}
@@ -0,0 +1,219 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrTopicExists is returned when creating a topic which already exists in
+	// a Manager
+	ErrTopicExists = errors.New("topic already exists")
+	// ErrTopicNotFound is returned when a topic does not exist in a Manager
+	ErrTopicNotFound = errors.New("topic not found")
+	// ErrTemplateNotFound is returned when an option template does not exist
+	// in a Manager
+	ErrTemplateNotFound = errors.New("template not found")
+)
+
+// ManagerOption customizes a Manager
+type ManagerOption func(*Manager) error
+
+// WithSharedPayloads deduplicates record payloads across all logs of a
+// Manager using a single PayloadStore, so writing the same payload to multiple
+// topics stores it only once
+func WithSharedPayloads() ManagerOption {
+	return func(m *Manager) error {
+		m.payloads = NewPayloadStore()
+		return nil
+	}
+}
+
+// WithDefaultOptions sets log options inherited by all topics created by the
+// Manager. Options specified when creating a topic override the defaults.
+func WithDefaultOptions(options ...Option) ManagerOption {
+	return func(m *Manager) error {
+		m.defaults = append(m.defaults, options...)
+		return nil
+	}
+}
+
+// WithTemplate defines a named set of log options, e.g. retention and limits
+// for a class of topics, used by CreateFromTemplate(). Template options
+// override the default options (see WithDefaultOptions()) and are overridden
+// by options specified when creating a topic.
+func WithTemplate(name string, options ...Option) ManagerOption {
+	return func(m *Manager) error {
+		if name == "" {
+			return errors.New("template name must not be empty")
+		}
+
+		if _, ok := m.templates[name]; ok {
+			return fmt.Errorf("template %q already defined", name)
+		}
+
+		m.templates[name] = options
+		return nil
+	}
+}
+
+// Manager manages a set of logs identified by topic name.
+//
+// Safe for concurrent use.
+type Manager struct {
+	mu        sync.RWMutex
+	logs      map[string]*Log
+	payloads  *PayloadStore // optional, shared by all logs
+	defaults  []Option
+	templates map[string][]Option
+}
+
+// NewManager creates a Manager without topics. Default options and templates
+// are validated, i.e. an error is returned if they combine conflicting
+// options.
+func NewManager(options ...ManagerOption) (*Manager, error) {
+	m := Manager{
+		logs:      make(map[string]*Log),
+		templates: make(map[string][]Option),
+	}
+
+	for _, opt := range options {
+		if err := opt(&m); err != nil {
+			return nil, fmt.Errorf("configure manager option: %v", err)
+		}
+	}
+
+	if err := m.validate(nil); err != nil {
+		return nil, fmt.Errorf("configure manager default options: %w", err)
+	}
+
+	for name, tmpl := range m.templates {
+		if err := m.validate(tmpl); err != nil {
+			return nil, fmt.Errorf("configure manager template %q: %w", name, err)
+		}
+	}
+
+	return &m, nil
+}
+
+// validate applies the inherited options followed by the given template
+// options to an unused log and checks for conflicting options
+func (m *Manager) validate(template []Option) error {
+	var l Log
+	for _, opt := range append(append([]Option(nil), defaultOptions...), m.inherit(template, nil)...) {
+		if err := opt(&l); err != nil {
+			return err
+		}
+	}
+
+	return l.validateConfig()
+}
+
+// inherit returns the options of a new topic, i.e. the manager defaults,
+// template and topic options in order
+func (m *Manager) inherit(template, options []Option) []Option {
+	inherited := make([]Option, 0, len(m.defaults)+len(template)+len(options)+1)
+	inherited = append(inherited, m.defaults...)
+	inherited = append(inherited, template...)
+	inherited = append(inherited, options...)
+
+	if m.payloads != nil {
+		inherited = append(inherited, WithPayloadStore(m.payloads))
+	}
+	return inherited
+}
+
+// Create creates a new log for the given topic with the default options of
+// the Manager (see WithDefaultOptions()) and the specified options. If the
+// topic already exists, ErrTopicExists is returned.
+func (m *Manager) Create(ctx context.Context, topic string, options ...Option) (*Log, error) {
+	return m.create(ctx, topic, nil, options)
+}
+
+// CreateFromTemplate creates a new log for the given topic with the options of
+// the named template (see WithTemplate()) and the specified options
+// overriding the template. If the template does not exist,
+// ErrTemplateNotFound is returned.
+func (m *Manager) CreateFromTemplate(ctx context.Context, topic, template string, options ...Option) (*Log, error) {
+	tmpl, ok := m.templates[template]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, template)
+	}
+
+	return m.create(ctx, topic, tmpl, options)
+}
+
+func (m *Manager) create(ctx context.Context, topic string, template, options []Option) (*Log, error) {
+	if topic == "" {
+		return nil, errors.New("topic must not be empty")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.logs[topic]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrTopicExists, topic)
+	}
+
+	l, err := New(ctx, m.inherit(template, options)...)
+	if err != nil {
+		return nil, fmt.Errorf("create log for topic %q: %w", topic, err)
+	}
+
+	m.logs[topic] = l
+	return l, nil
+}
+
+// Get returns the log for the given topic. If the topic does not exist,
+// ErrTopicNotFound is returned.
+func (m *Manager) Get(topic string) (*Log, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	l, ok := m.logs[topic]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTopicNotFound, topic)
+	}
+	return l, nil
+}
+
+// Delete removes the log for the given topic and closes it, see Log.Close().
+// If the topic does not exist, ErrTopicNotFound is returned.
+func (m *Manager) Delete(_ context.Context, topic string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.logs[topic]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTopicNotFound, topic)
+	}
+
+	// the log might have been closed by the caller already
+	_ = l.Close()
+
+	delete(m.logs, topic)
+	return nil
+}
+
+// Topics returns the sorted names of all topics
+func (m *Manager) Topics() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	topics := make([]string, 0, len(m.logs))
+	for t := range m.logs {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+// Payloads returns the payload store shared by all logs of the Manager, or nil
+// if payloads are not shared
+func (m *Manager) Payloads() *PayloadStore {
+	return m.payloads
+}
@@ -0,0 +1,129 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestManager(t *testing.T) {
+	t.Run("creates, gets and deletes topics", func(t *testing.T) {
+		ctx := context.Background()
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		_, err = m.Create(ctx, "")
+		assert.ErrorContains(t, err, "must not be empty")
+
+		_, err = m.Create(ctx, "orders", WithStartOffset(-1))
+		assert.ErrorContains(t, err, "must not be negative")
+
+		orders, err := m.Create(ctx, "orders")
+		assert.NilError(t, err)
+
+		_, err = m.Create(ctx, "orders")
+		assert.Assert(t, errors.Is(err, ErrTopicExists))
+
+		_, err = m.Create(ctx, "audit")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, m.Topics(), []string{"audit", "orders"})
+
+		got, err := m.Get("orders")
+		assert.NilError(t, err)
+		assert.Assert(t, got == orders)
+
+		err = m.Delete(ctx, "orders")
+		assert.NilError(t, err)
+
+		_, err = m.Get("orders")
+		assert.Assert(t, errors.Is(err, ErrTopicNotFound))
+
+		err = m.Delete(ctx, "orders")
+		assert.Assert(t, errors.Is(err, ErrTopicNotFound))
+	})
+
+	t.Run("shares payloads across topics", func(t *testing.T) {
+		ctx := context.Background()
+		m, err := NewManager(WithSharedPayloads())
+		assert.NilError(t, err)
+
+		topics := []string{"a", "b", "c"}
+		for _, topic := range topics {
+			l, createErr := m.Create(ctx, topic)
+			assert.NilError(t, createErr)
+
+			_, writeErr := l.Write(ctx, newTestData(t, "fan-out"))
+			assert.NilError(t, writeErr)
+		}
+
+		assert.Equal(t, m.Payloads().Len(), 1)
+
+		a, err := m.Get("a")
+		assert.NilError(t, err)
+		b, err := m.Get("b")
+		assert.NilError(t, err)
+		assert.Equal(t, &a.active.data[0].Data[0], &b.active.data[0].Data[0])
+
+		for _, topic := range topics {
+			assert.NilError(t, m.Delete(ctx, topic))
+		}
+		assert.Equal(t, m.Payloads().Len(), 0)
+	})
+}
+
+func TestManager_Templates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fails with conflicting options", func(t *testing.T) {
+		_, err := NewManager(WithDefaultOptions(WithSparseOffsets(), WithMerkleTree()))
+		assert.ErrorContains(t, err, "sparse offsets cannot be combined with merkle tree")
+
+		_, err = NewManager(WithSharedPayloads(), WithTemplate("fast", WithOffHeapStorage()))
+		if offHeapSupported {
+			assert.ErrorContains(t, err, `template "fast": off-heap storage cannot be combined`)
+		} else {
+			assert.ErrorContains(t, err, "not supported")
+		}
+
+		_, err = NewManager(WithTemplate("a"), WithTemplate("a"))
+		assert.ErrorContains(t, err, "already defined")
+
+		_, err = NewManager(WithTemplate(""))
+		assert.ErrorContains(t, err, "must not be empty")
+	})
+
+	t.Run("topics inherit defaults and templates", func(t *testing.T) {
+		m, err := NewManager(
+			WithDefaultOptions(WithMaxSegmentSize(10), WithMaxRecordSizeBytes(100)),
+			WithTemplate("audit", WithMaxSegmentSize(1000), WithMerkleTree()),
+		)
+		assert.NilError(t, err)
+
+		orders, err := m.Create(ctx, "orders")
+		assert.NilError(t, err)
+		assert.Equal(t, orders.conf.segmentSize, 10)
+		assert.Equal(t, orders.conf.maxRecordSize, 100)
+
+		// partial override of defaults
+		payments, err := m.Create(ctx, "payments", WithMaxRecordSizeBytes(200))
+		assert.NilError(t, err)
+		assert.Equal(t, payments.conf.segmentSize, 10)
+		assert.Equal(t, payments.conf.maxRecordSize, 200)
+
+		audit, err := m.CreateFromTemplate(ctx, "audit", "audit", WithStartOffset(5))
+		assert.NilError(t, err)
+		assert.Equal(t, audit.conf.segmentSize, 1000)
+		assert.Equal(t, audit.conf.maxRecordSize, 100)
+		assert.Equal(t, audit.conf.startOffset, Offset(5))
+		assert.Assert(t, audit.merkle != nil)
+
+		// conflicting override
+		_, err = m.CreateFromTemplate(ctx, "sparse-audit", "audit", WithSparseOffsets())
+		assert.ErrorContains(t, err, "sparse offsets cannot be combined with merkle tree")
+
+		_, err = m.CreateFromTemplate(ctx, "unknown", "unknown")
+		assert.Assert(t, errors.Is(err, ErrTemplateNotFound))
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"sync"
 	"time"
-
-	"github.com/benbjohnson/clock"
 )
 
 var (
@@ -15,11 +13,20 @@ var (
 	// configured maximum record size
 	ErrRecordTooLarge = errors.New("record data too large")
 	// ErrFutureOffset is returned when the specified offset is in the future and
-	// not written yet
+	// not written yet. Reads return a *FutureOffsetError wrapping
+	// ErrFutureOffset.
 	ErrFutureOffset = errors.New("future offset")
 	// ErrOutOfRange is returned when the specified offset is invalid for the Log
 	// configuration or already purged from history
 	ErrOutOfRange = errors.New("offset out of range")
+	// ErrOffsetGap is returned when the specified offset is within the range of
+	// a log with sparse offsets but no record has been written at this offset
+	ErrOffsetGap = errors.New("offset gap")
+	// ErrDraining is returned when writing to a log which is drained, see
+	// Drain()
+	ErrDraining = errors.New("log is draining")
+	// ErrClosed is returned when using a log which is closed, see Close()
+	ErrClosed = errors.New("log is closed")
 )
 
 // Offset is a monotonically increasing position of a record in the log
@@ -32,6 +39,22 @@ type Header struct {
 	// Created is the UTC timestamp when a record was successfully written in the
 	// log
 	Created time.Time `json:"created"` // UTC
+	// Key is an optional key of a record
+	Key []byte `json:"key,omitempty"`
+	// Headers are optional key/value pairs attached to a record
+	Headers map[string][]byte `json:"headers,omitempty"`
+	// Epoch is the writer epoch of the log when the record was written, see
+	// SetEpoch()
+	Epoch uint64 `json:"epoch,omitempty"`
+	// Checksum is the CRC-32C checksum of the record payload, see
+	// WithChecksums()
+	Checksum uint32 `json:"checksum,omitempty"`
+	// Expires is the UTC time when the record expires or the zero time if the
+	// record does not expire, see WithTTL()
+	Expires time.Time `json:"expires"`
+	// Annotations are mutable key/value pairs attached to a record after it
+	// has been written, see Annotate()
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // Record is an immutable entry in the log
@@ -41,24 +64,66 @@ type Record struct {
 }
 
 func (r Record) deepCopy() Record {
-	if r.Metadata.Offset == 0 && r.Metadata.Created.IsZero() {
+	if r.Metadata.Offset == 0 && r.Metadata.Created.IsZero() && r.Data == nil {
 		return Record{}
 	}
 
 	dCopy := append([]byte(nil), r.Data...)
 	return Record{
 		Metadata: Header{
-			Offset:  r.Metadata.Offset,
-			Created: r.Metadata.Created,
+			Offset:   r.Metadata.Offset,
+			Created:  r.Metadata.Created,
+			Key:      copyBytes(r.Metadata.Key),
+			Headers:  copyHeaders(r.Metadata.Headers),
+			Epoch:    r.Metadata.Epoch,
+			Checksum: r.Metadata.Checksum,
+			Expires:  r.Metadata.Expires,
 		},
 		Data: dCopy,
 	}
 }
 
+// copyBytes returns a copy of b, preserving nil
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+// copyHeaders returns a deep copy of h, preserving nil
+func copyHeaders(h map[string][]byte) map[string][]byte {
+	if h == nil {
+		return nil
+	}
+
+	hCopy := make(map[string][]byte, len(h))
+	for k, v := range h {
+		hCopy[k] = copyBytes(v)
+	}
+	return hCopy
+}
+
 type config struct {
 	startOffset   Offset // logical start offset
 	segmentSize   int    // offsets per segment
 	maxRecordSize int    // bytes
+	offHeap       bool   // store payloads outside the Go heap
+	sparse        bool   // allow gaps between offsets
+	minimal       bool   // store offsets and payloads only
+	unsafeReads   bool   // return records without copying
+	unsafeWrites  bool   // store written slices without copying
+
+	maxAge   time.Duration // optional, time-based retention
+	maxBytes int64         // optional, byte-based retention
+
+	checksums       bool // store payload checksums
+	verifyChecksums bool // verify payload checksums on read
+
+	targetDuration time.Duration // optional, adaptive segment size
+	targetBytes    int           // optional, adaptive segment size
+
+	debugSubscriptions bool // record stack traces of subscriptions
 }
 
 // Log is an append-only in-memory data structure storing records. Records are
@@ -78,12 +143,52 @@ type config struct {
 // Safe for concurrent use.
 type Log struct {
 	conf config
+	id   string // identity of the log incarnation
 
 	mu      sync.RWMutex
 	history *segment // read-only
 	active  *segment // read-write
 	offset  Offset   // monotonic offset counter tracking next write
-	clock   clock.Clock
+	clock   TimeSource
+	codecs  *CodecRegistry
+	codec   Codec // default codec of typed reads and writes
+
+	signer   Signer      // optional
+	verifier Verifier    // optional
+	merkle   *merkleTree // optional
+
+	payloads *PayloadStore // optional, deduplicates payloads
+
+	translations map[string]OffsetTranslation // by source id, see WithSourceID()
+	epoch        uint64                       // writer epoch
+
+	consumers *consumerRegistry
+	draining  int  // active Drain calls, reject writes
+	drained   bool // reject writes after a completed Drain
+	closed    bool
+	done      chan struct{} // closed on Close()
+
+	events     EventHandler       // optional
+	extractors []ContextExtractor // optional
+	admission  map[Priority]int   // optional, retained bytes limit per priority
+	latency    *latencyTracker    // optional
+
+	downsampling *downsampler // optional, coarse history of purged records
+
+	subscriptions *subscriptionRegistry
+
+	access      *accessLog                    // optional
+	compaction  *compactor                    // optional, latest purged record per key
+	sequences   map[string]*producerSequences // idempotent producers
+	expiring    bool                          // records with a TTL written
+	bookmarks   map[string]Offset             // named offsets
+	eviction    *evictor                      // optional, record eviction order
+	watchers    map[chan Offset]struct{}      // write notifications, see Notify()
+	annotations map[Offset]map[string]string  // mutable record annotations
+
+	rate   writeRate // recent write rate for future offset wait hints
+	writes uint64    // number of written records
+	purged uint64    // number of purged records
 }
 
 // New creates an empty log with default options applied, unless specified
@@ -105,52 +210,222 @@ func New(_ context.Context, options ...Option) (*Log, error) {
 		}
 	}
 
-	s, err := newSegment(l.conf.startOffset, l.conf.segmentSize)
+	if l.id == "" {
+		id, err := newLogID()
+		if err != nil {
+			return nil, fmt.Errorf("generate log id: %v", err)
+		}
+		l.id = id
+	}
+
+	if err := l.validateConfig(); err != nil {
+		return nil, fmt.Errorf("configure log: %w", err)
+	}
+	l.subscriptions = newSubscriptionRegistry(l.conf.debugSubscriptions)
+	l.done = make(chan struct{})
+
+	s, err := l.newSegment(l.conf.startOffset)
 	if err != nil {
 		return nil, fmt.Errorf("create active segment: %v", err)
 	}
 	l.active = s
 	l.offset = l.conf.startOffset
+	l.emit(EventCreated, l.conf.startOffset, -1)
 
 	return &l, nil
 }
 
-// Write creates a new record in the log with the given data. The write offset
-// of the new record is returned. If an error occurs, an invalid offset (-1) and
-// the error is returned.
+// validateConfig returns an error if the configured options conflict
+func (l *Log) validateConfig() error {
+	if l.conf.offHeap && l.payloads != nil {
+		return errors.New("off-heap storage cannot be combined with payload deduplication")
+	}
+
+	if l.conf.offHeap && l.conf.unsafeReads {
+		return errors.New("off-heap storage cannot be combined with unsafe reads")
+	}
+
+	if l.conf.sparse && l.merkle != nil {
+		return errors.New("sparse offsets cannot be combined with merkle tree")
+	}
+
+	if l.eviction != nil && l.conf.maxBytes == 0 {
+		return errors.New("eviction strategy requires max log bytes")
+	}
+
+	if l.conf.minimal && (l.signer != nil || l.verifier != nil || l.merkle != nil || l.conf.targetDuration > 0 || l.conf.checksums || l.conf.maxAge > 0) {
+		return errors.New("minimal metadata cannot be combined with signing, merkle tree, checksums, target segment duration or max record age")
+	}
+
+	return nil
+}
+
+// Write creates a new record in the log with the given data. Optional record
+// metadata, such as a key or headers, can be specified with write options. The
+// write offset of the new record is returned. If an error occurs, an invalid
+// offset (-1) and the error is returned. Write returns the context error if
+// the context is done while waiting for concurrent operations on the log.
 //
 // Safe for concurrent use.
-func (l *Log) Write(ctx context.Context, data []byte) (Offset, error) {
-	l.mu.Lock()
+func (l *Log) Write(ctx context.Context, data []byte, options ...WriteOption) (Offset, error) {
+	if l.latency != nil {
+		defer l.latency.write.since(l.clock, l.clock.Now())
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return -1, err
+	}
 	defer l.mu.Unlock()
-	return l.write(ctx, data)
+	return l.write(ctx, data, options...)
+}
+
+// WriteKeyed creates a new record with the given key and data in the log. It
+// is equivalent to Write() with WithKey(), i.e. the key is stored in the
+// record metadata and can be used without parsing the payload, e.g. for
+// per-key lookups or routing.
+//
+// Safe for concurrent use.
+func (l *Log) WriteKeyed(ctx context.Context, key, data []byte, options ...WriteOption) (Offset, error) {
+	return l.Write(ctx, data, append(options[:len(options):len(options)], WithKey(key))...)
 }
 
-func (l *Log) write(ctx context.Context, data []byte) (Offset, error) {
+// lock acquires the write lock of the log unless the context is done first,
+// e.g. when the deadline of a write expires while the log is saturated. If
+// the lock is not acquired, the context error is returned.
+func (l *Log) lock(ctx context.Context) error {
+	if l.mu.TryLock() {
+		return nil
+	}
+
+	if ctx.Done() == nil {
+		// context can not be cancelled
+		l.mu.Lock()
+		return nil
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		l.mu.Lock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		// release the lock once acquired
+		go func() {
+			<-locked
+			l.mu.Unlock()
+		}()
+		return ctx.Err()
+	}
+}
+
+func (l *Log) write(ctx context.Context, data []byte, options ...WriteOption) (Offset, error) {
 	if ctx.Err() != nil {
 		return -1, ctx.Err()
 	}
 
-	if len(data) > l.conf.maxRecordSize {
-		return -1, ErrRecordTooLarge
+	wc := newWriteConfig(options)
+	if offset, ok, err := l.duplicate(wc); ok || err != nil {
+		return offset, err
 	}
 
-	if len(data) == 0 {
-		return -1, errors.New("no data provided")
+	if err := l.validateWrite(data, wc); err != nil {
+		return -1, err
+	}
+
+	if err := l.purgeExpiredPrefix(); err != nil {
+		return -1, err
+	}
+
+	if l.conf.minimal {
+		wc.key, wc.headers, wc.created, wc.expires = nil, nil, time.Time{}, time.Time{}
+	} else {
+		l.extractHeaders(ctx, &wc)
+
+		if wc.created.IsZero() {
+			wc.created = l.clock.Now()
+		}
+		wc.created = wc.created.UTC()
+	}
+
+	var dcopy []byte
+	switch {
+	case l.payloads != nil:
+		dcopy = l.payloads.acquire(data)
+	case l.active.arena != nil:
+		// payload must be allocated in the segment it is written to
+		if l.active.full() {
+			if err := l.extend(); err != nil {
+				panic(err.Error()) // abnormal program state
+			}
+		}
+
+		var err error
+		if dcopy, err = l.active.arena.alloc(data); err != nil {
+			return -1, fmt.Errorf("allocate off-heap memory: %w", err)
+		}
+	case l.conf.unsafeWrites:
+		dcopy = data
+	default:
+		dcopy = append([]byte(nil), data...)
 	}
 
-	dcopy := append([]byte(nil), data...)
 	r := Record{
 		Metadata: Header{
 			Offset:  l.offset,
-			Created: l.clock.Now().UTC(),
+			Created: wc.created,
+			Key:     wc.key,
+			Headers: wc.headers,
+			Epoch:   l.epoch,
 		},
 		Data: dcopy,
 	}
 
+	if wc.written != nil {
+		r.Metadata.Epoch = *wc.written
+	}
+
+	if !l.conf.unsafeWrites {
+		r.Metadata.Key = copyBytes(wc.key)
+		r.Metadata.Headers = copyHeaders(wc.headers)
+	}
+
+	if l.conf.checksums {
+		r.Metadata.Checksum = checksum(dcopy)
+	}
+
+	if wc.ttl > 0 {
+		r.Metadata.Expires = wc.created.Add(wc.ttl)
+	}
+
+	if !wc.expires.IsZero() {
+		r.Metadata.Expires = wc.expires.UTC()
+	}
+
+	if l.conf.maxAge > 0 {
+		if expires := wc.created.Add(l.conf.maxAge); r.Metadata.Expires.IsZero() || expires.Before(r.Metadata.Expires) {
+			r.Metadata.Expires = expires
+		}
+	}
+
+	if !r.Metadata.Expires.IsZero() {
+		l.expiring = true
+	}
+
+	if l.signer != nil {
+		if err := signRecord(l.signer, &r); err != nil {
+			l.releasePayload(r.Data)
+			return -1, err
+		}
+	}
+
 	err := l.active.write(ctx, r)
 	for err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			l.releasePayload(r.Data)
 			return -1, err
 		}
 
@@ -172,15 +447,78 @@ func (l *Log) write(ctx context.Context, data []byte) (Offset, error) {
 		panic("write error: " + err.Error())
 	}
 
+	if l.merkle != nil {
+		l.merkle.append(LeafHash(r))
+	}
+
+	l.supersede(r.Metadata.Key)
+	l.recordSequence(wc.sequence, r.Metadata.Offset)
+	if l.eviction != nil {
+		l.eviction.wrote(r.Metadata.Offset, wc.priority)
+	}
 	l.offset++
+	l.writes++
+	if !l.conf.minimal {
+		l.rate.observe(l.clock.Now())
+	}
+
+	if err := l.enforceMaxBytes(); err != nil {
+		panic(err.Error()) // abnormal program state
+	}
+
+	l.notify(r.Metadata.Offset)
 	return r.Metadata.Offset, nil
 }
 
+// validateWrite returns an error if a record with the given data and write
+// configuration can not be written to the log. Must be protected with a lock
+// by the caller.
+func (l *Log) validateWrite(data []byte, wc writeConfig) error {
+	if l.closed {
+		return ErrClosed
+	}
+
+	if l.draining > 0 || l.drained {
+		return ErrDraining
+	}
+
+	if len(data) > l.conf.maxRecordSize || (l.conf.maxBytes > 0 && int64(len(data)) > l.conf.maxBytes) {
+		return ErrRecordTooLarge
+	}
+
+	if len(data) == 0 {
+		return errors.New("no data provided")
+	}
+
+	if wc.epoch != nil && *wc.epoch != l.epoch {
+		return fmt.Errorf("%w: writer epoch %d, log epoch %d", ErrStaleEpoch, *wc.epoch, l.epoch)
+	}
+
+	if wc.ttl < 0 || (wc.ttl > 0 && l.conf.minimal) {
+		return errors.New("ttl must be positive and can not be combined with minimal metadata")
+	}
+
+	if wc.expected != nil && *wc.expected != l.offset {
+		return fmt.Errorf("%w: expected next offset %d, next offset %d", ErrOffsetConflict, *wc.expected, l.offset)
+	}
+
+	if err := l.checkPurge(l.purgedBy(1)); err != nil {
+		return err
+	}
+
+	return l.admit(len(data), wc.priority)
+}
+
 // Read reads a record from the log at the given offset. If an error occurs, an
-// invalid record and the error is returned.
+// invalid record and the error is returned. If no record has been written to
+// the log, ErrEmptyLog is returned for offsets at or after the start offset.
 //
 // Safe for concurrent use.
 func (l *Log) Read(ctx context.Context, offset Offset) (Record, error) {
+	if l.latency != nil {
+		defer l.latency.read.since(l.clock, l.clock.Now())
+	}
+
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
@@ -192,8 +530,15 @@ func (l *Log) read(ctx context.Context, offset Offset) (Record, error) {
 		return Record{}, ctx.Err()
 	}
 
+	if l.closed {
+		return Record{}, ErrClosed
+	}
+
 	if offset >= l.offset {
-		return Record{}, ErrFutureOffset
+		if l.isEmpty() {
+			return Record{}, ErrEmptyLog
+		}
+		return Record{}, l.futureOffsetError(offset)
 	}
 
 	if offset < l.conf.startOffset {
@@ -205,16 +550,49 @@ func (l *Log) read(ctx context.Context, offset Offset) (Record, error) {
 		return Record{}, err
 	}
 
+	if err = s.touch(l.clock.Now()); err != nil {
+		return Record{}, err
+	}
+
 	r, err := s.read(ctx, offset)
 	if err != nil {
 		return Record{}, err
 	}
 
-	return r.deepCopy(), nil
+	if r.Metadata.Offset != offset {
+		return Record{}, ErrOffsetGap
+	}
+
+	if expired(r.Metadata, l.expiryTime()) {
+		return Record{}, expiredError{}
+	}
+
+	if l.eviction != nil {
+		l.eviction.read(offset, l.clock.Now())
+	}
+
+	if l.conf.verifyChecksums && !IsTombstone(r) {
+		if err = verifyChecksum(r); err != nil {
+			return Record{}, err
+		}
+	}
+
+	if l.verifier != nil && !IsTombstone(r) {
+		if err = verifyRecord(l.verifier, r); err != nil {
+			return Record{}, err
+		}
+	}
+
+	if !l.conf.unsafeReads {
+		r = r.deepCopy()
+	}
+	l.annotate(&r)
+	return r, nil
 }
 
 // Range returns the earliest and latest available record offset in the log. If
-// the log is empty, an invalid offset (-1) for both return values is returned.
+// the log is empty, an invalid offset (-1) for both return values is returned,
+// see Bounds() and IsEmpty() to explicitly handle empty logs.
 // If the log has been purged one or more times, earliest points to the oldest
 // available record offset in the log, i.e. not the configured start offset.
 //
@@ -243,7 +621,7 @@ func (l *Log) offsetRange() (Offset, Offset) {
 		}
 
 		// no purge since start
-		return l.conf.startOffset, l.active.currentOffset()
+		return l.active.start, l.active.currentOffset()
 	}
 
 	return l.history.start, l.active.currentOffset()
@@ -264,13 +642,13 @@ func (l *Log) getSegment(offset Offset) (*segment, error) {
 
 	// search history
 	history := l.history
-	if history != nil {
-		min := history.start
-		max := history.start + Offset(l.conf.segmentSize) - 1
-
-		if min <= offset && offset <= max {
+	if history != nil && offset >= history.start {
+		if offset <= history.currentOffset() {
 			return history, nil
 		}
+
+		// history segment sealed before it was full due to sparse offsets
+		return nil, ErrOffsetGap
 	}
 	return nil, ErrOutOfRange
 }
@@ -280,14 +658,74 @@ func (l *Log) getSegment(offset Offset) (*segment, error) {
 // history will be purged before replacing it. Must be protected with a lock by
 // the caller.
 func (l *Log) extend() error {
+	return l.extendAt(l.offset)
+}
+
+// extendAt is like extend but the new active segment starts at the given
+// offset. Must be protected with a lock by the caller.
+func (l *Log) extendAt(start Offset) error {
 	l.active.seal()
+	l.emit(EventSealed, l.active.start, l.active.currentOffset())
+
+	if l.history != nil {
+		if err := l.downsample(l.history); err != nil {
+			return err
+		}
+		if err := l.compact(l.history, len(l.history.data)); err != nil {
+			return err
+		}
+		l.purge(l.history)
+	}
 
 	l.history = l.active
-	seg, err := newSegment(l.offset, l.conf.segmentSize)
+	seg, err := l.newSegment(start)
 	if err != nil {
 		return err
 	}
 
 	l.active = seg
+	l.emit(EventSegmentRolled, start, -1)
 	return nil
 }
+
+// newSegment creates an empty segment with the configured segment size and
+// storage starting at the given offset. With adaptive segment sizing, the size
+// is derived from the history segment. Must be protected with a lock by the
+// caller.
+func (l *Log) newSegment(start Offset) (*segment, error) {
+	s, err := newSegment(start, l.nextSegmentSize(l.history))
+	if err != nil {
+		return nil, err
+	}
+
+	if l.conf.offHeap {
+		s.arena = &arena{}
+	}
+	s.lastRead = l.clock.Now()
+	return s, nil
+}
+
+// purge releases resources held by the records of a segment removed from the
+// log. The segment must not be used afterwards. Must be protected with a lock
+// by the caller.
+func (l *Log) purge(s *segment) {
+	l.emit(EventPurged, s.start, s.currentOffset())
+	l.purged += uint64(s.written())
+
+	for _, r := range s.data {
+		l.releasePayload(r.Data)
+	}
+
+	if s.arena != nil {
+		s.data = nil // records point to freed memory
+		s.arena.free()
+	}
+}
+
+// releasePayload releases a payload acquired from the deduplicating payload
+// store, if enabled. Must be protected with a lock by the caller.
+func (l *Log) releasePayload(data []byte) {
+	if l.payloads != nil {
+		l.payloads.release(data)
+	}
+}
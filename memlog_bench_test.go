@@ -43,6 +43,34 @@ func BenchmarkLog_write(b *testing.B) {
 	_ = result
 }
 
+func BenchmarkLog_writeMinimalMetadata(b *testing.B) {
+	var (
+		offset Offset
+		result Offset
+		err    error
+	)
+
+	ctx := context.Background()
+	l, err := New(ctx, WithMaxSegmentSize(1000), WithMinimalMetadata())
+	if err != nil {
+		b.Fatalf("create log: %v", err)
+	}
+
+	d := []byte(`{"id":"1","message":"benchmark"}`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offset, err = l.write(ctx, d)
+		if err != nil {
+			b.Fatalf("write data: %v", err)
+		}
+
+		result = offset
+	}
+
+	_ = result
+}
+
 func BenchmarkLog_read(b *testing.B) {
 	const (
 		start   = Offset(0)
@@ -83,3 +111,62 @@ func BenchmarkLog_read(b *testing.B) {
 
 	_ = result
 }
+
+func BenchmarkLog_readUnsafe(b *testing.B) {
+	var (
+		record Record
+		result Record
+		err    error
+	)
+
+	ctx := context.Background()
+	l, err := New(ctx, WithMaxSegmentSize(1000), WithUnsafeReads())
+	if err != nil {
+		b.Fatalf("create log: %v", err)
+	}
+
+	d := []byte(`{"id":"1","message":"benchmark"}`)
+	offset, err := l.write(ctx, d)
+	if err != nil {
+		b.Fatalf("write data: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		record, err = l.read(ctx, offset)
+		if err != nil {
+			b.Fatalf("read data: %v", err)
+		}
+		result = record
+	}
+
+	_ = result
+}
+
+func BenchmarkLog_writeUnsafe(b *testing.B) {
+	var (
+		offset Offset
+		result Offset
+		err    error
+	)
+
+	ctx := context.Background()
+	l, err := New(ctx, WithMaxSegmentSize(1000), WithUnsafeWrites())
+	if err != nil {
+		b.Fatalf("create log: %v", err)
+	}
+
+	d := []byte(`{"id":"1","message":"benchmark"}`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offset, err = l.write(ctx, d)
+		if err != nil {
+			b.Fatalf("write data: %v", err)
+		}
+
+		result = offset
+	}
+
+	_ = result
+}
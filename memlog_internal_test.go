@@ -22,6 +22,13 @@ func TestRecord_deepCopy(t *testing.T) {
 	}{
 		{name: "nil Record", record: Record{}},
 		{name: "valid Record", record: Record{Metadata: Header{Offset: 1, Created: now}, Data: data}},
+		{
+			name: "valid Record with key and headers",
+			record: Record{
+				Metadata: Header{Offset: 1, Created: now, Key: []byte("1"), Headers: map[string][]byte{"trace-id": []byte("abc")}},
+				Data:     data,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -330,6 +337,168 @@ func TestLog_read(t *testing.T) {
 	})
 }
 
+func TestLog_WriteDeadline(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	writes := map[string]func(ctx context.Context) error{
+		"Write": func(ctx context.Context) error {
+			_, err := l.Write(ctx, []byte("data"))
+			return err
+		},
+		"WriteBatch": func(ctx context.Context) error {
+			_, err := l.WriteBatch(ctx, [][]byte{[]byte("data")})
+			return err
+		},
+		"ImportFrom": func(ctx context.Context) error {
+			return l.ImportFrom(ctx, NewSliceSource([]Record{{Data: []byte("data")}}))
+		},
+	}
+
+	for name, write := range writes {
+		write := write
+		t.Run(name, func(t *testing.T) {
+			// saturated log
+			l.mu.RLock()
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := write(timeoutCtx)
+			assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+			assert.Assert(t, time.Since(start) < time.Second)
+
+			l.mu.RUnlock()
+
+			// lock acquired after the deadline is released
+			assert.NilError(t, write(ctx))
+		})
+	}
+}
+
+func TestLog_WriteKeyed(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	offset, err := l.WriteKeyed(ctx, []byte("key"), []byte("data"), WithHeader("trace", []byte("1")))
+	assert.NilError(t, err)
+
+	r, err := l.Read(ctx, offset)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Metadata.Key, []byte("key"))
+	assert.DeepEqual(t, r.Metadata.Headers, map[string][]byte{"trace": []byte("1")})
+	assert.DeepEqual(t, r.Data, []byte("data"))
+
+	// key argument takes precedence over key option
+	offset, err = l.WriteKeyed(ctx, []byte("key"), []byte("data"), WithKey([]byte("other")))
+	assert.NilError(t, err)
+
+	r, err = l.Read(ctx, offset)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Metadata.Key, []byte("key"))
+}
+
+func TestLog_WriteMinimalMetadata(t *testing.T) {
+	t.Run("fails with conflicting options", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := New(ctx, WithMinimalMetadata(), WithMerkleTree())
+		assert.ErrorContains(t, err, "minimal metadata cannot be combined")
+
+		_, err = New(ctx, WithMinimalMetadata(), WithSegmentTargetDuration(time.Minute))
+		assert.ErrorContains(t, err, "minimal metadata cannot be combined")
+	})
+
+	t.Run("stores offsets and payloads only", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMinimalMetadata(), WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d, WithKey([]byte("key")), WithHeader("trace", []byte("1")))
+			assert.NilError(t, err)
+		}
+
+		r, err := l.Read(ctx, 4)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r, Record{Metadata: Header{Offset: 4}, Data: NewTestDataSlice(t, 5)[4]})
+
+		l, err = New(ctx, WithMinimalMetadata())
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("first"))
+		assert.NilError(t, err)
+
+		r, err = l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r, Record{Data: []byte("first")})
+	})
+}
+
+func TestLog_ReadUnsafe(t *testing.T) {
+	t.Run("fails with off-heap storage", func(t *testing.T) {
+		if !offHeapSupported {
+			t.Skip("off-heap storage not supported")
+		}
+
+		_, err := New(context.Background(), WithUnsafeReads(), WithOffHeapStorage())
+		assert.ErrorContains(t, err, "cannot be combined with unsafe reads")
+	})
+
+	t.Run("returns records without copying", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithUnsafeReads())
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, []byte("data"), WithKey([]byte("key")))
+		assert.NilError(t, err)
+
+		first, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		second, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, first.Data, []byte("data"))
+		assert.Equal(t, &first.Data[0], &second.Data[0])
+		assert.Equal(t, &first.Metadata.Key[0], &second.Metadata.Key[0])
+
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _ = l.Read(ctx, offset)
+		})
+		assert.Equal(t, allocs, float64(0))
+	})
+}
+
+func TestLog_WriteUnsafe(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx, WithUnsafeWrites())
+	assert.NilError(t, err)
+
+	data, key := []byte("data"), []byte("key")
+	offset, err := l.Write(ctx, data, WithKey(key))
+	assert.NilError(t, err)
+
+	// the log owns the written slices
+	data[0], key[0] = 'D', 'K'
+	r, err := l.Read(ctx, offset)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, []byte("Data"))
+	assert.DeepEqual(t, r.Metadata.Key, []byte("Key"))
+
+	// payloads are copied with deduplication
+	l, err = New(ctx, WithUnsafeWrites(), WithPayloadStore(NewPayloadStore()))
+	assert.NilError(t, err)
+
+	offset, err = l.Write(ctx, data)
+	assert.NilError(t, err)
+	data[0] = 'd'
+	r, err = l.Read(ctx, offset)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, []byte("Data"))
+}
+
 func Test_offsetRange(t *testing.T) {
 	type wantOffsets struct {
 		earliest Offset
@@ -0,0 +1,309 @@
+package memlog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+var (
+	// ErrMerkleDisabled is returned when a tamper-evidence operation is called
+	// on a log created without WithMerkleTree()
+	ErrMerkleDisabled = errors.New("merkle tree not enabled")
+	// ErrInvalidProof is returned when an inclusion proof or tree head does not
+	// verify
+	ErrInvalidProof = errors.New("invalid proof")
+)
+
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+	treeHeadContext  = "memlog-tree-head-v1"
+)
+
+// InclusionProof proves that a record is included in a log at a given tree
+// size. The proof follows the Merkle audit path construction from RFC 6962.
+type InclusionProof struct {
+	// Offset is the offset of the proven record
+	Offset Offset `json:"offset"`
+	// LeafIndex is the position of the record in the tree
+	LeafIndex uint64 `json:"leafIndex"`
+	// TreeSize is the size of the tree the proof was created for
+	TreeSize uint64 `json:"treeSize"`
+	// LeafHash is the leaf hash of the record, see LeafHash()
+	LeafHash []byte `json:"leafHash"`
+	// Hashes is the audit path from the leaf to the root
+	Hashes [][]byte `json:"hashes"`
+}
+
+// TreeHead describes the Merkle tree of a log at a given size. If the log is
+// configured with a Signer, the tree head is signed.
+type TreeHead struct {
+	TreeSize  uint64    `json:"treeSize"`
+	RootHash  []byte    `json:"rootHash"`
+	Timestamp time.Time `json:"timestamp"` // UTC
+	Signature []byte    `json:"signature,omitempty"`
+}
+
+// merkleTree is an append-only Merkle tree over record leaf hashes. Leaf
+// hashes are kept for all records ever written, including purged ones, so
+// history remains provable. The hashes of complete subtrees are kept as
+// leaves are appended, i.e. root hashes and audit paths are computed from
+// O(log n) subtrees. Not safe for concurrent use.
+type merkleTree struct {
+	// levels[h][j] is the hash of the complete subtree of the leaves
+	// [j*2^h, (j+1)*2^h), i.e. levels[0] are the leaf hashes
+	levels [][][]byte
+}
+
+func (m *merkleTree) append(leaf []byte) {
+	if len(m.levels) == 0 {
+		m.levels = [][][]byte{nil}
+	}
+
+	m.levels[0] = append(m.levels[0], leaf)
+	for h := 0; len(m.levels[h])%2 == 0; h++ {
+		// complete subtree of height h+1
+		if h+1 == len(m.levels) {
+			m.levels = append(m.levels, nil)
+		}
+
+		n := len(m.levels[h])
+		m.levels[h+1] = append(m.levels[h+1], nodeHash(m.levels[h][n-2], m.levels[h][n-1]))
+	}
+}
+
+func (m *merkleTree) size() uint64 {
+	if len(m.levels) == 0 {
+		return 0
+	}
+	return uint64(len(m.levels[0]))
+}
+
+// leaf returns the leaf hash at index i
+func (m *merkleTree) leaf(i uint64) []byte {
+	return m.levels[0][i]
+}
+
+// rootHash returns the Merkle tree hash of the first n leaves
+func (m *merkleTree) rootHash(n uint64) []byte {
+	return m.subtreeHash(0, n)
+}
+
+// path returns the audit path for leaf index i in the tree of the first n
+// leaves
+func (m *merkleTree) path(i, n uint64) [][]byte {
+	return m.subtreePath(i, 0, n)
+}
+
+// subtreeHash returns the Merkle tree hash of the n leaves starting at index
+// start. Subtrees of the tree of the first n leaves start at a multiple of
+// their largest power of two, i.e. are composed of complete subtrees.
+func (m *merkleTree) subtreeHash(start, n uint64) []byte {
+	switch {
+	case n == 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case n&(n-1) == 0:
+		h := bits.TrailingZeros64(n)
+		return m.levels[h][start>>h]
+	default:
+		k := splitPoint(n)
+		return nodeHash(m.subtreeHash(start, k), m.subtreeHash(start+k, n-k))
+	}
+}
+
+// subtreePath returns the audit path for leaf index i in the subtree of the n
+// leaves starting at index start
+func (m *merkleTree) subtreePath(i, start, n uint64) [][]byte {
+	if n <= 1 {
+		return nil
+	}
+
+	k := splitPoint(n)
+	if i < k {
+		return append(m.subtreePath(i, start, k), m.subtreeHash(start+k, n-k))
+	}
+	return append(m.subtreePath(i-k, start+k, n-k), m.subtreeHash(start, k))
+}
+
+// splitPoint returns the largest power of two smaller than n (n > 1)
+func splitPoint(n uint64) uint64 {
+	k := uint64(1)
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// LeafHash returns the Merkle leaf hash of a record. The hash covers offset,
+// creation time, key, headers and data of the record.
+func LeafHash(r Record) []byte {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(r.Metadata.Offset))
+	binary.BigEndian.PutUint64(buf[8:], uint64(r.Metadata.Created.UnixNano()))
+
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(buf[:])
+	h.Write(signingMessage(r))
+	return h.Sum(nil)
+}
+
+// Verify checks that the inclusion proof resolves to the given root hash. Use
+// LeafHash() to assert that the proof belongs to a specific record.
+func Verify(proof InclusionProof, rootHash []byte) error {
+	if proof.LeafIndex >= proof.TreeSize {
+		return fmt.Errorf("%w: leaf index %d not in tree of size %d", ErrInvalidProof, proof.LeafIndex, proof.TreeSize)
+	}
+
+	fn, sn := proof.LeafIndex, proof.TreeSize-1
+	r := proof.LeafHash
+	for _, p := range proof.Hashes {
+		if sn == 0 {
+			return fmt.Errorf("%w: audit path too long", ErrInvalidProof)
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(r, rootHash) {
+		return fmt.Errorf("%w: root hash mismatch", ErrInvalidProof)
+	}
+	return nil
+}
+
+// VerifyTreeHead checks the signature of a tree head created by a log
+// configured with a Signer
+func VerifyTreeHead(v Verifier, th TreeHead) error {
+	if v == nil {
+		return errors.New("verifier must not be nil")
+	}
+
+	if err := v.Verify(treeHeadMessage(th), th.Signature); err != nil {
+		return fmt.Errorf("%w: tree head signature: %v", ErrInvalidProof, err)
+	}
+	return nil
+}
+
+func treeHeadMessage(th TreeHead) []byte {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], th.TreeSize)
+	binary.BigEndian.PutUint64(buf[8:], uint64(th.Timestamp.UnixNano()))
+
+	msg := append([]byte(treeHeadContext), buf[:]...)
+	return append(msg, th.RootHash...)
+}
+
+// Prove returns an inclusion proof for the record at the given offset against
+// the current tree. Records which have been purged can still be proven.
+//
+// Safe for concurrent use.
+func (l *Log) Prove(ctx context.Context, offset Offset) (InclusionProof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.merkle == nil {
+		return InclusionProof{}, ErrMerkleDisabled
+	}
+	return l.prove(ctx, offset, l.merkle.size())
+}
+
+// ProveAt returns an inclusion proof for the record at the given offset
+// against the tree with the given size, e.g. the size of a previously
+// published TreeHead.
+//
+// Safe for concurrent use.
+func (l *Log) ProveAt(ctx context.Context, offset Offset, treeSize uint64) (InclusionProof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.merkle == nil {
+		return InclusionProof{}, ErrMerkleDisabled
+	}
+	return l.prove(ctx, offset, treeSize)
+}
+
+func (l *Log) prove(ctx context.Context, offset Offset, treeSize uint64) (InclusionProof, error) {
+	if ctx.Err() != nil {
+		return InclusionProof{}, ctx.Err()
+	}
+
+	if treeSize > l.merkle.size() {
+		return InclusionProof{}, fmt.Errorf("tree size %d larger than current size %d", treeSize, l.merkle.size())
+	}
+
+	if offset < l.conf.startOffset {
+		return InclusionProof{}, ErrOutOfRange
+	}
+
+	index := uint64(offset - l.conf.startOffset)
+	if index >= treeSize {
+		return InclusionProof{}, ErrFutureOffset
+	}
+
+	return InclusionProof{
+		Offset:    offset,
+		LeafIndex: index,
+		TreeSize:  treeSize,
+		LeafHash:  l.merkle.leaf(index),
+		Hashes:    l.merkle.path(index, treeSize),
+	}, nil
+}
+
+// TreeHead returns the current tree head of the log. If the log is configured
+// with a Signer, the tree head is signed.
+//
+// Safe for concurrent use.
+func (l *Log) TreeHead(ctx context.Context) (TreeHead, error) {
+	if ctx.Err() != nil {
+		return TreeHead{}, ctx.Err()
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.merkle == nil {
+		return TreeHead{}, ErrMerkleDisabled
+	}
+
+	size := l.merkle.size()
+	th := TreeHead{
+		TreeSize:  size,
+		RootHash:  l.merkle.rootHash(size),
+		Timestamp: l.clock.Now().UTC(),
+	}
+
+	if l.signer != nil {
+		sig, err := l.signer.Sign(treeHeadMessage(th))
+		if err != nil {
+			return TreeHead{}, fmt.Errorf("sign tree head: %v", err)
+		}
+		th.Signature = sig
+	}
+
+	return th, nil
+}
@@ -0,0 +1,191 @@
+package memlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Prove(t *testing.T) {
+	t.Run("fails when merkle tree is disabled", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Prove(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrMerkleDisabled))
+
+		_, err = l.TreeHead(ctx)
+		assert.Assert(t, errors.Is(err, ErrMerkleDisabled))
+	})
+
+	t.Run("proofs verify against tree head for all tree sizes", func(t *testing.T) {
+		const (
+			start   = Offset(10)
+			segSize = 3
+			records = 11
+		)
+
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(start), WithMaxSegmentSize(segSize), WithMerkleTree(), WithClock(clock.NewMock()))
+		assert.NilError(t, err)
+
+		var written []Record
+		for _, d := range NewTestDataSlice(t, records) {
+			offset, writeErr := l.Write(ctx, d)
+			assert.NilError(t, writeErr)
+
+			r, readErr := l.Read(ctx, offset)
+			assert.NilError(t, readErr)
+			written = append(written, r)
+
+			th, thErr := l.TreeHead(ctx)
+			assert.NilError(t, thErr)
+
+			// every record written so far is included in the current tree
+			for _, w := range written {
+				proof, proveErr := l.Prove(ctx, w.Metadata.Offset)
+				assert.NilError(t, proveErr)
+				assert.DeepEqual(t, proof.LeafHash, LeafHash(w))
+				assert.NilError(t, Verify(proof, th.RootHash))
+			}
+		}
+
+		// purged records remain provable
+		_, err = l.Read(ctx, start)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		th, err := l.TreeHead(ctx)
+		assert.NilError(t, err)
+		proof, err := l.Prove(ctx, start)
+		assert.NilError(t, err)
+		assert.NilError(t, Verify(proof, th.RootHash))
+	})
+
+	t.Run("proof for older tree size verifies against older root only", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMerkleTree())
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		old, err := l.TreeHead(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 3) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		current, err := l.TreeHead(ctx)
+		assert.NilError(t, err)
+
+		proof, err := l.ProveAt(ctx, 2, old.TreeSize)
+		assert.NilError(t, err)
+		assert.NilError(t, Verify(proof, old.RootHash))
+		assert.Assert(t, errors.Is(Verify(proof, current.RootHash), ErrInvalidProof))
+
+		_, err = l.ProveAt(ctx, 6, old.TreeSize)
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	})
+
+	t.Run("tampered proof fails", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMerkleTree())
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 4) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		th, err := l.TreeHead(ctx)
+		assert.NilError(t, err)
+
+		proof, err := l.Prove(ctx, 1)
+		assert.NilError(t, err)
+
+		proof.LeafHash = LeafHash(Record{Data: []byte("forged")})
+		assert.Assert(t, errors.Is(Verify(proof, th.RootHash), ErrInvalidProof))
+	})
+
+	t.Run("signs tree heads", func(t *testing.T) {
+		ctx := context.Background()
+		signer, err := NewHMACSigner([]byte("secret"))
+		assert.NilError(t, err)
+
+		l, err := New(ctx, WithMerkleTree(), WithSigner(signer))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "1"))
+		assert.NilError(t, err)
+
+		th, err := l.TreeHead(ctx)
+		assert.NilError(t, err)
+		assert.NilError(t, VerifyTreeHead(signer, th))
+
+		th.TreeSize++
+		assert.Assert(t, errors.Is(VerifyTreeHead(signer, th), ErrInvalidProof))
+	})
+}
+
+// referenceRoot is the Merkle tree hash of RFC 6962 computed over all leaves
+func referenceRoot(leaves [][]byte) []byte {
+	switch n := len(leaves); n {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	default:
+		k := splitPoint(uint64(n))
+		return nodeHash(referenceRoot(leaves[:k]), referenceRoot(leaves[k:]))
+	}
+}
+
+func Test_merkleTree(t *testing.T) {
+	var (
+		m      merkleTree
+		leaves [][]byte
+	)
+
+	for i := 0; i < 70; i++ {
+		n := uint64(len(leaves))
+		assert.Equal(t, m.size(), n)
+		assert.DeepEqual(t, m.rootHash(n), referenceRoot(leaves))
+
+		// older tree sizes remain available
+		for size := uint64(1); size <= n; size++ {
+			root := m.rootHash(size)
+			assert.DeepEqual(t, root, referenceRoot(leaves[:size]))
+
+			for index := uint64(0); index < size; index++ {
+				proof := InclusionProof{
+					LeafIndex: index,
+					TreeSize:  size,
+					LeafHash:  m.leaf(index),
+					Hashes:    m.path(index, size),
+				}
+				assert.NilError(t, Verify(proof, root))
+			}
+		}
+
+		leaf := sha256.Sum256([]byte{byte(i)})
+		m.append(leaf[:])
+		leaves = append(leaves, leaf[:])
+	}
+
+	// only complete subtrees are kept, i.e. less than two hashes per leaf
+	var hashes int
+	for _, level := range m.levels {
+		hashes += len(level)
+	}
+	assert.Assert(t, hashes < 2*len(leaves))
+}
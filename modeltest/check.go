@@ -0,0 +1,121 @@
+package modeltest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing/quick"
+
+	"github.com/embano1/memlog"
+)
+
+// errOther is the class of errors not defined by memlog
+var errOther = errors.New("other error")
+
+// classes are the errors compared between a logger and the model
+var classes = []error{
+	memlog.ErrEmptyLog,
+	memlog.ErrFutureOffset,
+	memlog.ErrOutOfRange,
+	memlog.ErrRecordTooLarge,
+}
+
+// class returns the error class of the given error or nil
+func class(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, c := range classes {
+		if errors.Is(err, c) {
+			return c
+		}
+	}
+	return errOther
+}
+
+// Check applies the operations in order to the logger and the model and
+// returns an error describing the first divergence. Errors are compared by
+// their memlog error (see errors.Is()), e.g. a logger may wrap
+// memlog.ErrOutOfRange with additional context.
+func Check(ctx context.Context, l memlog.Logger, m *Model, ops []Op) error {
+	for i, op := range ops {
+		if err := apply(ctx, l, m, op); err != nil {
+			return fmt.Errorf("operation %d %s: %w", i, op, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, l memlog.Logger, m *Model, op Op) error {
+	switch op.Kind {
+	case OpWrite:
+		got, gotErr := l.Write(ctx, op.Data)
+		want, wantErr := m.Write(op.Data)
+		if class(gotErr) != class(wantErr) {
+			return fmt.Errorf("got error %v, want %v", gotErr, wantErr)
+		}
+		if wantErr == nil && got != want {
+			return fmt.Errorf("got offset %d, want %d", got, want)
+		}
+
+	case OpRead:
+		offset := m.Start() + op.Offset
+		got, gotErr := l.Read(ctx, offset)
+		want, wantErr := m.Read(offset)
+		if class(gotErr) != class(wantErr) {
+			return fmt.Errorf("got error %v, want %v", gotErr, wantErr)
+		}
+		if wantErr != nil {
+			return nil
+		}
+		if got.Metadata.Offset != offset {
+			return fmt.Errorf("got record offset %d, want %d", got.Metadata.Offset, offset)
+		}
+		if !bytes.Equal(got.Data, want) {
+			return fmt.Errorf("got data %q, want %q", got.Data, want)
+		}
+
+	case OpRange:
+		gotEarliest, gotLatest := l.Range(ctx)
+		wantEarliest, wantLatest := m.Range()
+		if gotEarliest != wantEarliest || gotLatest != wantLatest {
+			return fmt.Errorf("got range [%d, %d], want [%d, %d]", gotEarliest, gotLatest, wantEarliest, wantLatest)
+		}
+
+	default:
+		return fmt.Errorf("unknown operation kind %s", op.Kind)
+	}
+
+	return nil
+}
+
+// Conforms checks with quick.Check() that loggers created with newLogger
+// conform to the models created with newModel for randomly generated
+// operations (see Ops). Every check uses a new logger and model. A nil config
+// uses the defaults of quick.Check(). A failing check returns an error
+// wrapping the *quick.CheckError with the failing operations and describing
+// the divergence. If a logger can not be created, its error is returned.
+func Conforms(ctx context.Context, newLogger func() (memlog.Logger, error), newModel func() *Model, config *quick.Config) error {
+	var setupErr, checkErr error
+
+	property := func(ops Ops) bool {
+		l, err := newLogger()
+		if err != nil {
+			setupErr = fmt.Errorf("create logger: %w", err)
+			return false
+		}
+
+		checkErr = Check(ctx, l, newModel(), ops)
+		return checkErr == nil
+	}
+
+	if err := quick.Check(property, config); err != nil {
+		if setupErr != nil {
+			return setupErr
+		}
+		return fmt.Errorf("%w: %v", err, checkErr)
+	}
+	return nil
+}
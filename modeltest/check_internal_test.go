@@ -0,0 +1,109 @@
+package modeltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/quick"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/embano1/memlog"
+)
+
+func TestConforms(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name          string
+		start         memlog.Offset
+		segmentSize   int
+		maxRecordSize int
+	}{
+		{name: "small segments", start: 0, segmentSize: 2, maxRecordSize: 32},
+		{name: "custom start offset", start: 100, segmentSize: 5, maxRecordSize: 32},
+		{name: "small records", start: 0, segmentSize: 3, maxRecordSize: 8},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			newLogger := func() (memlog.Logger, error) {
+				return memlog.New(ctx,
+					memlog.WithStartOffset(tc.start),
+					memlog.WithMaxSegmentSize(tc.segmentSize),
+					memlog.WithMaxRecordSizeBytes(tc.maxRecordSize),
+				)
+			}
+
+			newModel := func() *Model {
+				m, err := NewModel(tc.start, tc.segmentSize, tc.maxRecordSize)
+				assert.NilError(t, err)
+				return m
+			}
+
+			err := Conforms(ctx, newLogger, newModel, &quick.Config{MaxCount: 200})
+			assert.NilError(t, err)
+		})
+	}
+
+	t.Run("reports divergence", func(t *testing.T) {
+		newLogger := func() (memlog.Logger, error) {
+			return memlog.New(ctx, memlog.WithMaxSegmentSize(2))
+		}
+
+		// diverges from the log after the first segment rollover
+		newModel := func() *Model {
+			m, err := NewModel(0, 3, memlog.DefaultMaxRecordSize)
+			assert.NilError(t, err)
+			return m
+		}
+
+		err := Conforms(ctx, newLogger, newModel, &quick.Config{MaxCount: 200})
+		var checkErr *quick.CheckError
+		assert.Assert(t, errors.As(err, &checkErr))
+		assert.ErrorContains(t, err, "operation")
+	})
+
+	t.Run("reports logger errors", func(t *testing.T) {
+		newLogger := func() (memlog.Logger, error) {
+			return memlog.New(ctx, memlog.WithMaxSegmentSize(-1))
+		}
+
+		err := Conforms(ctx, newLogger, NewDefaultModel, nil)
+		assert.ErrorContains(t, err, "create logger")
+	})
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	l, err := memlog.New(ctx, memlog.WithMaxSegmentSize(2))
+	assert.NilError(t, err)
+
+	m, err := NewModel(0, 2, memlog.DefaultMaxRecordSize)
+	assert.NilError(t, err)
+
+	ops := []Op{
+		{Kind: OpRange},
+		{Kind: OpRead, Offset: 0},
+		{Kind: OpWrite},
+		{Kind: OpWrite, Data: []byte("a")},
+		{Kind: OpWrite, Data: []byte("b")},
+		{Kind: OpWrite, Data: []byte("c")},
+		{Kind: OpWrite, Data: []byte("d")},
+		{Kind: OpWrite, Data: []byte("e")},
+		{Kind: OpRead, Offset: 1},
+		{Kind: OpRead, Offset: 2},
+		{Kind: OpRead, Offset: 5},
+		{Kind: OpRange},
+	}
+	assert.NilError(t, Check(ctx, l, m, ops))
+
+	// the log diverges from a model with other records
+	m, err = NewModel(0, 2, memlog.DefaultMaxRecordSize)
+	assert.NilError(t, err)
+	_, err = m.Write([]byte("a"))
+	assert.NilError(t, err)
+
+	err = Check(ctx, l, m, []Op{{Kind: OpRange}})
+	assert.ErrorContains(t, err, "operation 0 range: got range [2, 4], want [0, 0]")
+}
@@ -0,0 +1,112 @@
+// Package modeltest property-tests implementations of memlog.Logger against a
+// sequential reference model of the semantics of memlog.Log, e.g. in the CI
+// of alternative backends. Operations are generated with testing/quick (see
+// Ops) and applied to both the implementation and the model (see Check()).
+package modeltest
+
+import (
+	"errors"
+
+	"github.com/embano1/memlog"
+)
+
+// Model is a sequential reference model of a memlog.Log with the given start
+// offset, segment size and maximum record size. Records are retained like in
+// a log with an active and a history segment, i.e. when the active segment is
+// full, the next write purges the history segment.
+//
+// Not safe for concurrent use.
+type Model struct {
+	start         memlog.Offset
+	segmentSize   int
+	maxRecordSize int
+
+	earliest memlog.Offset // offset of records[0]
+	records  [][]byte      // retained records
+	next     memlog.Offset // next write offset
+}
+
+// NewModel creates an empty model. The arguments correspond to the options
+// memlog.WithStartOffset(), memlog.WithMaxSegmentSize() and
+// memlog.WithMaxRecordSizeBytes().
+func NewModel(start memlog.Offset, segmentSize, maxRecordSize int) (*Model, error) {
+	if start < 0 {
+		return nil, errors.New("start offset must not be negative")
+	}
+
+	if segmentSize <= 0 {
+		return nil, errors.New("segment size must be greater than 0")
+	}
+
+	if maxRecordSize <= 0 {
+		return nil, errors.New("max record size must be greater than 0")
+	}
+
+	return &Model{
+		start:         start,
+		segmentSize:   segmentSize,
+		maxRecordSize: maxRecordSize,
+		earliest:      start,
+		next:          start,
+	}, nil
+}
+
+// NewDefaultModel creates an empty model of a memlog.Log with default options
+func NewDefaultModel() *Model {
+	m, err := NewModel(memlog.DefaultStartOffset, memlog.DefaultSegmentSize, memlog.DefaultMaxRecordSize)
+	if err != nil {
+		panic(err.Error()) // invalid defaults
+	}
+	return m
+}
+
+// Write appends a record with the given data and returns its offset
+func (m *Model) Write(data []byte) (memlog.Offset, error) {
+	if len(data) > m.maxRecordSize {
+		return -1, memlog.ErrRecordTooLarge
+	}
+
+	if len(data) == 0 {
+		return -1, errors.New("no data provided")
+	}
+
+	// active and history segment are full, i.e. the history segment is purged
+	if len(m.records) == 2*m.segmentSize {
+		m.records = m.records[m.segmentSize:]
+		m.earliest += memlog.Offset(m.segmentSize)
+	}
+
+	m.records = append(m.records, append([]byte(nil), data...))
+	m.next++
+	return m.next - 1, nil
+}
+
+// Read returns the data of the record at the given offset
+func (m *Model) Read(offset memlog.Offset) ([]byte, error) {
+	if offset >= m.next {
+		if len(m.records) == 0 {
+			return nil, memlog.ErrEmptyLog
+		}
+		return nil, memlog.ErrFutureOffset
+	}
+
+	if offset < m.earliest {
+		return nil, memlog.ErrOutOfRange
+	}
+
+	return append([]byte(nil), m.records[offset-m.earliest]...), nil
+}
+
+// Range returns the earliest and latest retained record offset or -1 for both
+// if the model is empty
+func (m *Model) Range() (earliest, latest memlog.Offset) {
+	if len(m.records) == 0 {
+		return -1, -1
+	}
+	return m.earliest, m.next - 1
+}
+
+// Start returns the start offset of the model
+func (m *Model) Start() memlog.Offset {
+	return m.start
+}
@@ -0,0 +1,75 @@
+package modeltest
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/embano1/memlog"
+)
+
+func TestNewModel(t *testing.T) {
+	testCases := []struct {
+		name          string
+		start         memlog.Offset
+		segmentSize   int
+		maxRecordSize int
+		wantErr       string
+	}{
+		{name: "valid", start: 10, segmentSize: 2, maxRecordSize: 10},
+		{name: "negative start offset", start: -1, segmentSize: 2, maxRecordSize: 10, wantErr: "start offset"},
+		{name: "invalid segment size", segmentSize: 0, maxRecordSize: 10, wantErr: "segment size"},
+		{name: "invalid max record size", segmentSize: 2, maxRecordSize: 0, wantErr: "max record size"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewModel(tc.start, tc.segmentSize, tc.maxRecordSize)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestModel(t *testing.T) {
+	m, err := NewModel(10, 2, 4)
+	assert.NilError(t, err)
+
+	earliest, latest := m.Range()
+	assert.Equal(t, earliest, memlog.Offset(-1))
+	assert.Equal(t, latest, memlog.Offset(-1))
+
+	_, err = m.Read(10)
+	assert.Assert(t, errors.Is(err, memlog.ErrEmptyLog))
+
+	_, err = m.Write([]byte("12345"))
+	assert.Assert(t, errors.Is(err, memlog.ErrRecordTooLarge))
+
+	_, err = m.Write(nil)
+	assert.ErrorContains(t, err, "no data provided")
+
+	for i, d := range []string{"a", "b", "c", "d", "e"} {
+		offset, err := m.Write([]byte(d))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, memlog.Offset(10+i))
+	}
+
+	// history segment [10, 11] purged with the fifth write
+	earliest, latest = m.Range()
+	assert.Equal(t, earliest, memlog.Offset(12))
+	assert.Equal(t, latest, memlog.Offset(14))
+
+	_, err = m.Read(11)
+	assert.Assert(t, errors.Is(err, memlog.ErrOutOfRange))
+
+	_, err = m.Read(15)
+	assert.Assert(t, errors.Is(err, memlog.ErrFutureOffset))
+
+	data, err := m.Read(13)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "d")
+}
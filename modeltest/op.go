@@ -0,0 +1,93 @@
+package modeltest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing/quick"
+
+	"github.com/embano1/memlog"
+)
+
+// OpKind is the kind of an operation
+type OpKind int
+
+const (
+	// OpWrite writes a record
+	OpWrite OpKind = iota
+	// OpRead reads a record
+	OpRead
+	// OpRange reads the offset range
+	OpRange
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpWrite:
+		return "write"
+	case OpRead:
+		return "read"
+	case OpRange:
+		return "range"
+	default:
+		return fmt.Sprintf("op(%d)", int(k))
+	}
+}
+
+// Op is an operation applied to a logger and the model
+type Op struct {
+	Kind OpKind
+	// Data is the payload of a write
+	Data []byte
+	// Offset is the offset of a read relative to the start offset of the log,
+	// i.e. 0 reads the first record ever written
+	Offset memlog.Offset
+}
+
+func (o Op) String() string {
+	switch o.Kind {
+	case OpWrite:
+		return fmt.Sprintf("write(%d bytes)", len(o.Data))
+	case OpRead:
+		return fmt.Sprintf("read(%d)", o.Offset)
+	default:
+		return o.Kind.String()
+	}
+}
+
+// maxDataSize is the maximum payload size of generated writes
+const maxDataSize = 32
+
+// Ops is a sequence of operations. Ops implements quick.Generator, i.e. it can
+// be used as an argument of properties checked with quick.Check(). The number
+// of generated operations is bounded by the size of the quick.Config. Reads
+// target offsets around the written records, i.e. purged, retained and future
+// offsets.
+type Ops []Op
+
+var _ quick.Generator = Ops(nil)
+
+// Generate returns a random sequence of operations
+func (Ops) Generate(rand *rand.Rand, size int) reflect.Value {
+	ops := make(Ops, rand.Intn(size+1))
+
+	var written int
+	for i := range ops {
+		switch n := rand.Intn(10); {
+		case n < 6:
+			// empty payloads are invalid writes
+			data := make([]byte, rand.Intn(maxDataSize+1))
+			rand.Read(data)
+			ops[i] = Op{Kind: OpWrite, Data: data}
+			if len(data) > 0 {
+				written++
+			}
+		case n < 9:
+			ops[i] = Op{Kind: OpRead, Offset: memlog.Offset(rand.Intn(written+3) - 1)}
+		default:
+			ops[i] = Op{Kind: OpRange}
+		}
+	}
+
+	return reflect.ValueOf(ops)
+}
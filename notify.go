@@ -0,0 +1,61 @@
+package memlog
+
+import "context"
+
+// Notify returns a channel signalling the offset of the latest record whenever
+// new records are written, so that readers can wait for new records instead of
+// polling for ErrFutureOffset. Notifications are delivered without blocking
+// writers: a watcher which does not keep up only receives the latest offset,
+// i.e. consecutive writes are coalesced into a single notification. Each call
+// registers an independent watcher. The channel is closed when the context is
+// cancelled or the log is closed.
+//
+// Safe for concurrent use.
+func (l *Log) Notify(ctx context.Context) <-chan Offset {
+	ch := make(chan Offset, 1)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed || ctx.Err() != nil {
+		close(ch)
+		return ch
+	}
+
+	if l.watchers == nil {
+		l.watchers = make(map[chan Offset]struct{})
+	}
+	l.watchers[ch] = struct{}{}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-l.done:
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(l.watchers, ch)
+		close(ch)
+	}()
+
+	return ch
+}
+
+// notify signals the written offset to all watchers, replacing a notification
+// not yet received. Must be protected with a lock by the caller.
+func (l *Log) notify(offset Offset) {
+	for ch := range l.watchers {
+		// drop the pending notification, only the latest offset is relevant
+		select {
+		case <-ch:
+		default:
+		}
+
+		select {
+		case ch <- offset:
+		default:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Notify(t *testing.T) {
+	t.Run("signals written offsets to independent watchers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		first, second := l.Notify(ctx), l.Notify(ctx)
+
+		offset, err := l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+
+		for _, ch := range []<-chan Offset{first, second} {
+			select {
+			case got := <-ch:
+				assert.Equal(t, got, offset)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for notification")
+			}
+		}
+	})
+
+	t.Run("coalesces notifications of a slow watcher", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		ch := l.Notify(ctx)
+		for i := 0; i < 5; i++ {
+			_, err = l.Write(ctx, []byte("a"))
+			assert.NilError(t, err)
+		}
+
+		assert.Equal(t, <-ch, Offset(4))
+		select {
+		case got := <-ch:
+			t.Fatalf("unexpected notification %d", got)
+		default:
+		}
+	})
+
+	t.Run("closes channel when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		ch := l.Notify(ctx)
+		cancel()
+
+		_, ok := <-ch
+		assert.Assert(t, !ok)
+
+		// writes without watchers
+		_, err = l.Write(context.Background(), []byte("a"))
+		assert.NilError(t, err)
+	})
+
+	t.Run("closes channel when log is closed", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		ch := l.Notify(ctx)
+		assert.NilError(t, l.Close())
+
+		_, ok := <-ch
+		assert.Assert(t, !ok)
+
+		_, ok = <-l.Notify(ctx)
+		assert.Assert(t, !ok)
+	})
+}
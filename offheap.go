@@ -0,0 +1,59 @@
+package memlog
+
+import (
+	"errors"
+)
+
+// offHeapChunkSize is the default size of memory chunks allocated outside the
+// Go heap. Records larger than the chunk size get a dedicated chunk.
+const offHeapChunkSize = 1 << 20 // 1MiB
+
+// errOffHeapUnsupported is returned when off-heap storage is not available on
+// the platform
+var errOffHeapUnsupported = errors.New("off-heap storage not supported on this platform")
+
+// arena allocates record payloads in manually managed memory outside the Go
+// heap. All memory of an arena is released at once with free(). Slices
+// returned by alloc() must not be used after free(). Not safe for concurrent
+// use.
+type arena struct {
+	chunks  [][]byte
+	current []byte // unused remainder of the last chunk
+}
+
+// alloc copies data into arena memory and returns the copy
+func (a *arena) alloc(data []byte) ([]byte, error) {
+	n := len(data)
+	if n > len(a.current) {
+		size := offHeapChunkSize
+		if n > size {
+			size = n
+		}
+
+		chunk, err := mapChunk(size)
+		if err != nil {
+			return nil, err
+		}
+
+		a.chunks = append(a.chunks, chunk)
+		a.current = chunk
+	}
+
+	b := a.current[:n:n]
+	copy(b, data)
+	a.current = a.current[n:]
+
+	return b, nil
+}
+
+// free releases all memory of the arena
+func (a *arena) free() {
+	for _, c := range a.chunks {
+		if err := unmapChunk(c); err != nil {
+			panic("free off-heap memory: " + err.Error()) // abnormal program state
+		}
+	}
+
+	a.chunks = nil
+	a.current = nil
+}
@@ -0,0 +1,74 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_OffHeapStorage(t *testing.T) {
+	if !offHeapSupported {
+		t.Run("fails on unsupported platform", func(t *testing.T) {
+			_, err := New(context.Background(), WithOffHeapStorage())
+			assert.Assert(t, errors.Is(err, errOffHeapUnsupported))
+		})
+		return
+	}
+
+	t.Run("fails when combined with deduplication", func(t *testing.T) {
+		_, err := New(context.Background(), WithOffHeapStorage(), WithPayloadDeduplication())
+		assert.ErrorContains(t, err, "cannot be combined")
+	})
+
+	t.Run("reads and purges off-heap records", func(t *testing.T) {
+		const (
+			segSize = 10
+			records = 35
+		)
+
+		ctx := context.Background()
+		l, err := New(ctx, WithOffHeapStorage(), WithMaxSegmentSize(segSize))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, records)
+		var purged *segment
+		for i, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+
+			if i == segSize-1 {
+				purged = l.active
+			}
+		}
+
+		assert.Assert(t, purged.arena.chunks == nil)
+		assert.Assert(t, len(l.active.arena.chunks) > 0)
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(20))
+		for offset := earliest; offset <= latest; offset++ {
+			r, readErr := l.Read(ctx, offset)
+			assert.NilError(t, readErr)
+			assert.DeepEqual(t, r.Data, data[offset])
+		}
+	})
+
+	t.Run("allocates dedicated chunk for large records", func(t *testing.T) {
+		var a arena
+		defer a.free()
+
+		large := make([]byte, offHeapChunkSize+1)
+		large[offHeapChunkSize] = 'x'
+
+		small, err := a.alloc([]byte("small"))
+		assert.NilError(t, err)
+
+		b, err := a.alloc(large)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, b, large)
+		assert.Equal(t, string(small), "small")
+		assert.Equal(t, len(a.chunks), 2)
+	})
+}
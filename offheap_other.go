@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package memlog
+
+// offHeapSupported reports whether off-heap storage is available
+const offHeapSupported = false
+
+func mapChunk(_ int) ([]byte, error) {
+	return nil, errOffHeapUnsupported
+}
+
+func unmapChunk(_ []byte) error {
+	return errOffHeapUnsupported
+}
@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package memlog
+
+import (
+	"syscall"
+)
+
+// offHeapSupported reports whether off-heap storage is available
+const offHeapSupported = true
+
+// mapChunk allocates an anonymous private memory mapping of the given size
+func mapChunk(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+func unmapChunk(b []byte) error {
+	return syscall.Munmap(b)
+}
@@ -2,6 +2,7 @@ package memlog
 
 import (
 	"errors"
+	"time"
 
 	"github.com/benbjohnson/clock"
 )
@@ -19,19 +20,37 @@ const (
 type Option func(*Log) error
 
 var defaultOptions = []Option{
-	WithClock(clock.New()),
+	WithTimeSource(SystemTimeSource()),
 	WithStartOffset(DefaultStartOffset),
 	WithMaxSegmentSize(DefaultSegmentSize),
 	WithMaxRecordSizeBytes(DefaultMaxRecordSize),
+	WithCodecRegistry(DefaultCodecRegistry()),
+	WithCodec(JSONCodec{}),
+	WithConsumerTimeout(DefaultConsumerTimeout),
 }
 
+// WithClock sets the time source of the log to the given
+// github.com/benbjohnson/clock clock, see ClockTimeSource()
 func WithClock(c clock.Clock) Option {
 	return func(log *Log) error {
 		if c == nil {
 			return errors.New("clock must not be nil")
 		}
 
-		log.clock = c
+		log.clock = ClockTimeSource(c)
+		return nil
+	}
+}
+
+// WithTimeSource sets the time source of the log, e.g. an adapter for a mock
+// clock in tests. Defaults to SystemTimeSource().
+func WithTimeSource(ts TimeSource) Option {
+	return func(log *Log) error {
+		if ts == nil {
+			return errors.New("time source must not be nil")
+		}
+
+		log.clock = ts
 		return nil
 	}
 }
@@ -65,3 +84,299 @@ func WithStartOffset(offset Offset) Option {
 		return nil
 	}
 }
+
+// WithCodecRegistry sets the codec registry used to resolve codecs by content
+// type. Defaults to the process-wide DefaultCodecRegistry.
+func WithCodecRegistry(r *CodecRegistry) Option {
+	return func(log *Log) error {
+		if r == nil {
+			return errors.New("codec registry must not be nil")
+		}
+
+		log.codecs = r
+		return nil
+	}
+}
+
+// WithCodec sets the codec used to encode and decode values of the log, e.g.
+// by typed logs (see NewTyped()) without an explicit codec. Defaults to
+// JSONCodec.
+func WithCodec(c Codec) Option {
+	return func(log *Log) error {
+		if c == nil {
+			return errors.New("codec must not be nil")
+		}
+
+		log.codec = c
+		return nil
+	}
+}
+
+// WithSigner signs every written record with the given signer. The signature
+// is stored in the HeaderSignature record header.
+func WithSigner(s Signer) Option {
+	return func(log *Log) error {
+		if s == nil {
+			return errors.New("signer must not be nil")
+		}
+
+		log.signer = s
+		return nil
+	}
+}
+
+// WithVerifier verifies the signature of every record on read, including
+// streams. Reading a record with a missing or invalid signature returns
+// ErrInvalidSignature.
+func WithVerifier(v Verifier) Option {
+	return func(log *Log) error {
+		if v == nil {
+			return errors.New("verifier must not be nil")
+		}
+
+		log.verifier = v
+		return nil
+	}
+}
+
+// WithMerkleTree maintains a Merkle tree over all written records to provide
+// tamper-evidence with Prove() and TreeHead(). The tree keeps one hash (32
+// bytes) per record ever written, i.e. memory usage is not bounded by
+// purging.
+func WithMerkleTree() Option {
+	return func(log *Log) error {
+		log.merkle = &merkleTree{}
+		return nil
+	}
+}
+
+// WithPayloadDeduplication stores identical record payloads only once. Each
+// record keeps its own metadata while sharing the payload bytes with other
+// records of the same content. Payloads are freed when the last record
+// referencing them is purged. Reduces memory usage when the same payload is
+// written many times at the cost of hashing every written payload.
+func WithPayloadDeduplication() Option {
+	return func(log *Log) error {
+		log.payloads = NewPayloadStore()
+		return nil
+	}
+}
+
+// WithPayloadStore deduplicates record payloads like WithPayloadDeduplication()
+// using the given store. The store can be shared by multiple logs, so that
+// writing the same payload to several logs stores it only once.
+func WithPayloadStore(s *PayloadStore) Option {
+	return func(log *Log) error {
+		if s == nil {
+			return errors.New("payload store must not be nil")
+		}
+
+		log.payloads = s
+		return nil
+	}
+}
+
+// WithOffHeapStorage is an EXPERIMENTAL option to store record payloads in
+// anonymous memory mappings outside the Go heap. Memory is freed explicitly
+// when a segment is purged, which keeps large logs from increasing garbage
+// collection scan times. Reads always return copies on the Go heap. Not
+// available on all platforms and cannot be combined with payload
+// deduplication.
+func WithOffHeapStorage() Option {
+	return func(log *Log) error {
+		if !offHeapSupported {
+			return errOffHeapUnsupported
+		}
+
+		log.conf.offHeap = true
+		return nil
+	}
+}
+
+// WithUnsafeReads returns records without copying their payload, key and
+// headers on reads, which avoids an allocation per read for logs with many
+// readers. Returned records share memory with the log and other readers, i.e.
+// they must not be modified. Payloads of deleted records (see Delete()) are
+// scrubbed in place, i.e. also in records returned earlier. Only use unsafe
+// reads with trusted, read-only consumers. Cannot be combined with off-heap
+// storage (see WithOffHeapStorage()), which releases payload memory on purge.
+func WithUnsafeReads() Option {
+	return func(log *Log) error {
+		log.conf.unsafeReads = true
+		return nil
+	}
+}
+
+// WithUnsafeWrites stores the payload, key and headers passed to a write
+// without copying them, i.e. the log takes ownership of the slices, which
+// avoids allocations per write for callers allocating a fresh buffer per
+// record. Written slices must not be modified by the caller after the write.
+// Payloads of deleted records (see Delete()) are scrubbed in place, i.e. also
+// in the caller's buffer. Payloads are still copied with payload
+// deduplication (see WithPayloadStore()) and off-heap storage (see
+// WithOffHeapStorage()).
+func WithUnsafeWrites() Option {
+	return func(log *Log) error {
+		log.conf.unsafeWrites = true
+		return nil
+	}
+}
+
+// WithSparseOffsets allows writing records at explicit offsets with WriteAt(),
+// leaving gaps between offsets, e.g. when mirroring an upstream system with
+// holes in its offsets. Reading an offset in a gap returns ErrOffsetGap.
+//
+// Gaps smaller than the remaining space in the active segment are stored as
+// empty slots. Larger gaps start a new active segment at the written offset,
+// i.e. the log may retain fewer records than in dense mode.
+func WithSparseOffsets() Option {
+	return func(log *Log) error {
+		log.conf.sparse = true
+		return nil
+	}
+}
+
+// WithMinimalMetadata stores only the offset and payload of records, e.g. when
+// using the log as an in-process ring buffer on hot paths. Creation times are
+// not recorded, and keys and headers of writes (including extracted headers,
+// see WithContextExtractor()) are dropped. Features depending on record
+// metadata, such as signing, merkle trees and time-based adaptive segment
+// sizing, can not be combined with minimal metadata.
+func WithMinimalMetadata() Option {
+	return func(log *Log) error {
+		log.conf.minimal = true
+		return nil
+	}
+}
+
+// WithOffsetTranslation loads an offset translation, e.g. persisted from
+// OffsetTranslation() after an import, so that Translate() maps checkpoints of
+// the source log after a restart. Translations of imports with a source id
+// (see OffsetTranslationFrom()) are loaded for their source, i.e. the option
+// can be specified once per source.
+func WithOffsetTranslation(t OffsetTranslation) Option {
+	return func(log *Log) error {
+		for i, r := range t.Runs {
+			if r.Count <= 0 {
+				return errors.New("translation run count must be greater than 0")
+			}
+
+			if i > 0 {
+				prev := t.Runs[i-1]
+				if r.Source < prev.Source+Offset(prev.Count) {
+					return errors.New("translation runs must be ordered by source offset")
+				}
+			}
+		}
+
+		if log.translations == nil {
+			log.translations = make(map[string]OffsetTranslation)
+		}
+		log.translations[t.Source] = t.copy()
+		return nil
+	}
+}
+
+// WithConsumerTimeout sets the duration after the last heartbeat of a
+// consumer when it is considered dead, see Heartbeat()
+func WithConsumerTimeout(d time.Duration) Option {
+	return func(log *Log) error {
+		if d <= 0 {
+			return errors.New("timeout must be greater than 0")
+		}
+
+		log.consumers = newConsumerRegistry(d)
+		return nil
+	}
+}
+
+// WriteOption configures a single write
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	key      []byte
+	headers  map[string][]byte
+	created  time.Time // preserved creation time, e.g. on import
+	expires  time.Time // preserved expiry time, e.g. on restore
+	epoch    *uint64   // expected writer epoch
+	written  *uint64   // preserved writer epoch of the record, e.g. on restore
+	expected *Offset   // expected next write offset
+	sequence *sequence // idempotent write
+	ttl      time.Duration
+	priority Priority // admission priority class
+}
+
+// newWriteConfig applies the given write options
+func newWriteConfig(options []WriteOption) writeConfig {
+	var wc writeConfig
+	for _, opt := range options {
+		opt(&wc)
+	}
+	return wc
+}
+
+// withConfig replaces the write configuration, e.g. to write a record with
+// options resolved earlier
+func withConfig(wc writeConfig) WriteOption {
+	return func(c *writeConfig) {
+		*c = wc
+	}
+}
+
+// WithKey sets the key of the written record
+func WithKey(key []byte) WriteOption {
+	return func(wc *writeConfig) {
+		wc.key = key
+	}
+}
+
+// WithHeader adds a header to the written record. Repeated keys overwrite
+// earlier values.
+func WithHeader(key string, value []byte) WriteOption {
+	return func(wc *writeConfig) {
+		if wc.headers == nil {
+			wc.headers = make(map[string][]byte)
+		}
+		wc.headers[key] = value
+	}
+}
+
+// WithHeaders adds the given headers to the written record
+func WithHeaders(headers map[string][]byte) WriteOption {
+	return func(wc *writeConfig) {
+		for k, v := range headers {
+			WithHeader(k, v)(wc)
+		}
+	}
+}
+
+// WithEpoch fences the write with the writer epoch of the caller. If the epoch
+// of the log has changed, e.g. because another writer took over with
+// SetEpoch(), the write fails with ErrStaleEpoch.
+func WithEpoch(epoch uint64) WriteOption {
+	return func(wc *writeConfig) {
+		wc.epoch = &epoch
+	}
+}
+
+// withCreated overwrites the creation time of the written record
+func withCreated(t time.Time) WriteOption {
+	return func(wc *writeConfig) {
+		wc.created = t
+	}
+}
+
+// withExpires overwrites the expiry time of the written record. The zero time
+// keeps the expiry time derived from the TTL and maximum record age.
+func withExpires(t time.Time) WriteOption {
+	return func(wc *writeConfig) {
+		wc.expires = t
+	}
+}
+
+// withWrittenEpoch overwrites the writer epoch of the written record
+func withWrittenEpoch(epoch uint64) WriteOption {
+	return func(wc *writeConfig) {
+		wc.written = &epoch
+	}
+}
@@ -0,0 +1,303 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// DefaultMaxInFlight is the maximum number of records processed concurrently
+// by ProcessOrdered unless explicitly specified
+const DefaultMaxInFlight = streamBuffer
+
+// ProcessFunc processes a single record
+type ProcessFunc func(ctx context.Context, r Record) error
+
+// OrderedOption customizes ProcessOrdered
+type OrderedOption func(*orderedConfig) error
+
+type orderedConfig struct {
+	workers     int
+	maxInFlight int
+	onCommit    func(Offset)
+}
+
+// WithWorkers sets the number of goroutines processing records. Defaults to
+// the number of CPUs.
+func WithWorkers(n int) OrderedOption {
+	return func(c *orderedConfig) error {
+		if n <= 0 {
+			return errors.New("workers must be greater than 0")
+		}
+		c.workers = n
+		return nil
+	}
+}
+
+// WithMaxInFlight sets the maximum number of records received from the stream
+// but not processed yet. Defaults to DefaultMaxInFlight.
+func WithMaxInFlight(n int) OrderedOption {
+	return func(c *orderedConfig) error {
+		if n <= 0 {
+			return errors.New("max in-flight records must be greater than 0")
+		}
+		c.maxInFlight = n
+		return nil
+	}
+}
+
+// WithCommitHandler calls fn whenever the committed offset advances. The
+// committed offset is the offset of the latest record for which all records
+// received before it have been processed successfully, i.e. a safe
+// checkpoint. fn is called sequentially with increasing offsets and should not
+// block.
+func WithCommitHandler(fn func(committed Offset)) OrderedOption {
+	return func(c *orderedConfig) error {
+		if fn == nil {
+			return errors.New("commit handler must not be nil")
+		}
+		c.onCommit = fn
+		return nil
+	}
+}
+
+// ProcessOrdered processes the records of a stream, e.g. created with
+// Stream(), concurrently while preserving the order of records with the same
+// key. Records with different keys are processed in parallel by any idle
+// worker. Records without a key have no ordering guarantees.
+//
+// ProcessOrdered blocks until the stream terminates or fn returns an error.
+// The first error returned by fn or the error terminating the stream is
+// returned together with the committed offset, i.e. the offset up to which
+// all records have been processed successfully. If no record has been
+// committed, -1 is returned.
+func ProcessOrdered(ctx context.Context, stream <-chan StreamRecord, errs <-chan error, fn ProcessFunc, options ...OrderedOption) (Offset, error) {
+	if fn == nil {
+		return -1, errors.New("process func must not be nil")
+	}
+
+	conf := orderedConfig{
+		workers:     runtime.NumCPU(),
+		maxInFlight: DefaultMaxInFlight,
+	}
+	for _, opt := range options {
+		if err := opt(&conf); err != nil {
+			return -1, fmt.Errorf("configure ordered processing option: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := newOrderedProcessor(conf, fn, cancel)
+
+	var wg sync.WaitGroup
+	for i := 0; i < conf.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	streamErr := func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-errs:
+				// process records buffered before the stream terminated
+				if stream != nil {
+					for r := range stream {
+						if !p.dispatch(r.Record) {
+							return nil
+						}
+					}
+				}
+				return err
+			case r, ok := <-stream:
+				if !ok {
+					stream = nil
+					continue
+				}
+
+				if !p.dispatch(r.Record) {
+					return nil
+				}
+			}
+		}
+	}()
+
+	p.wait()
+	close(p.ready)
+	wg.Wait()
+
+	if err := p.failure(); err != nil {
+		return p.committedOffset(), err
+	}
+	return p.committedOffset(), streamErr
+}
+
+// orderedProcessor tracks per-key queues and the committed offset of
+// ProcessOrdered
+type orderedProcessor struct {
+	conf   orderedConfig
+	fn     ProcessFunc
+	cancel context.CancelFunc
+	ready  chan string // keys with queued records and no active worker
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	queues    map[string][]Record
+	inFlight  int
+	pending   []Offset // received offsets in order, not committed yet
+	done      map[Offset]bool
+	committed Offset
+	err       error
+
+	commitMu  sync.Mutex
+	published Offset // last offset passed to the commit handler
+}
+
+func newOrderedProcessor(conf orderedConfig, fn ProcessFunc, cancel context.CancelFunc) *orderedProcessor {
+	p := orderedProcessor{
+		conf:   conf,
+		fn:     fn,
+		cancel: cancel,
+		// every ready key has at least one in-flight record, i.e. sends never
+		// block
+		ready:     make(chan string, conf.maxInFlight),
+		queues:    make(map[string][]Record),
+		done:      make(map[Offset]bool),
+		committed: -1,
+		published: -1,
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	return &p
+}
+
+// dispatch queues a record for processing, blocking while the maximum number
+// of in-flight records is reached. Returns false if processing failed.
+func (p *orderedProcessor) dispatch(r Record) bool {
+	p.mu.Lock()
+	for p.inFlight >= p.conf.maxInFlight && p.err == nil {
+		p.cond.Wait()
+	}
+
+	if p.err != nil {
+		p.mu.Unlock()
+		return false
+	}
+
+	key := string(r.Metadata.Key)
+	if len(r.Metadata.Key) == 0 {
+		// no ordering constraints
+		key = fmt.Sprintf("\x00%d", r.Metadata.Offset)
+	}
+
+	p.inFlight++
+	p.pending = append(p.pending, r.Metadata.Offset)
+
+	q, active := p.queues[key]
+	p.queues[key] = append(q, r)
+	p.mu.Unlock()
+
+	if !active {
+		p.ready <- key
+	}
+	return true
+}
+
+// work processes the queued records of ready keys until the ready channel is
+// closed
+func (p *orderedProcessor) work(ctx context.Context) {
+	for key := range p.ready {
+		for {
+			p.mu.Lock()
+			q := p.queues[key]
+			if len(q) == 0 {
+				delete(p.queues, key)
+				p.mu.Unlock()
+				break
+			}
+
+			r := q[0]
+			p.queues[key] = q[1:]
+			failed := p.err != nil
+			p.mu.Unlock()
+
+			var err error
+			if !failed {
+				err = p.fn(ctx, r)
+			}
+			p.complete(r.Metadata.Offset, failed, err)
+		}
+	}
+}
+
+// complete marks a record as processed and advances the committed offset
+func (p *orderedProcessor) complete(offset Offset, skipped bool, err error) {
+	p.mu.Lock()
+	p.inFlight--
+
+	switch {
+	case err != nil:
+		if p.err == nil {
+			p.err = fmt.Errorf("process record at offset %d: %w", offset, err)
+			p.cancel()
+		}
+	case !skipped && p.err == nil:
+		p.done[offset] = true
+		for len(p.pending) > 0 && p.done[p.pending[0]] {
+			p.committed = p.pending[0]
+			delete(p.done, p.pending[0])
+			p.pending = p.pending[1:]
+		}
+	}
+
+	committed := p.committed
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.publish(committed)
+}
+
+// publish calls the commit handler if the committed offset advanced
+func (p *orderedProcessor) publish(committed Offset) {
+	if p.conf.onCommit == nil {
+		return
+	}
+
+	p.commitMu.Lock()
+	defer p.commitMu.Unlock()
+
+	if committed > p.published {
+		p.published = committed
+		p.conf.onCommit(committed)
+	}
+}
+
+// wait blocks until all in-flight records are completed
+func (p *orderedProcessor) wait() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.inFlight > 0 {
+		p.cond.Wait()
+	}
+}
+
+func (p *orderedProcessor) failure() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *orderedProcessor) committedOffset() Offset {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.committed
+}
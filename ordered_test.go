@@ -0,0 +1,137 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProcessOrdered(t *testing.T) {
+	const (
+		records = 200
+		keys    = 5
+	)
+
+	seed := func(t *testing.T, ctx context.Context) *Log {
+		l, err := New(ctx, WithMaxSegmentSize(records))
+		assert.NilError(t, err)
+
+		for i, d := range NewTestDataSlice(t, records) {
+			var opts []WriteOption
+			if i%10 != 0 { // some records without key
+				opts = append(opts, WithKey([]byte(strconv.Itoa(i%keys))))
+			}
+
+			_, err = l.Write(ctx, d, opts...)
+			assert.NilError(t, err)
+		}
+		return l
+	}
+
+	t.Run("fails with invalid options", func(t *testing.T) {
+		_, err := ProcessOrdered(context.Background(), nil, nil, nil)
+		assert.ErrorContains(t, err, "must not be nil")
+
+		fn := func(context.Context, Record) error { return nil }
+		_, err = ProcessOrdered(context.Background(), nil, nil, fn, WithWorkers(0))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("preserves per-key order and commits processed prefix", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l := seed(t, ctx)
+		stream, errs := l.Stream(ctx, 0)
+
+		var (
+			mu        sync.Mutex
+			seen      = make(map[string][]Offset)
+			processed int
+			commits   []Offset
+		)
+
+		fn := func(_ context.Context, r Record) error {
+			time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			seen[string(r.Metadata.Key)] = append(seen[string(r.Metadata.Key)], r.Metadata.Offset)
+			processed++
+			return nil
+		}
+
+		onCommit := func(committed Offset) {
+			commits = append(commits, committed)
+			if committed == records-1 {
+				cancel()
+			}
+		}
+
+		committed, err := ProcessOrdered(ctx, stream, errs, fn, WithWorkers(8), WithMaxInFlight(16), WithCommitHandler(onCommit))
+		assert.Assert(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, committed, Offset(records-1))
+		assert.Equal(t, processed, records)
+
+		for key, offsets := range seen {
+			if key == "" {
+				continue
+			}
+			for i := 1; i < len(offsets); i++ {
+				assert.Assert(t, offsets[i-1] < offsets[i], "key %s out of order: %v", key, offsets)
+			}
+		}
+
+		for i := 1; i < len(commits); i++ {
+			assert.Assert(t, commits[i-1] < commits[i])
+		}
+	})
+
+	t.Run("stops on error without committing failed record", func(t *testing.T) {
+		const failAt = Offset(50)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l := seed(t, ctx)
+		stream, errs := l.Stream(ctx, 0)
+
+		processErr := errors.New("process failed")
+		fn := func(_ context.Context, r Record) error {
+			if r.Metadata.Offset == failAt {
+				return processErr
+			}
+			return nil
+		}
+
+		committed, err := ProcessOrdered(ctx, stream, errs, fn, WithWorkers(4))
+		assert.Assert(t, errors.Is(err, processErr))
+		assert.Assert(t, committed < failAt)
+	})
+	t.Run("processes buffered records before terminal stream error", func(t *testing.T) {
+		ctx := context.Background()
+
+		// both channels are ready, repeat to exercise the select order
+		for i := 0; i < 50; i++ {
+			stream := make(chan StreamRecord, 3)
+			errs := make(chan error, 1)
+			for offset := Offset(0); offset < 3; offset++ {
+				stream <- StreamRecord{Record: Record{Metadata: Header{Offset: offset}}}
+			}
+			errs <- ErrSlowReader
+			close(stream)
+			close(errs)
+
+			fn := func(context.Context, Record) error { return nil }
+			committed, err := ProcessOrdered(ctx, stream, errs, fn, WithWorkers(2))
+			assert.Assert(t, errors.Is(err, ErrSlowReader))
+			assert.Equal(t, committed, Offset(2))
+		}
+	})
+}
@@ -0,0 +1,276 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLinger is the maximum duration a Producer buffers records before
+	// flushing unless explicitly specified
+	DefaultLinger = time.Millisecond * 5
+	// DefaultBatchSize is the number of buffered records flushed by a Producer
+	// unless explicitly specified
+	DefaultBatchSize = 100
+)
+
+// ErrProducerClosed is returned when writing to a closed Producer
+var ErrProducerClosed = errors.New("producer closed")
+
+// BatchResult is the result of a batch flushed by a Producer
+type BatchResult struct {
+	// Offsets are the offsets of the written records in the order of the
+	// Producer writes
+	Offsets []Offset
+	// Records is the number of records in the batch
+	Records int
+	// Err is the error if the batch was not written
+	Err error
+}
+
+// ProducerOption customizes a Producer
+type ProducerOption func(*Producer) error
+
+// WithLinger sets the maximum duration records are buffered before they are
+// flushed. Defaults to DefaultLinger.
+func WithLinger(d time.Duration) ProducerOption {
+	return func(p *Producer) error {
+		if d <= 0 {
+			return errors.New("linger must be greater than 0")
+		}
+
+		p.linger = d
+		return nil
+	}
+}
+
+// WithBatchSize sets the number of buffered records which triggers a flush.
+// Defaults to DefaultBatchSize.
+func WithBatchSize(n int) ProducerOption {
+	return func(p *Producer) error {
+		if n <= 0 {
+			return errors.New("batch size must be greater than 0")
+		}
+
+		p.batchSize = n
+		return nil
+	}
+}
+
+// WithBatchHandler calls fn with the result of every flushed batch, e.g. to
+// collect offsets or handle errors of batches flushed in the background. fn
+// is called sequentially and should not block. Without a batch handler, the
+// error of a batch flushed in the background is returned by the next call to
+// Write, Flush or Close.
+func WithBatchHandler(fn func(BatchResult)) ProducerOption {
+	return func(p *Producer) error {
+		if fn == nil {
+			return errors.New("batch handler must not be nil")
+		}
+
+		p.onBatch = fn
+		return nil
+	}
+}
+
+// Producer buffers writes and writes them to a log in batches when the batch
+// size is reached or the linger duration has passed since the first buffered
+// record, e.g. for producers writing many small records at high frequency.
+// Each batch is written atomically, i.e. either all or no record of a batch is
+// written.
+//
+// Safe for concurrent use.
+type Producer struct {
+	log       *Log
+	linger    time.Duration
+	batchSize int
+	onBatch   func(BatchResult)
+
+	mu      sync.Mutex
+	pending []batchEntry
+	timer   Timer // linger timer, nil if no records are pending
+	err     error // unreported error of a background flush
+	closed  bool
+
+	flushMu sync.Mutex // serializes batches, acquired before mu
+}
+
+// NewProducer creates a Producer writing to the log
+func (l *Log) NewProducer(options ...ProducerOption) (*Producer, error) {
+	p := Producer{
+		log:       l,
+		linger:    DefaultLinger,
+		batchSize: DefaultBatchSize,
+	}
+
+	for _, opt := range options {
+		if err := opt(&p); err != nil {
+			return nil, fmt.Errorf("configure producer option: %v", err)
+		}
+	}
+
+	return &p, nil
+}
+
+// Write buffers a record with the given data and write options. The data and
+// options are copied, i.e. can be reused by the caller. If the batch size is
+// reached, the batch is flushed and the flush error is returned. If the error
+// of a background flush is returned, the record is not buffered.
+func (p *Producer) Write(ctx context.Context, data []byte, options ...WriteOption) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if len(data) > p.log.conf.maxRecordSize {
+		return ErrRecordTooLarge
+	}
+
+	if len(data) == 0 {
+		return errors.New("no data provided")
+	}
+
+	// capture context headers, the batch is written with another context
+	wc := newWriteConfig(options)
+	p.log.extractHeaders(ctx, &wc)
+	wc.key = copyBytes(wc.key)
+	wc.headers = copyHeaders(wc.headers)
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrProducerClosed
+	}
+
+	if err := p.takeErr(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+
+	p.pending = append(p.pending, batchEntry{
+		data:    append([]byte(nil), data...),
+		options: []WriteOption{withConfig(wc)},
+	})
+
+	if len(p.pending) < p.batchSize {
+		p.schedule()
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	return p.Flush(ctx)
+}
+
+// Flush writes all buffered records to the log. If the context is done before
+// the records are written, they stay buffered.
+func (p *Producer) Flush(ctx context.Context) error {
+	return p.flush(ctx, false)
+}
+
+// Close flushes all buffered records and rejects further writes with
+// ErrProducerClosed. If the context is done before the records are written,
+// they stay buffered and Close can be retried.
+func (p *Producer) Close(ctx context.Context) error {
+	return p.flush(ctx, true)
+}
+
+// schedule starts the linger timer unless it is running. Must be protected with
+// a lock by the caller.
+func (p *Producer) schedule() {
+	if p.timer != nil {
+		return
+	}
+
+	p.timer = p.log.clock.AfterFunc(p.linger, func() {
+		// not blocking the clock
+		go func() {
+			err := p.Flush(context.Background())
+			if err != nil && p.onBatch == nil {
+				p.mu.Lock()
+				p.err = err
+				p.mu.Unlock()
+			}
+		}()
+	})
+}
+
+// takeErr returns and clears the unreported error of a background flush. Must
+// be protected with a lock by the caller.
+func (p *Producer) takeErr() error {
+	err := p.err
+	p.err = nil
+	return err
+}
+
+// take removes and returns the pending records and stops the linger timer.
+// Must be protected with a lock by the caller.
+func (p *Producer) take() []batchEntry {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+
+	batch := p.pending
+	p.pending = nil
+	return batch
+}
+
+// flush writes the pending records as a batch. Batches are taken and written
+// while holding flushMu to preserve the order of writes.
+func (p *Producer) flush(ctx context.Context, closing bool) error {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	p.mu.Lock()
+	if closing {
+		p.closed = true
+	}
+	batch := p.take()
+	bgErr := p.takeErr()
+	p.mu.Unlock()
+
+	if len(batch) == 0 {
+		return bgErr
+	}
+
+	var offsets []Offset
+	err := p.log.lock(ctx)
+	if err == nil {
+		offsets, err = p.log.writeBatch(ctx, batch)
+		p.log.mu.Unlock()
+	}
+
+	if err != nil && ctx.Err() != nil {
+		// not written, keep the batch for the next flush
+		p.mu.Lock()
+		p.pending = append(batch, p.pending...)
+		if p.err == nil {
+			p.err = bgErr
+		}
+		if !p.closed {
+			p.schedule()
+		}
+		p.mu.Unlock()
+		return err
+	}
+
+	if err != nil {
+		err = fmt.Errorf("write batch: %w", err)
+	}
+
+	if p.onBatch != nil {
+		p.onBatch(BatchResult{
+			Offsets: offsets,
+			Records: len(batch),
+			Err:     err,
+		})
+	}
+
+	if err == nil {
+		err = bgErr
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestProducer(t *testing.T) {
+	t.Run("fails with invalid options", func(t *testing.T) {
+		l, err := New(context.Background())
+		assert.NilError(t, err)
+
+		_, err = l.NewProducer(WithLinger(0))
+		assert.ErrorContains(t, err, "greater than 0")
+
+		_, err = l.NewProducer(WithBatchSize(-1))
+		assert.ErrorContains(t, err, "greater than 0")
+
+		_, err = l.NewProducer(WithBatchHandler(nil))
+		assert.ErrorContains(t, err, "must not be nil")
+	})
+
+	t.Run("flushes on batch size", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		var results []BatchResult
+		p, err := l.NewProducer(WithBatchSize(3), WithLinger(time.Hour), WithBatchHandler(func(r BatchResult) {
+			results = append(results, r)
+		}))
+		assert.NilError(t, err)
+
+		err = p.Write(ctx, nil)
+		assert.ErrorContains(t, err, "no data")
+
+		key := []byte("key")
+		data := NewTestDataSlice(t, 7)
+		for _, d := range data {
+			assert.NilError(t, p.Write(ctx, d, WithKey(key)))
+		}
+		key[0] = 'x' // copied on write
+
+		assert.Equal(t, len(results), 2)
+		assert.DeepEqual(t, results[0].Offsets, []Offset{0, 1, 2})
+		assert.DeepEqual(t, results[1].Offsets, []Offset{3, 4, 5})
+
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(5))
+
+		assert.NilError(t, p.Close(ctx))
+		assert.Equal(t, len(results), 3)
+		assert.DeepEqual(t, results[2].Offsets, []Offset{6})
+
+		for i, d := range data {
+			r, err := l.Read(ctx, Offset(i))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, r.Data, d)
+			assert.DeepEqual(t, r.Metadata.Key, []byte("key"))
+		}
+
+		err = p.Write(ctx, data[0])
+		assert.Assert(t, errors.Is(err, ErrProducerClosed))
+	})
+
+	t.Run("flushes after linger", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		var (
+			mu      sync.Mutex
+			results []BatchResult
+		)
+		p, err := l.NewProducer(WithLinger(time.Second), WithBatchHandler(func(r BatchResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, r)
+		}))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 3) {
+			assert.NilError(t, p.Write(ctx, d))
+		}
+
+		mockClock.Add(time.Millisecond * 500)
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(-1))
+
+		mockClock.Add(time.Millisecond * 500)
+		flushed := func() []BatchResult {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]BatchResult(nil), results...)
+		}
+		for len(flushed()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		assert.Equal(t, len(flushed()), 1)
+		assert.DeepEqual(t, flushed()[0].Offsets, []Offset{0, 1, 2})
+	})
+
+	t.Run("rejects batch atomically", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		p, err := l.NewProducer(WithLinger(time.Hour))
+		assert.NilError(t, err)
+
+		assert.NilError(t, p.Write(ctx, newTestData(t, "1")))
+		assert.NilError(t, p.Write(ctx, newTestData(t, "2"), WithEpoch(1)))
+
+		err = p.Flush(ctx)
+		assert.Assert(t, errors.Is(err, ErrStaleEpoch))
+
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(-1))
+	})
+
+	t.Run("keeps batch when flush context is done", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		p, err := l.NewProducer(WithLinger(time.Hour))
+		assert.NilError(t, err)
+
+		assert.NilError(t, p.Write(ctx, newTestData(t, "1")))
+		assert.NilError(t, p.Write(ctx, newTestData(t, "2")))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		err = p.Close(cancelled)
+		assert.Assert(t, errors.Is(err, context.Canceled))
+
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(-1))
+
+		assert.NilError(t, p.Close(ctx))
+		_, latest = l.Range(ctx)
+		assert.Equal(t, latest, Offset(1))
+	})
+
+	t.Run("returns background flush error on next call", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		p, err := l.NewProducer(WithLinger(time.Second))
+		assert.NilError(t, err)
+
+		assert.NilError(t, p.Write(ctx, newTestData(t, "1"), WithEpoch(1)))
+		mockClock.Add(time.Second)
+
+		failed := func() bool {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			return p.err != nil
+		}
+		for !failed() {
+			time.Sleep(time.Millisecond)
+		}
+
+		err = p.Write(ctx, newTestData(t, "2"))
+		assert.Assert(t, errors.Is(err, ErrStaleEpoch))
+
+		// reported once, the record was not buffered
+		assert.NilError(t, p.Flush(ctx))
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(-1))
+	})
+}
@@ -0,0 +1,69 @@
+package memlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HeaderProvenance is the record header storing the provenance chain of a
+// record imported from another log, see WithProvenance()
+const HeaderProvenance = "memlog-provenance"
+
+// ProvenanceEntry describes a hop of a record passing through a mirror or
+// connector
+type ProvenanceEntry struct {
+	// LogID is the identity of the source log, see Log.ID()
+	LogID string `json:"logID"`
+	// Offset is the offset of the record in the source log
+	Offset Offset `json:"offset"`
+	// Time is the UTC time the record was imported from the source log
+	Time time.Time `json:"time"`
+}
+
+// Provenance returns the provenance chain of the record ordered from the
+// original source to the latest hop. If the record has no provenance, an
+// empty chain is returned.
+func Provenance(r Record) ([]ProvenanceEntry, error) {
+	v, ok := r.Metadata.Headers[HeaderProvenance]
+	if !ok {
+		return []ProvenanceEntry{}, nil
+	}
+
+	var chain []ProvenanceEntry
+	if err := json.Unmarshal(v, &chain); err != nil {
+		return nil, fmt.Errorf("decode provenance: %w", err)
+	}
+	return chain, nil
+}
+
+// WithProvenance appends an entry with the given source log identity (see
+// Log.ID()), the source offset and the import time to the provenance chain of
+// every imported record (see HeaderProvenance), so that consumers can trace a
+// record back through multi-hop replication, see Provenance().
+func WithProvenance(sourceID string) ImportOption {
+	return func(ic *importConfig) error {
+		if sourceID == "" {
+			return errors.New("source id must not be empty")
+		}
+
+		ic.provenance = sourceID
+		return nil
+	}
+}
+
+// appendProvenance returns the provenance header of the record extended by an
+// entry for the given hop
+func appendProvenance(r Record, entry ProvenanceEntry) ([]byte, error) {
+	chain, err := Provenance(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(append(chain, entry))
+	if err != nil {
+		return nil, fmt.Errorf("encode provenance: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,62 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestProvenance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fails with invalid input", func(t *testing.T) {
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(nil), WithProvenance(""))
+		assert.ErrorContains(t, err, "must not be empty")
+
+		_, err = Provenance(Record{Metadata: Header{Headers: map[string][]byte{HeaderProvenance: []byte("invalid")}}})
+		assert.ErrorContains(t, err, "decode provenance")
+	})
+
+	t.Run("traces record through multiple hops", func(t *testing.T) {
+		mockClock := clock.NewMock()
+
+		origin, err := New(ctx, WithClock(mockClock), WithID("origin"))
+		assert.NilError(t, err)
+		mirror, err := New(ctx, WithClock(mockClock), WithID("mirror"), WithStartOffset(100))
+		assert.NilError(t, err)
+		edge, err := New(ctx, WithClock(mockClock), WithID("edge"))
+		assert.NilError(t, err)
+
+		_, err = origin.Write(ctx, newTestData(t, "1"), WithHeader("trace", []byte("abc")))
+		assert.NilError(t, err)
+
+		r, err := origin.Read(ctx, 0)
+		assert.NilError(t, err)
+		chain, err := Provenance(r)
+		assert.NilError(t, err)
+		assert.Equal(t, len(chain), 0)
+
+		mockClock.Add(time.Second)
+		assert.NilError(t, mirror.ImportFrom(ctx, NewLogSource(origin), WithProvenance(origin.ID())))
+
+		mockClock.Add(time.Second)
+		assert.NilError(t, edge.ImportFrom(ctx, NewLogSource(mirror), WithProvenance(mirror.ID())))
+
+		r, err = edge.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Metadata.Headers["trace"], []byte("abc"))
+
+		chain, err = Provenance(r)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, chain, []ProvenanceEntry{
+			{LogID: "origin", Offset: 0, Time: time.Unix(1, 0).UTC()},
+			{LogID: "mirror", Offset: 100, Time: time.Unix(2, 0).UTC()},
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPurgeHeld is returned when a write or Trim() would purge records held by
+// a consumer, see HoldPurge()
+var ErrPurgeHeld = errors.New("purge held by consumer")
+
+// HoldPurge prevents purging records with an offset greater or equal to the
+// given offset while the named consumer processes them, e.g. a critical
+// consumer which must not miss records. Writes which would purge held records
+// fail with ErrPurgeHeld instead, i.e. the barrier applies back pressure to
+// writers. Calling HoldPurge again moves the barrier.
+//
+// The hold is a lease bound to the liveness of the consumer: it expires when
+// the consumer is considered dead (see Heartbeat() and WithConsumerTimeout()),
+// so that a dead consumer can not block retention forever. HoldPurge counts as
+// a heartbeat. Consumers are registered with their first hold. If the offset
+// has already been purged, ErrOutOfRange is returned.
+//
+// Safe for concurrent use.
+func (l *Log) HoldPurge(ctx context.Context, consumer string, upTo Offset) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if consumer == "" {
+		return errors.New("consumer name must not be empty")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if earliest, _ := l.offsetRange(); upTo < earliest || upTo < l.conf.startOffset {
+		return fmt.Errorf("%w: hold offset %d already purged", ErrOutOfRange, upTo)
+	}
+
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.register(consumer)
+	c.lastHeartbeat = l.clock.Now().UTC()
+	c.hold = upTo
+
+	return nil
+}
+
+// ReleasePurge removes the purge barrier of the named consumer, see
+// HoldPurge(). If the consumer is not registered, ErrConsumerNotFound is
+// returned.
+//
+// Safe for concurrent use.
+func (l *Log) ReleasePurge(ctx context.Context, consumer string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.consumers[consumer]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrConsumerNotFound, consumer)
+	}
+	c.hold = -1
+
+	return nil
+}
+
+// checkPurge returns ErrPurgeHeld if purging records up to and including the
+// given offset is prevented by the hold of an alive consumer. Must be
+// protected with a lock by the caller.
+func (l *Log) checkPurge(upTo Offset) error {
+	if upTo < 0 {
+		return nil
+	}
+
+	r := l.consumers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := l.clock.Now()
+	for name, c := range r.consumers {
+		if c.hold != -1 && c.hold <= upTo && r.alive(c, now) {
+			return fmt.Errorf("%w: %s holds offset %d", ErrPurgeHeld, name, c.hold)
+		}
+	}
+
+	return nil
+}
+
+// purgedBy returns the highest offset purged by writing the given number of
+// records or -1 if no record is purged. Must be protected with a lock by the
+// caller.
+func (l *Log) purgedBy(n int) Offset {
+	free := cap(l.active.data) - len(l.active.data)
+	switch {
+	case n <= free:
+		return -1
+	case n <= free+l.nextSegmentSize(l.active):
+		// history is purged when the active segment rolls
+		if l.history == nil {
+			return -1
+		}
+		return l.history.currentOffset()
+	default:
+		// the active segment is purged, too
+		return l.active.currentOffset()
+	}
+}
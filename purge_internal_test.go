@@ -0,0 +1,129 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_HoldPurge(t *testing.T) {
+	t.Run("fails with invalid arguments", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		err = l.HoldPurge(ctx, "", 0)
+		assert.ErrorContains(t, err, "must not be empty")
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		err = l.HoldPurge(ctx, "c1", 1)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		err = l.ReleasePurge(ctx, "unknown")
+		assert.Assert(t, errors.Is(err, ErrConsumerNotFound))
+	})
+
+	t.Run("blocks writes purging held records until released", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 10)
+		for _, d := range data[:4] {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.HoldPurge(ctx, "c1", 1))
+
+		consumers := l.Consumers(ctx)
+		assert.Equal(t, len(consumers), 1)
+		assert.Equal(t, consumers[0].Hold, Offset(1))
+
+		// rolling the active segment would purge offsets 0 and 1
+		_, err = l.Write(ctx, data[4])
+		assert.Assert(t, errors.Is(err, ErrPurgeHeld))
+
+		_, err = l.WriteBatch(ctx, data[4:6])
+		assert.Assert(t, errors.Is(err, ErrPurgeHeld))
+
+		err = l.Trim(ctx, 2)
+		assert.Assert(t, errors.Is(err, ErrPurgeHeld))
+
+		// records before the barrier can be trimmed
+		assert.NilError(t, l.Trim(ctx, 1))
+
+		// moving the barrier allows purging the history segment
+		assert.NilError(t, l.HoldPurge(ctx, "c1", 2))
+		_, err = l.Write(ctx, data[4])
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, data[5])
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, data[6])
+		assert.Assert(t, errors.Is(err, ErrPurgeHeld))
+
+		assert.NilError(t, l.ReleasePurge(ctx, "c1"))
+		_, err = l.Write(ctx, data[6])
+		assert.NilError(t, err)
+
+		consumers = l.Consumers(ctx)
+		assert.Equal(t, consumers[0].Hold, Offset(-1))
+	})
+
+	t.Run("blocks writes at offsets purging held records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 4)
+		for _, d := range data[:3] {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.HoldPurge(ctx, "c1", 1))
+
+		// a record fits into the active segment, but the gap rolls it, which
+		// would purge offsets 0 and 1
+		_, err = l.WriteAt(ctx, 100, data[3])
+		assert.Assert(t, errors.Is(err, ErrPurgeHeld))
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(0))
+		assert.Equal(t, latest, Offset(2))
+
+		assert.NilError(t, l.ReleasePurge(ctx, "c1"))
+		offset, err := l.WriteAt(ctx, 100, data[3])
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(100))
+	})
+
+	t.Run("hold expires with dead consumer", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(2), WithConsumerTimeout(time.Second*10))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 5)
+		for _, d := range data[:4] {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.HoldPurge(ctx, "c1", 0))
+
+		_, err = l.Write(ctx, data[4])
+		assert.Assert(t, errors.Is(err, ErrPurgeHeld))
+
+		mockClock.Add(time.Second * 11)
+		_, err = l.Write(ctx, data[4])
+		assert.NilError(t, err)
+	})
+}
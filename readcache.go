@@ -0,0 +1,190 @@
+package memlog
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// CachingReader is a Reader caching the records read from another Reader,
+// e.g. a remote log client, so that repeated reads of recent offsets by many
+// consumers are served locally. It holds up to a bounded number of records and
+// evicts the least recently used record first. If the wrapped reader
+// implements BatchReader, a cache miss fetches the following records with a
+// single call, so that sequential readers cause fewer remote fetches.
+//
+// Cached records are not refreshed, i.e. they become stale when purged,
+// deleted (see Log.Delete()) or annotated (see Log.Annotate()). Purged records
+// are invalidated with Invalidate(), e.g. driven by purge notifications of the
+// server. Deleted or annotated records must be invalidated with Remove(),
+// otherwise the scrubbed payload or changed annotations are not observed.
+// Range() and Stream() are not cached.
+//
+// Safe for concurrent use.
+type CachingReader struct {
+	reader Reader
+	size   int
+	fetch  int // records fetched on a cache miss
+
+	mu      sync.Mutex
+	entries map[Offset]*list.Element
+	lru     *list.List // front is most recently used
+	hits    int
+	misses  int
+}
+
+var _ Reader = (*CachingReader)(nil)
+
+// NewCachingReader creates a caching reader for the given reader holding up to
+// size records. On a cache miss, up to fetch records starting at the missed
+// offset are read if the reader implements BatchReader.
+func NewCachingReader(r Reader, size, fetch int) (*CachingReader, error) {
+	if r == nil {
+		return nil, errors.New("reader must not be nil")
+	}
+
+	if size <= 0 {
+		return nil, errors.New("size must be greater than 0")
+	}
+
+	if fetch <= 0 || fetch > size {
+		return nil, errors.New("fetch must be greater than 0 and not greater than size")
+	}
+
+	return &CachingReader{
+		reader:  r,
+		size:    size,
+		fetch:   fetch,
+		entries: make(map[Offset]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// Read returns the record at the given offset, reading it from the wrapped
+// reader if not cached
+func (c *CachingReader) Read(ctx context.Context, offset Offset) (Record, error) {
+	if ctx.Err() != nil {
+		return Record{}, ctx.Err()
+	}
+
+	if r, ok := c.get(offset, true); ok {
+		return r, nil
+	}
+
+	if br, ok := c.reader.(BatchReader); ok && c.fetch > 1 {
+		records, err := br.ReadBatch(ctx, offset, c.fetch)
+		if err != nil {
+			return Record{}, err
+		}
+
+		c.add(records...)
+		if r, ok := c.get(offset, false); ok {
+			return r, nil
+		}
+		// no record at the offset, e.g. a gap, read to return its error
+	}
+
+	r, err := c.reader.Read(ctx, offset)
+	if err != nil {
+		return Record{}, err
+	}
+
+	c.add(r)
+	return r.deepCopy(), nil
+}
+
+// Range returns the earliest and latest offset of the wrapped reader
+func (c *CachingReader) Range(ctx context.Context) (earliest, latest Offset) {
+	return c.reader.Range(ctx)
+}
+
+// Stream streams the records of the wrapped reader
+func (c *CachingReader) Stream(ctx context.Context, start Offset, options ...StreamOption) (<-chan StreamRecord, <-chan error) {
+	return c.reader.Stream(ctx, start, options...)
+}
+
+// Invalidate removes all cached records with an offset lower than the given
+// offset, e.g. when notified about purged records
+func (c *CachingReader) Invalidate(before Offset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for offset, e := range c.entries {
+		if offset < before {
+			c.lru.Remove(e)
+			delete(c.entries, offset)
+		}
+	}
+}
+
+// Remove removes the cached record at the given offset, e.g. when notified
+// about a deleted or annotated record
+func (c *CachingReader) Remove(offset Offset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[offset]; ok {
+		c.lru.Remove(e)
+		delete(c.entries, offset)
+	}
+}
+
+// Len returns the number of cached records
+func (c *CachingReader) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Stats returns the number of cache hits and misses
+func (c *CachingReader) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses
+}
+
+// get returns a copy of the cached record at the given offset. Only counted
+// lookups are reported in Stats().
+func (c *CachingReader) get(offset Offset, count bool) (Record, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[offset]
+	if count {
+		if !ok {
+			c.misses++
+		} else {
+			c.hits++
+		}
+	}
+	if !ok {
+		return Record{}, false
+	}
+
+	c.lru.MoveToFront(e)
+	return e.Value.(Record).deepCopy(), true
+}
+
+// add caches the given records
+func (c *CachingReader) add(records ...Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range records {
+		if e, ok := c.entries[r.Metadata.Offset]; ok {
+			// read concurrently
+			c.lru.MoveToFront(e)
+			continue
+		}
+
+		c.entries[r.Metadata.Offset] = c.lru.PushFront(r)
+		if c.lru.Len() > c.size {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(Record).Metadata.Offset)
+		}
+	}
+}
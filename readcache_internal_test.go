@@ -0,0 +1,175 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fetchCountingReader counts the reads of the wrapped log
+type fetchCountingReader struct {
+	*Log
+	reads   int
+	batches int
+}
+
+func (r *fetchCountingReader) Read(ctx context.Context, offset Offset) (Record, error) {
+	r.reads++
+	return r.Log.Read(ctx, offset)
+}
+
+func (r *fetchCountingReader) ReadBatch(ctx context.Context, start Offset, max int) ([]Record, error) {
+	r.batches++
+	return r.Log.ReadBatch(ctx, start, max)
+}
+
+func TestNewCachingReader(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name    string
+		reader  Reader
+		size    int
+		fetch   int
+		wantErr string
+	}{
+		{name: "valid", reader: l, size: 10, fetch: 5},
+		{name: "nil reader", reader: nil, size: 10, fetch: 5, wantErr: "must not be nil"},
+		{name: "invalid size", reader: l, size: 0, fetch: 1, wantErr: "size must be greater than 0"},
+		{name: "fetch greater than size", reader: l, size: 5, fetch: 10, wantErr: "fetch must be"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCachingReader(tc.reader, tc.size, tc.fetch)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestCachingReader_Read(t *testing.T) {
+	setup := func(t *testing.T, options ...Option) (context.Context, *fetchCountingReader) {
+		ctx := context.Background()
+		l, err := New(ctx, options...)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		return ctx, &fetchCountingReader{Log: l}
+	}
+
+	t.Run("fetches batches and serves repeated reads locally", func(t *testing.T) {
+		ctx, r := setup(t)
+		c, err := NewCachingReader(r, 8, 4)
+		assert.NilError(t, err)
+
+		for i := 0; i < 3; i++ {
+			for offset := Offset(0); offset < 8; offset++ {
+				got, err := c.Read(ctx, offset)
+				assert.NilError(t, err)
+
+				want, err := r.Log.Read(ctx, offset)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, got, want)
+			}
+		}
+
+		assert.Equal(t, r.batches, 2)
+		assert.Equal(t, r.reads, 0)
+		assert.Equal(t, c.Len(), 8)
+
+		hits, misses := c.Stats()
+		assert.Equal(t, hits, 22)
+		assert.Equal(t, misses, 2)
+	})
+
+	t.Run("reads single records without batch reader", func(t *testing.T) {
+		ctx, r := setup(t)
+		c, err := NewCachingReader(r.Log, 2, 1)
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{0, 1, 0, 2, 1} {
+			_, err = c.Read(ctx, offset)
+			assert.NilError(t, err)
+		}
+
+		hits, misses := c.Stats()
+		assert.Equal(t, hits, 1)
+		assert.Equal(t, misses, 4)
+		assert.Equal(t, c.Len(), 2)
+	})
+
+	t.Run("returns errors of the wrapped reader", func(t *testing.T) {
+		ctx, r := setup(t, WithMaxSegmentSize(4))
+		c, err := NewCachingReader(r, 8, 4)
+		assert.NilError(t, err)
+
+		_, err = c.Read(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		_, err = c.Read(ctx, 10)
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	})
+
+	t.Run("cached records are copies", func(t *testing.T) {
+		ctx, r := setup(t)
+		c, err := NewCachingReader(r, 8, 4)
+		assert.NilError(t, err)
+
+		got, err := c.Read(ctx, 0)
+		assert.NilError(t, err)
+		got.Data[0] = 'x'
+
+		got, err = c.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Assert(t, got.Data[0] != 'x')
+	})
+
+	t.Run("invalidates purged records", func(t *testing.T) {
+		ctx, r := setup(t)
+		c, err := NewCachingReader(r, 8, 4)
+		assert.NilError(t, err)
+
+		_, err = c.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Equal(t, c.Len(), 4)
+
+		c.Invalidate(2)
+		assert.Equal(t, c.Len(), 2)
+
+		earliest, latest := c.Range(ctx)
+		assert.Equal(t, earliest, Offset(0))
+		assert.Equal(t, latest, Offset(9))
+	})
+	t.Run("removes deleted records", func(t *testing.T) {
+		ctx, r := setup(t)
+		c, err := NewCachingReader(r, 8, 4)
+		assert.NilError(t, err)
+
+		_, err = c.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.NilError(t, r.Delete(ctx, 1))
+
+		// stale until removed
+		got, err := c.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.Assert(t, !IsTombstone(got))
+
+		c.Remove(1)
+		assert.Equal(t, c.Len(), 3)
+
+		got, err = c.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.Assert(t, IsTombstone(got))
+	})
+}
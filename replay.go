@@ -0,0 +1,101 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// replayBatchSize is the number of records read under a single lock
+// acquisition by a replay
+const replayBatchSize = 64
+
+// Replay re-delivers the records of the log starting at the given offset at
+// their original inter-record timing based on their creation time, e.g. for
+// realistic load replays against downstream systems. The timing is scaled by
+// the given speed, e.g. 2 replays twice as fast as the records were written.
+// Delays are measured with the clock of the log. A slow receiver delays the
+// delivery of a record but not the schedule of the following records.
+//
+// Records up to the latest offset at the time of the call are replayed. When
+// all records have been delivered, both channels are closed without an error.
+// Otherwise, the terminating error is sent on the returned error channel,
+// e.g. when the replayed records have been purged (ErrOutOfRange) or the
+// context is cancelled, and both channels are closed afterwards.
+//
+// Safe for concurrent use.
+func (l *Log) Replay(ctx context.Context, start Offset, speed float64) (<-chan StreamRecord, <-chan error) {
+	var (
+		recordCh = make(chan StreamRecord)
+		errCh    = make(chan error)
+	)
+
+	l.mu.RLock()
+	end := l.offset
+	l.mu.RUnlock()
+
+	go func() {
+		defer func() {
+			close(recordCh)
+			close(errCh)
+		}()
+
+		if speed <= 0 {
+			errCh <- errors.New("speed must be greater than 0")
+			return
+		}
+
+		var (
+			first time.Time // creation time of the first record
+			begin time.Time // delivery time of the first record
+		)
+
+		offset := start
+		for offset < end {
+			records, err := l.ReadBatch(ctx, offset, replayBatchSize)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			earliest, latest := l.Range(ctx)
+			for _, r := range records {
+				if r.Metadata.Offset >= end {
+					return
+				}
+
+				created := r.Metadata.Created
+				if first.IsZero() {
+					first = created
+					begin = l.clock.Now()
+				}
+
+				due := begin.Add(time.Duration(float64(created.Sub(first)) / speed))
+				if err = sleep(ctx, l.clock, due.Sub(l.clock.Now())); err != nil {
+					errCh <- err
+					return
+				}
+
+				rec := StreamRecord{
+					Metadata: StreamHeader{Earliest: earliest, Latest: latest},
+					Record:   r,
+				}
+
+				select {
+				case recordCh <- rec:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+
+			if len(records) == 0 {
+				// only gaps until the end of the log
+				return
+			}
+			offset = records[len(records)-1].Metadata.Offset + 1
+		}
+	}()
+
+	return recordCh, errCh
+}
@@ -0,0 +1,90 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Replay(t *testing.T) {
+	t.Run("fails with invalid speed", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, errCh := l.Replay(ctx, 0, 0)
+		assert.ErrorContains(t, <-errCh, "speed must be greater than 0")
+	})
+
+	t.Run("fails with purged offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		_, errCh := l.Replay(ctx, 0, 1)
+		assert.Assert(t, errors.Is(<-errCh, ErrOutOfRange))
+	})
+
+	t.Run("replays records at original timing with speed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		// inter-record delays 1s and 2s
+		for _, d := range []time.Duration{0, time.Second, 2 * time.Second} {
+			mockClock.Add(d)
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		// advance the mock clock in the background
+		go func() {
+			ticker := time.NewTicker(time.Millisecond)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					mockClock.Add(10 * time.Millisecond)
+				}
+			}
+		}()
+
+		// records written after the call are not replayed
+		recordCh, errCh := l.Replay(ctx, 0, 2)
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		var (
+			begin time.Time
+			want  = []time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond}
+		)
+		for i, delay := range want {
+			r := <-recordCh
+			now := mockClock.Now()
+			assert.Equal(t, r.Record.Metadata.Offset, Offset(i))
+
+			if i == 0 {
+				begin = now
+				continue
+			}
+			assert.Assert(t, now.Sub(begin) >= delay, "record %d delivered after %v, want %v", i, now.Sub(begin), delay)
+		}
+
+		_, ok := <-recordCh
+		assert.Assert(t, !ok)
+		assert.NilError(t, <-errCh)
+	})
+}
@@ -0,0 +1,109 @@
+package memlog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithMaxRecordAge purges records older than the given age in addition to the
+// segment size based purging, e.g. to retain the records of the last 15
+// minutes. The age of a record is measured from its creation time with the
+// clock of the log. Records exceeding the age are no longer readable (see
+// ErrExpired) and purged with the next write or PurgeExpired(). Combined with
+// WithTTL(), a record expires at the earlier of both times.
+func WithMaxRecordAge(d time.Duration) Option {
+	return func(log *Log) error {
+		if d <= 0 {
+			return errors.New("max record age must be greater than 0")
+		}
+
+		log.conf.maxAge = d
+		return nil
+	}
+}
+
+// purgeExpiredPrefix purges the expired records at the beginning of the log,
+// i.e. advances the earliest offset past records exceeding their TTL or the
+// maximum record age. Records held by a consumer (see HoldPurge()) are not
+// purged. Must be protected with a lock by the caller.
+func (l *Log) purgeExpiredPrefix() error {
+	if !l.expiring || l.isEmpty() {
+		return nil
+	}
+
+	earliest, _ := l.offsetRange()
+	before := l.nextWritten(earliest)
+	if before <= earliest || l.checkPurge(before-1) != nil {
+		return nil
+	}
+
+	return l.trim(before)
+}
+
+// WithMaxLogBytes bounds the total payload bytes retained in the log (see
+// RetainedBytes()) in addition to the segment size based purging, e.g. when
+// payload sizes vary widely. When a write exceeds the limit, the oldest
+// records are purged until the log is within the limit again, unless specified
+// otherwise with WithEvictionStrategy(). The latest record is always retained,
+// i.e. records larger than the limit are rejected with ErrRecordTooLarge.
+// Records held by a consumer (see HoldPurge()) are not purged, i.e. the limit
+// can be exceeded until the hold is released.
+func WithMaxLogBytes(n int64) Option {
+	return func(log *Log) error {
+		if n <= 0 {
+			return errors.New("max log bytes must be greater than 0")
+		}
+
+		log.conf.maxBytes = n
+		return nil
+	}
+}
+
+// enforceMaxBytes purges the oldest records or evicts records in the order of
+// the eviction strategy (see WithEvictionStrategy()) until the retained bytes
+// are within the configured limit. Must be protected with a lock by the
+// caller.
+func (l *Log) enforceMaxBytes() error {
+	earliest, latest := l.offsetRange()
+	if l.eviction != nil {
+		l.eviction.prune(earliest, int(latest-earliest+1))
+	}
+
+	excess := int64(l.retainedBytes()) - l.conf.maxBytes
+	if l.conf.maxBytes == 0 || excess <= 0 {
+		return nil
+	}
+
+	if l.eviction != nil {
+		if _, ok := l.eviction.strategy.(oldestFirst); !ok {
+			if err := l.evict(excess); err != nil {
+				return fmt.Errorf("enforce max log bytes: %w", err)
+			}
+			return nil
+		}
+	}
+
+	before := earliest
+	for before < latest && excess > 0 {
+		s, err := l.getSegment(before)
+		if err != nil {
+			// history segment ends before the active segment starts
+			before = l.active.start
+			continue
+		}
+
+		excess -= int64(s.payloadSize(before))
+		before++
+	}
+
+	if l.checkPurge(before-1) != nil {
+		// held, exceed the limit until the hold is released
+		return nil
+	}
+
+	if err := l.trim(before); err != nil {
+		return fmt.Errorf("enforce max log bytes: %w", err)
+	}
+	return nil
+}
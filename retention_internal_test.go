@@ -0,0 +1,170 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithMaxRecordAge(t *testing.T) {
+	t.Run("fails with invalid age", func(t *testing.T) {
+		_, err := New(context.Background(), WithMaxRecordAge(0))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("purges records older than max age", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxRecordAge(time.Minute*15), WithMaxSegmentSize(10))
+		assert.NilError(t, err)
+
+		// one record per minute
+		for i := 0; i < 10; i++ {
+			_, err = l.Write(ctx, newTestData(t, "data"))
+			assert.NilError(t, err)
+			mockClock.Add(time.Minute)
+		}
+
+		mockClock.Add(time.Minute * 7)
+
+		// records older than 15 minutes are unreadable before the next write
+		_, err = l.Read(ctx, 1)
+		assert.Assert(t, errors.Is(err, ErrExpired))
+
+		r, err := l.ReadEarliest(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(3))
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(3))
+		assert.Equal(t, latest, Offset(10))
+
+		_, err = l.Read(ctx, 2)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		// all records expire
+		mockClock.Add(time.Hour)
+		n, err := l.PurgeExpired(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 8)
+		assert.Equal(t, l.RetainedBytes(), 0)
+	})
+
+	t.Run("ttl shorter than max age", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxRecordAge(time.Minute))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"), WithTTL(time.Second))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, newTestData(t, "data"), WithTTL(time.Hour))
+		assert.NilError(t, err)
+
+		r, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Expires, mockClock.Now().UTC().Add(time.Second))
+
+		r, err = l.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Expires, mockClock.Now().UTC().Add(time.Minute))
+	})
+
+	t.Run("does not purge held records", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxRecordAge(time.Minute), WithConsumerTimeout(time.Hour))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+		assert.NilError(t, l.HoldPurge(ctx, "c1", 0))
+
+		mockClock.Add(time.Minute * 2)
+		_, err = l.Write(ctx, newTestData(t, "data"))
+		assert.NilError(t, err)
+
+		earliest, _ := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(0))
+	})
+}
+
+func TestWithMaxLogBytes(t *testing.T) {
+	t.Run("fails with invalid limit", func(t *testing.T) {
+		_, err := New(context.Background(), WithMaxLogBytes(0))
+		assert.ErrorContains(t, err, "greater than 0")
+	})
+
+	t.Run("rejects records larger than the limit", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxLogBytes(4))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("12345"))
+		assert.Assert(t, errors.Is(err, ErrRecordTooLarge))
+	})
+
+	t.Run("purges oldest records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxLogBytes(100), WithMaxSegmentSize(4))
+		assert.NilError(t, err)
+
+		small, large := make([]byte, 10), make([]byte, 60)
+		for _, d := range [][]byte{small, small, small, small, small} {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.Equal(t, l.RetainedBytes(), 50)
+
+		// exceeds the limit by 10 bytes
+		_, err = l.Write(ctx, large)
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 100)
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(1))
+		assert.Equal(t, latest, Offset(5))
+
+		// purges the history segment and trims the active segment
+		_, err = l.Write(ctx, large)
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 60)
+
+		earliest, latest = l.Range(ctx)
+		assert.Equal(t, earliest, Offset(6))
+		assert.Equal(t, latest, Offset(6))
+	})
+
+	t.Run("purges oldest records of compressed segment", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxLogBytes(100), WithMaxSegmentSize(4))
+		assert.NilError(t, err)
+
+		for i := 0; i < 6; i++ {
+			_, err = l.Write(ctx, make([]byte, 10))
+			assert.NilError(t, err)
+		}
+
+		mockClock.Add(time.Minute * 2)
+		n, err := l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 1)
+
+		// exceeds the limit by 5 bytes
+		_, err = l.Write(ctx, make([]byte, 45))
+		assert.NilError(t, err)
+		assert.Equal(t, l.RetainedBytes(), 95)
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(1))
+		assert.Equal(t, latest, Offset(6))
+	})
+}
@@ -0,0 +1,200 @@
+package memlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// Matcher matches records, e.g. to search a log with Search()
+type Matcher interface {
+	// Match returns true if the record matches
+	Match(r Record) (bool, error)
+}
+
+// MatcherFunc is an adapter to use a function as a Matcher
+type MatcherFunc func(r Record) (bool, error)
+
+// Match calls fn(r)
+func (fn MatcherFunc) Match(r Record) (bool, error) {
+	return fn(r)
+}
+
+// SubstringMatcher matches records with data containing the given substring
+func SubstringMatcher(substr string) Matcher {
+	b := []byte(substr)
+	return MatcherFunc(func(r Record) (bool, error) {
+		return bytes.Contains(r.Data, b), nil
+	})
+}
+
+// RegexpMatcher matches records with data matching the given regular
+// expression
+func RegexpMatcher(re *regexp.Regexp) Matcher {
+	return MatcherFunc(func(r Record) (bool, error) {
+		return re.Match(r.Data), nil
+	})
+}
+
+// JSONFieldMatcher matches records with JSON data where the value selected
+// by the given JSONPath expression (see ParseJSONPath()) equals value, e.g.
+// "order.id". Value is compared to the selected value after JSON encoding and
+// decoding, i.e. numbers match regardless of their Go type. Records without
+// valid JSON data or the selected value do not match.
+func JSONFieldMatcher(path string, value interface{}) (Matcher, error) {
+	if path == "" {
+		return nil, errors.New("path must not be empty")
+	}
+
+	p, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshal value: %w", err)
+	}
+
+	var want interface{}
+	if err = json.Unmarshal(b, &want); err != nil {
+		return nil, fmt.Errorf("unmarshal value: %w", err)
+	}
+
+	return MatcherFunc(func(r Record) (bool, error) {
+		got, ok := p.Eval(r.Data)
+		if !ok {
+			return false, nil
+		}
+
+		return reflect.DeepEqual(got, want), nil
+	}), nil
+}
+
+// SearchOption customizes Search
+type SearchOption func(*searchConfig) error
+
+type searchConfig struct {
+	offsets       bool // offset window set
+	from, to      Offset
+	times         bool // time window set
+	after, before time.Time
+	limit         int
+}
+
+// WithSearchOffsets limits the search to records with offsets between from and
+// to (inclusive)
+func WithSearchOffsets(from, to Offset) SearchOption {
+	return func(sc *searchConfig) error {
+		if from < 0 || to < from {
+			return errors.New("invalid offset range")
+		}
+
+		sc.offsets = true
+		sc.from = from
+		sc.to = to
+		return nil
+	}
+}
+
+// WithSearchTime limits the search to records created between after and
+// before (inclusive)
+func WithSearchTime(after, before time.Time) SearchOption {
+	return func(sc *searchConfig) error {
+		if before.Before(after) {
+			return errors.New("invalid time range")
+		}
+
+		sc.times = true
+		sc.after = after
+		sc.before = before
+		return nil
+	}
+}
+
+// WithSearchLimit stops the search after n matching records
+func WithSearchLimit(n int) SearchOption {
+	return func(sc *searchConfig) error {
+		if n <= 0 {
+			return errors.New("limit must be greater than 0")
+		}
+
+		sc.limit = n
+		return nil
+	}
+}
+
+// Search returns the offsets of records matching the matcher in ascending
+// order. By default all records retained in the log are searched. The search
+// window can be bounded with WithSearchOffsets() and WithSearchTime(). The
+// first error returned by the matcher terminates the search.
+//
+// Concurrent writes are blocked during the search, so the search window should
+// be bounded for large logs.
+//
+// Safe for concurrent use.
+func (l *Log) Search(ctx context.Context, matcher Matcher, options ...SearchOption) ([]Offset, error) {
+	if matcher == nil {
+		return nil, errors.New("matcher must not be nil")
+	}
+
+	var sc searchConfig
+	for _, opt := range options {
+		if err := opt(&sc); err != nil {
+			return nil, fmt.Errorf("configure search option: %v", err)
+		}
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	earliest, latest := l.offsetRange()
+	if latest == -1 {
+		return []Offset{}, nil
+	}
+
+	from, to := earliest, latest
+	if sc.offsets {
+		if sc.from > from {
+			from = sc.from
+		}
+		if sc.to < to {
+			to = sc.to
+		}
+	}
+
+	matches := []Offset{}
+	for offset := from; offset <= to; offset++ {
+		r, err := l.read(ctx, offset)
+		if errors.Is(err, ErrOffsetGap) {
+			offset = l.nextWritten(offset) - 1
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if sc.times && (r.Metadata.Created.Before(sc.after) || r.Metadata.Created.After(sc.before)) {
+			continue
+		}
+
+		ok, err := matcher.Match(r)
+		if err != nil {
+			return nil, fmt.Errorf("match record at offset %d: %w", offset, err)
+		}
+
+		if ok {
+			matches = append(matches, offset)
+			if sc.limit > 0 && len(matches) == sc.limit {
+				break
+			}
+		}
+	}
+
+	return matches, nil
+}
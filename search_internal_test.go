@@ -0,0 +1,89 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Search(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clock.NewMock()
+	start := mockClock.Now()
+
+	l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(5))
+	assert.NilError(t, err)
+
+	for i := 0; i < 8; i++ {
+		data := fmt.Sprintf(`{"order":{"id":"order-%d","amount":%d}}`, 1230+i, i*10)
+		if i == 3 {
+			data = "not json order-1234"
+		}
+
+		_, err = l.Write(ctx, []byte(data))
+		assert.NilError(t, err)
+		mockClock.Add(time.Minute)
+	}
+
+	jsonID, err := JSONFieldMatcher("order.id", "order-1234")
+	assert.NilError(t, err)
+
+	jsonAmount, err := JSONFieldMatcher("order.amount", 50)
+	assert.NilError(t, err)
+
+	jsonMissing, err := JSONFieldMatcher("order.id.value", "order-1234")
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name    string
+		matcher Matcher
+		options []SearchOption
+		want    []Offset
+		wantErr string
+	}{
+		{name: "fails with nil matcher", wantErr: "must not be nil"},
+		{name: "fails with invalid offset range", matcher: SubstringMatcher("order"), options: []SearchOption{WithSearchOffsets(5, 2)}, wantErr: "invalid offset range"},
+		{name: "fails with invalid time range", matcher: SubstringMatcher("order"), options: []SearchOption{WithSearchTime(start.Add(time.Hour), start)}, wantErr: "invalid time range"},
+		{name: "fails with invalid limit", matcher: SubstringMatcher("order"), options: []SearchOption{WithSearchLimit(0)}, wantErr: "greater than 0"},
+		{name: "substring", matcher: SubstringMatcher("order-1234"), want: []Offset{3, 4}},
+		{name: "substring without match", matcher: SubstringMatcher("order-9999"), want: []Offset{}},
+		{name: "regexp", matcher: RegexpMatcher(regexp.MustCompile(`order-123[67]`)), want: []Offset{6, 7}},
+		{name: "json field", matcher: jsonID, want: []Offset{4}},
+		{name: "json number field", matcher: jsonAmount, want: []Offset{5}},
+		{name: "json missing field", matcher: jsonMissing, want: []Offset{}},
+		{name: "offset window", matcher: SubstringMatcher("order"), options: []SearchOption{WithSearchOffsets(2, 4)}, want: []Offset{2, 3, 4}},
+		{name: "time window", matcher: SubstringMatcher("order"), options: []SearchOption{WithSearchTime(start.Add(time.Minute*5), start.Add(time.Hour))}, want: []Offset{5, 6, 7}},
+		{name: "limit", matcher: SubstringMatcher("order"), options: []SearchOption{WithSearchLimit(2)}, want: []Offset{0, 1}},
+		{
+			name: "matcher error",
+			matcher: MatcherFunc(func(r Record) (bool, error) {
+				return false, errors.New("boom")
+			}),
+			wantErr: "match record at offset 0: boom",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := l.Search(ctx, tc.matcher, tc.options...)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tc.want)
+		})
+	}
+
+	t.Run("json matcher fails with empty path", func(t *testing.T) {
+		_, err := JSONFieldMatcher("", "value")
+		assert.ErrorContains(t, err, "must not be empty")
+	})
+}
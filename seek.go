@@ -0,0 +1,64 @@
+package memlog
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// OffsetAfter returns the offset of the first record retained in the log which
+// was created at or after the given time, e.g. to resume a consumer without a
+// checkpoint "from 5 minutes ago". If no such record exists, the next write
+// offset is returned, i.e. a stream starting at the returned offset delivers
+// all records created at or after the given time. If t is before the
+// creation time of the earliest retained record, the earliest offset is
+// returned.
+//
+// The offset is found with a binary search over creation times, which are
+// non-decreasing for records written with the clock of the log. For records
+// imported with earlier creation times, the result is approximate.
+//
+// Safe for concurrent use.
+func (l *Log) OffsetAfter(ctx context.Context, t time.Time) (Offset, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+
+	for _, s := range []*segment{l.history, l.active} {
+		if s == nil {
+			continue
+		}
+
+		if i, ok := s.searchCreated(t); ok {
+			return s.start + Offset(i), nil
+		}
+	}
+
+	return l.offset, nil
+}
+
+// searchCreated returns the index of the first record of the segment created
+// at or after the given time. If there is none, false is returned. Gaps are
+// skipped. Must be protected with a lock on the log by the caller.
+func (s *segment) searchCreated(t time.Time) (int, bool) {
+	// index of the first record at or after i or len(s.data) if none
+	nextWritten := func(i int) int {
+		for ; i < len(s.data); i++ {
+			if s.data[i].Metadata.Offset == s.start+Offset(i) {
+				return i
+			}
+		}
+		return i
+	}
+
+	i := sort.Search(len(s.data), func(i int) bool {
+		j := nextWritten(i)
+		return j == len(s.data) || !s.data[j].Metadata.Created.Before(t)
+	})
+
+	i = nextWritten(i)
+	return i, i < len(s.data)
+}
@@ -0,0 +1,73 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_OffsetAfter(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clock.NewMock()
+	start := mockClock.Now()
+
+	// records created every minute starting at 1m, offsets 0-9 purged
+	l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(10))
+	assert.NilError(t, err)
+
+	for _, d := range NewTestDataSlice(t, 25) {
+		mockClock.Add(time.Minute)
+		_, err = l.Write(ctx, d)
+		assert.NilError(t, err)
+	}
+
+	testCases := []struct {
+		name string
+		time time.Time
+		want Offset
+	}{
+		{name: "before earliest record", time: start, want: 10},
+		{name: "exact creation time in history", time: start.Add(12 * time.Minute), want: 11},
+		{name: "between creation times", time: start.Add(12*time.Minute + time.Second), want: 12},
+		{name: "exact creation time in active segment", time: start.Add(23 * time.Minute), want: 22},
+		{name: "latest record", time: start.Add(25 * time.Minute), want: 24},
+		{name: "after latest record", time: start.Add(time.Hour), want: 25},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			offset, err := l.OffsetAfter(ctx, tc.time)
+			assert.NilError(t, err)
+			assert.Equal(t, offset, tc.want)
+		})
+	}
+
+	t.Run("empty log", func(t *testing.T) {
+		l, err := New(ctx, WithStartOffset(5))
+		assert.NilError(t, err)
+
+		offset, err := l.OffsetAfter(ctx, start)
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+	})
+
+	t.Run("skips gaps", func(t *testing.T) {
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{0, 4, 5} {
+			mockClock.Add(time.Minute)
+			_, err = l.WriteAt(ctx, offset, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		offset, err := l.OffsetAfter(ctx, mockClock.Now().Add(-time.Minute-time.Second))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(4))
+	})
+}
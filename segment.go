@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 )
 
 var (
@@ -17,6 +19,13 @@ type segment struct {
 	start  Offset // logical start offset
 	sealed bool   // false set segment to read-only
 	data   []Record
+	bytes  int    // payload bytes of written records
+	arena  *arena // optional, off-heap payload memory
+
+	cmu        sync.Mutex       // protects reads, lastRead and compressed
+	reads      uint64           // number of read accesses
+	lastRead   time.Time        // last read access
+	compressed *segmentPayloads // compressed payloads of idle segment
 }
 
 func newSegment(startOffset Offset, size int) (*segment, error) {
@@ -50,6 +59,7 @@ func (s *segment) write(ctx context.Context, r Record) error {
 	}
 
 	s.data = append(s.data, r)
+	s.bytes += len(r.Data)
 	return nil
 }
 
@@ -67,6 +77,32 @@ func (s *segment) read(ctx context.Context, offset Offset) (Record, error) {
 	return s.data[index], nil
 }
 
+// full returns true if no more records can be written to the segment
+func (s *segment) full() bool {
+	return len(s.data) == cap(s.data)
+}
+
+// written returns the number of records in the segment, excluding gaps of logs
+// with sparse offsets
+func (s *segment) written() int {
+	var n int
+	for i := range s.data {
+		if s.data[i].Metadata.Offset == s.start+Offset(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// live returns true if the slot at the given offset holds a record which has
+// not expired at the given time (see expired()). Only the metadata of the
+// record is accessed, i.e. scanning readers holding a read lock on the log do
+// not race with payloads decompressed by touch().
+func (s *segment) live(offset Offset, now time.Time) bool {
+	h := &s.data[offset-s.start].Metadata
+	return h.Offset == offset && !expired(*h, now)
+}
+
 // seal closes a segment and sets it to read-only
 func (s *segment) seal() {
 	s.sealed = true
@@ -83,3 +119,64 @@ func (s *segment) currentOffset() Offset {
 	offset := s.start + Offset(len(s.data)) - 1
 	return offset
 }
+
+// SegmentInfo describes a segment of the log, e.g. to decide which segments to
+// compress, see Segments() and CompressSegments()
+type SegmentInfo struct {
+	// Start is the offset of the first slot of the segment
+	Start Offset `json:"start"`
+	// End is the offset of the last written slot of the segment or -1 if the
+	// segment is empty
+	End Offset `json:"end"`
+	// Records is the number of written slots of the segment, including gaps of
+	// logs with sparse offsets
+	Records int `json:"records"`
+	// Capacity is the maximum number of slots of the segment
+	Capacity int `json:"capacity"`
+	// Bytes is the number of payload bytes of the records of the segment
+	Bytes int `json:"bytes"`
+	// Sealed is true if the segment is read-only, i.e. the history segment
+	Sealed bool `json:"sealed"`
+	// Compressed is true if the payloads of the segment are compressed
+	Compressed bool `json:"compressed"`
+	// Reads is the number of reads of records of the segment
+	Reads uint64 `json:"reads"`
+	// LastRead is the time of the last read of the segment or the creation time
+	// of the segment if it has not been read
+	LastRead time.Time `json:"lastRead"`
+}
+
+// info returns the description of the segment. Must be protected with a lock
+// on the log by the caller.
+func (s *segment) info() SegmentInfo {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+
+	return SegmentInfo{
+		Start:      s.start,
+		End:        s.currentOffset(),
+		Records:    len(s.data),
+		Capacity:   cap(s.data),
+		Bytes:      s.bytes,
+		Sealed:     s.sealed,
+		Compressed: s.compressed != nil,
+		Reads:      s.reads,
+		LastRead:   s.lastRead,
+	}
+}
+
+// Segments returns the description of the retained segments of the log
+// ordered by offset, i.e. the history segment, if any, followed by the active
+// segment
+//
+// Safe for concurrent use.
+func (l *Log) Segments(_ context.Context) []SegmentInfo {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	segments := make([]SegmentInfo, 0, 2)
+	if l.history != nil {
+		segments = append(segments, l.history.info())
+	}
+	return append(segments, l.active.info())
+}
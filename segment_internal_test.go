@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"gotest.tools/v3/assert"
 )
 
@@ -250,3 +251,31 @@ func TestSegment_Write(t *testing.T) {
 		assert.DeepEqual(t, testRecords, resRecords)
 	})
 }
+
+func TestLog_Segments(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clock.NewMock()
+	l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(3))
+	assert.NilError(t, err)
+
+	created := mockClock.Now()
+	assert.DeepEqual(t, l.Segments(ctx), []SegmentInfo{
+		{Start: 0, End: -1, Capacity: 3, LastRead: created},
+	})
+
+	for _, d := range []string{"a", "bb", "ccc", "dddd"} {
+		_, err = l.Write(ctx, []byte(d))
+		assert.NilError(t, err)
+	}
+
+	mockClock.Add(time.Minute)
+	for _, offset := range []Offset{0, 1, 3} {
+		_, err = l.Read(ctx, offset)
+		assert.NilError(t, err)
+	}
+
+	assert.DeepEqual(t, l.Segments(ctx), []SegmentInfo{
+		{Start: 0, End: 2, Records: 3, Capacity: 3, Bytes: 6, Sealed: true, Reads: 2, LastRead: created.Add(time.Minute)},
+		{Start: 3, End: 3, Records: 1, Capacity: 3, Bytes: 4, Reads: 1, LastRead: created.Add(time.Minute)},
+	})
+}
@@ -0,0 +1,203 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SelectSource is a log merged by SelectFrom() and the offset to start reading
+// from
+type SelectSource struct {
+	Log   *Log
+	Start Offset
+}
+
+// SelectRecord is a record delivered by Select() or SelectFrom()
+type SelectRecord struct {
+	// Source is the index of the log the record was read from
+	Source int
+	Record Record
+}
+
+// Select merges the records of the given logs into a single stream ordered by
+// record creation time, starting at the earliest offset of each log. See
+// SelectFrom() for details.
+func Select(ctx context.Context, logs ...*Log) (<-chan SelectRecord, <-chan error) {
+	sources := make([]SelectSource, 0, len(logs))
+	for _, l := range logs {
+		if l == nil {
+			sources = append(sources, SelectSource{})
+			continue
+		}
+
+		earliest, _ := l.Range(ctx)
+		if earliest == -1 {
+			l.mu.RLock()
+			earliest = l.offset
+			l.mu.RUnlock()
+		}
+		sources = append(sources, SelectSource{Log: l, Start: earliest})
+	}
+
+	return SelectFrom(ctx, sources...)
+}
+
+// SelectFrom merges the records of the given sources into a single stream
+// ordered by record creation time, e.g. for consumers which must process
+// several logs in causal order. Records with the same creation time are
+// ordered by source index. Each source is read starting at its start offset,
+// i.e. a consumer resumes by tracking the offset of the last record delivered
+// per source (see SelectRecord).
+//
+// A record is delivered only once every other source has either a record
+// created later or no record written before the record was created. This
+// requires all logs to share the same clock and records created by the log,
+// i.e. not imported with their original creation time.
+//
+// The stream is terminated with an error when the context is cancelled or a
+// source can not be read, e.g. because the offset has been purged
+// (ErrOutOfRange). The error is sent on the returned error channel and both
+// channels are closed afterwards.
+func SelectFrom(ctx context.Context, sources ...SelectSource) (<-chan SelectRecord, <-chan error) {
+	var (
+		selectCh = make(chan SelectRecord, streamBuffer)
+		errCh    = make(chan error)
+	)
+
+	go func() {
+		defer func() {
+			close(selectCh)
+			close(errCh)
+		}()
+
+		if len(sources) == 0 {
+			errCh <- errors.New("no sources provided")
+			return
+		}
+
+		m := merger{sources: make([]mergeSource, len(sources))}
+		for i, s := range sources {
+			if s.Log == nil {
+				errCh <- fmt.Errorf("source %d: log must not be nil", i)
+				return
+			}
+			m.sources[i] = mergeSource{log: s.Log, next: s.Start}
+		}
+
+		// polled with the clock of the first source
+		ticker := sources[0].Log.clock.NewTicker(streamPollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := m.poll(ctx); err != nil {
+				errCh <- err
+				return
+			}
+
+			if r, ok := m.pop(); ok {
+				select {
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				case selectCh <- r:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			case <-ticker.Chan():
+			}
+		}
+	}()
+
+	return selectCh, errCh
+}
+
+// merger merges the records of multiple logs ordered by creation time
+type merger struct {
+	sources []mergeSource
+}
+
+type mergeSource struct {
+	log  *Log
+	next Offset // next offset to read
+
+	head   *Record   // next record to deliver, if read
+	idleAt time.Time // records written later are created at or after idleAt
+}
+
+// poll reads the next record of all sources without a pending record
+func (m *merger) poll(ctx context.Context) error {
+	for i := range m.sources {
+		s := &m.sources[i]
+		if s.head != nil {
+			continue
+		}
+
+		// any record written after this read is created at or after now
+		now := s.log.clock.Now()
+		r, err := s.log.Read(ctx, s.next)
+		switch {
+		case err == nil:
+			s.head = &r
+			s.next = r.Metadata.Offset + 1
+		case errors.Is(err, ErrFutureOffset):
+			s.idleAt = now
+		case errors.Is(err, ErrOffsetGap):
+			s.log.mu.RLock()
+			s.next = s.log.nextWritten(s.next)
+			s.log.mu.RUnlock()
+			s.idleAt = time.Time{} // poll again before delivering
+		default:
+			return fmt.Errorf("source %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// pop returns the earliest pending record if no source can have an earlier
+// record
+func (m *merger) pop() (SelectRecord, bool) {
+	minIdx := -1
+	for i := range m.sources {
+		s := &m.sources[i]
+		if s.head == nil {
+			continue
+		}
+
+		if minIdx == -1 || s.head.Metadata.Created.Before(m.sources[minIdx].head.Metadata.Created) {
+			minIdx = i
+		}
+	}
+
+	if minIdx == -1 {
+		return SelectRecord{}, false
+	}
+
+	created := m.sources[minIdx].head.Metadata.Created
+	for i := range m.sources {
+		s := &m.sources[i]
+		if s.head != nil {
+			continue
+		}
+
+		// idle source might still receive an earlier record or a record
+		// created at the same time ordered first
+		if s.idleAt.IsZero() || s.idleAt.Before(created) || (s.idleAt.Equal(created) && i < minIdx) {
+			return SelectRecord{}, false
+		}
+	}
+
+	s := &m.sources[minIdx]
+	r := SelectRecord{Source: minIdx, Record: *s.head}
+	s.head = nil
+	s.idleAt = time.Time{}
+
+	return r, true
+}
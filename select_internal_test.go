@@ -0,0 +1,116 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestSelect(t *testing.T) {
+	t.Run("fails with invalid sources", func(t *testing.T) {
+		ctx := context.Background()
+
+		_, errCh := SelectFrom(ctx)
+		assert.ErrorContains(t, <-errCh, "no sources")
+
+		_, errCh = Select(ctx, nil)
+		assert.ErrorContains(t, <-errCh, "source 0: log must not be nil")
+	})
+
+	t.Run("merges logs ordered by creation time", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := clock.NewMock()
+		orders, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+		payments, err := New(ctx, WithClock(mockClock), WithStartOffset(100))
+		assert.NilError(t, err)
+
+		write := func(l *Log, data string) {
+			mockClock.Add(time.Second)
+			_, err := l.Write(ctx, []byte(data))
+			assert.NilError(t, err)
+		}
+
+		write(orders, "order-1")
+		write(payments, "payment-1")
+		write(payments, "payment-2")
+		write(orders, "order-2")
+		mockClock.Add(time.Second)
+
+		selectCh, _ := Select(ctx, orders, payments)
+
+		want := []struct {
+			source int
+			offset Offset
+		}{
+			{source: 0, offset: 0},
+			{source: 1, offset: 100},
+			{source: 1, offset: 101},
+			{source: 0, offset: 1},
+		}
+		for _, w := range want {
+			r := <-selectCh
+			assert.Equal(t, r.Source, w.source)
+			assert.Equal(t, r.Record.Metadata.Offset, w.offset)
+		}
+
+		// new record delivered once no earlier record can be written to orders
+		write(payments, "payment-3")
+		mockClock.Add(time.Second)
+		r := <-selectCh
+		assert.Equal(t, r.Source, 1)
+		assert.Equal(t, string(r.Record.Data), "payment-3")
+	})
+
+	t.Run("resumes at source offsets", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := clock.NewMock()
+		a, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+		b, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		for i := 0; i < 3; i++ {
+			for _, l := range []*Log{a, b} {
+				mockClock.Add(time.Second)
+				_, err = l.Write(ctx, newTestData(t, "data"))
+				assert.NilError(t, err)
+			}
+		}
+		mockClock.Add(time.Second)
+
+		selectCh, _ := SelectFrom(ctx, SelectSource{Log: a, Start: 2}, SelectSource{Log: b, Start: 1})
+
+		r := <-selectCh
+		assert.Equal(t, r.Source, 1)
+		assert.Equal(t, r.Record.Metadata.Offset, Offset(1))
+
+		r = <-selectCh
+		assert.Equal(t, r.Source, 0)
+		assert.Equal(t, r.Record.Metadata.Offset, Offset(2))
+
+		r = <-selectCh
+		assert.Equal(t, r.Source, 1)
+		assert.Equal(t, r.Record.Metadata.Offset, Offset(2))
+	})
+
+	t.Run("fails when source offset purged", func(t *testing.T) {
+		ctx := context.Background()
+
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		_, errCh := SelectFrom(ctx, SelectSource{Log: l, Start: 0})
+		err = <-errCh
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+		assert.ErrorContains(t, err, "source 0")
+	})
+}
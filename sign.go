@@ -0,0 +1,168 @@
+package memlog
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// HeaderSignature is the record header holding the record signature when a
+// Signer is configured
+const HeaderSignature = "memlog-signature"
+
+// ErrInvalidSignature is returned when a record signature is missing or does
+// not match the record
+var ErrInvalidSignature = errors.New("invalid record signature")
+
+// Signer creates signatures for records
+type Signer interface {
+	// Sign returns the signature of msg
+	Sign(msg []byte) ([]byte, error)
+}
+
+// Verifier verifies record signatures
+type Verifier interface {
+	// Verify returns an error if sig is not a valid signature of msg
+	Verify(msg, sig []byte) error
+}
+
+// HMACSigner signs and verifies records with HMAC-SHA256 using a shared secret
+type HMACSigner struct {
+	key []byte
+}
+
+var (
+	_ Signer   = (*HMACSigner)(nil)
+	_ Verifier = (*HMACSigner)(nil)
+)
+
+// NewHMACSigner creates an HMAC-SHA256 signer and verifier with the given
+// secret key
+func NewHMACSigner(key []byte) (*HMACSigner, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key must not be empty")
+	}
+	return &HMACSigner{key: copyBytes(key)}, nil
+}
+
+// Sign returns the HMAC-SHA256 of msg
+func (s *HMACSigner) Sign(msg []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(msg)
+	return mac.Sum(nil), nil
+}
+
+// Verify compares sig with the HMAC-SHA256 of msg
+func (s *HMACSigner) Verify(msg, sig []byte) error {
+	want, _ := s.Sign(msg)
+	if !hmac.Equal(want, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Ed25519Signer signs records with an ed25519 private key
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer creates a signer using the given ed25519 private key
+func NewEd25519Signer(key ed25519.PrivateKey) (*Ed25519Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size %d", len(key))
+	}
+	return &Ed25519Signer{key: key}, nil
+}
+
+// Sign returns the ed25519 signature of msg
+func (s *Ed25519Signer) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, msg), nil
+}
+
+// Ed25519Verifier verifies records with an ed25519 public key
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+// NewEd25519Verifier creates a verifier using the given ed25519 public key
+func NewEd25519Verifier(key ed25519.PublicKey) (*Ed25519Verifier, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size %d", len(key))
+	}
+	return &Ed25519Verifier{key: key}, nil
+}
+
+// Verify checks sig is a valid ed25519 signature of msg
+func (v *Ed25519Verifier) Verify(msg, sig []byte) error {
+	if !ed25519.Verify(v.key, msg, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signingMessage returns the canonical encoding of the signed parts of a
+// record, i.e. key, headers (without HeaderSignature) and data. Offset and
+// creation time are not signed so signatures stay valid when records are
+// copied to another log.
+func signingMessage(r Record) []byte {
+	names := make([]string, 0, len(r.Metadata.Headers))
+	for k := range r.Metadata.Headers {
+		if k == HeaderSignature {
+			continue
+		}
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var msg []byte
+	appendField := func(b []byte) {
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(b)))
+		msg = append(msg, size[:]...)
+		msg = append(msg, b...)
+	}
+
+	appendField(r.Metadata.Key)
+	var count [4]byte
+	binary.BigEndian.PutUint32(count[:], uint32(len(names)))
+	msg = append(msg, count[:]...)
+	for _, k := range names {
+		appendField([]byte(k))
+		appendField(r.Metadata.Headers[k])
+	}
+	appendField(r.Data)
+
+	return msg
+}
+
+// signRecord adds a signature header to r. Must be called before the record is
+// visible to readers.
+func signRecord(s Signer, r *Record) error {
+	sig, err := s.Sign(signingMessage(*r))
+	if err != nil {
+		return fmt.Errorf("sign record: %v", err)
+	}
+
+	if r.Metadata.Headers == nil {
+		r.Metadata.Headers = make(map[string][]byte)
+	}
+	r.Metadata.Headers[HeaderSignature] = sig
+	return nil
+}
+
+// verifyRecord verifies the signature header of r
+func verifyRecord(v Verifier, r Record) error {
+	sig, ok := r.Metadata.Headers[HeaderSignature]
+	if !ok {
+		return fmt.Errorf("%w: offset %d: missing signature", ErrInvalidSignature, r.Metadata.Offset)
+	}
+
+	if err := v.Verify(signingMessage(r), sig); err != nil {
+		return fmt.Errorf("%w: offset %d: %v", ErrInvalidSignature, r.Metadata.Offset, err)
+	}
+	return nil
+}
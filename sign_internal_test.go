@@ -0,0 +1,95 @@
+package memlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Signing(t *testing.T) {
+	hmacSigner, err := NewHMACSigner([]byte("secret"))
+	assert.NilError(t, err)
+
+	otherHMAC, err := NewHMACSigner([]byte("other"))
+	assert.NilError(t, err)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NilError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	assert.NilError(t, err)
+
+	edSigner, err := NewEd25519Signer(priv)
+	assert.NilError(t, err)
+	edVerifier, err := NewEd25519Verifier(pub)
+	assert.NilError(t, err)
+	otherEdVerifier, err := NewEd25519Verifier(otherPub)
+	assert.NilError(t, err)
+
+	t.Run("verifies signed records", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			signer   Signer
+			verifier Verifier
+			wantErr  error
+		}{
+			{name: "hmac", signer: hmacSigner, verifier: hmacSigner},
+			{name: "hmac with wrong key", signer: hmacSigner, verifier: otherHMAC, wantErr: ErrInvalidSignature},
+			{name: "ed25519", signer: edSigner, verifier: edVerifier},
+			{name: "ed25519 with wrong key", signer: edSigner, verifier: otherEdVerifier, wantErr: ErrInvalidSignature},
+			{name: "unsigned record", signer: nil, verifier: hmacSigner, wantErr: ErrInvalidSignature},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctx := context.Background()
+
+				opts := []Option{WithVerifier(tc.verifier)}
+				if tc.signer != nil {
+					opts = append(opts, WithSigner(tc.signer))
+				}
+
+				l, err := New(ctx, opts...)
+				assert.NilError(t, err)
+
+				offset, err := l.Write(ctx, newTestData(t, "1"), WithKey([]byte("1")), WithHeader("tenant", []byte("acme")))
+				assert.NilError(t, err)
+
+				r, err := l.Read(ctx, offset)
+				if tc.wantErr != nil {
+					assert.Assert(t, errors.Is(err, tc.wantErr))
+					return
+				}
+
+				assert.NilError(t, err)
+				assert.Assert(t, len(r.Metadata.Headers[HeaderSignature]) > 0)
+			})
+		}
+	})
+
+	t.Run("detects tampered records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSigner(hmacSigner), WithVerifier(hmacSigner))
+		assert.NilError(t, err)
+
+		offset, err := l.Write(ctx, newTestData(t, "1"), WithHeader("tenant", []byte("acme")))
+		assert.NilError(t, err)
+
+		l.active.data[0].Metadata.Headers["tenant"] = []byte("evil")
+		_, err = l.Read(ctx, offset)
+		assert.Assert(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("fails with invalid keys", func(t *testing.T) {
+		_, err := NewHMACSigner(nil)
+		assert.ErrorContains(t, err, "must not be empty")
+
+		_, err = NewEd25519Signer(ed25519.PrivateKey("short"))
+		assert.ErrorContains(t, err, "invalid private key")
+
+		_, err = NewEd25519Verifier(ed25519.PublicKey("short"))
+		assert.ErrorContains(t, err, "invalid public key")
+	})
+}
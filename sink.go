@@ -0,0 +1,277 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultSinkMaxRecords is the maximum number of records of a sink batch
+	// unless explicitly specified
+	DefaultSinkMaxRecords = 500
+	// DefaultSinkMaxWait is the maximum duration a sink waits for a batch to
+	// fill up unless explicitly specified
+	DefaultSinkMaxWait = time.Second
+
+	// sinkPendingSuffix is appended to the sink name to store the last offset
+	// of the batch being inserted
+	sinkPendingSuffix = "/pending"
+)
+
+// SinkBatch is a batch of records handed to the insert function of a sink
+type SinkBatch struct {
+	// ID identifies the batch by the log identity and the offsets of its
+	// first and last record. A batch retried after a failure or restart has
+	// the same ID, i.e. the ID can be used to deduplicate inserts.
+	ID string
+	// Records are the records of the batch ordered by offset
+	Records []Record
+}
+
+// BulkInsertFunc inserts a batch of records into an external system, e.g. an
+// analytics warehouse
+type BulkInsertFunc func(ctx context.Context, batch SinkBatch) error
+
+// SinkOption customizes a Sink
+type SinkOption func(*Sink) error
+
+// WithSinkMaxRecords sets the maximum number of records of a batch
+func WithSinkMaxRecords(n int) SinkOption {
+	return func(s *Sink) error {
+		if n <= 0 {
+			return errors.New("max records must be greater than 0")
+		}
+
+		s.maxRecords = n
+		return nil
+	}
+}
+
+// WithSinkMaxBytes sets the maximum payload bytes of a batch. A record larger
+// than the limit is inserted in a batch of its own.
+func WithSinkMaxBytes(n int) SinkOption {
+	return func(s *Sink) error {
+		if n <= 0 {
+			return errors.New("max bytes must be greater than 0")
+		}
+
+		s.maxBytes = n
+		return nil
+	}
+}
+
+// WithSinkMaxWait sets the maximum duration between reading the first record of
+// a batch and inserting the batch, i.e. the maximum delay of records in
+// low-traffic periods. Durations are measured with the clock of the log.
+func WithSinkMaxWait(d time.Duration) SinkOption {
+	return func(s *Sink) error {
+		if d <= 0 {
+			return errors.New("max wait must be greater than 0")
+		}
+
+		s.maxWait = d
+		return nil
+	}
+}
+
+// Sink reads the records of a log in size and time bounded batches and hands
+// them to a bulk insert function, e.g. to offload records to an analytics
+// warehouse. The offset of the last inserted record is committed to a
+// CheckpointStore with the name of the sink, so that a restarted sink resumes
+// after the last inserted batch. Before a batch is inserted, its bounds are
+// saved, so that a batch which was not committed, e.g. because the insert
+// failed or the process crashed, is rebuilt with the same records and ID on
+// restart. Combined with an insert function which ignores batch IDs it has
+// inserted before, every record is inserted exactly once.
+type Sink struct {
+	log    *Log
+	name   string
+	store  CheckpointStore
+	insert BulkInsertFunc
+
+	maxRecords int
+	maxBytes   int // optional
+	maxWait    time.Duration
+}
+
+// NewSink creates a sink with the given name inserting the records of the log
+// with the given insert function. The sink is registered with the log as a
+// consumer (see Commit()) when the first batch is committed.
+func (l *Log) NewSink(name string, store CheckpointStore, insert BulkInsertFunc, options ...SinkOption) (*Sink, error) {
+	if name == "" {
+		return nil, errors.New("sink name must not be empty")
+	}
+
+	if store == nil {
+		return nil, errors.New("checkpoint store must not be nil")
+	}
+
+	if insert == nil {
+		return nil, errors.New("insert function must not be nil")
+	}
+
+	s := Sink{
+		log:        l,
+		name:       name,
+		store:      store,
+		insert:     insert,
+		maxRecords: DefaultSinkMaxRecords,
+		maxWait:    DefaultSinkMaxWait,
+	}
+
+	for _, opt := range options {
+		if err := opt(&s); err != nil {
+			return nil, fmt.Errorf("configure sink option: %v", err)
+		}
+	}
+
+	return &s, nil
+}
+
+// Run inserts batches until the context is cancelled, the log is closed
+// (ErrClosed) or an error occurs, e.g. the insert function fails or the next
+// record has been purged (ErrOutOfRange). Records of a batch which has not
+// been inserted when Run returns are inserted by the next call to Run. Run
+// must not be called concurrently.
+func (s *Sink) Run(ctx context.Context) error {
+	committed, err := s.log.resume(ctx, s.name, s.store, false)
+	if err != nil {
+		return err
+	}
+
+	// last offset of a batch started before a failure or restart
+	pending, err := s.log.resume(ctx, s.name+sinkPendingSuffix, s.store, false)
+	if err != nil {
+		return err
+	}
+
+	next := committed + 1
+	if committed == -1 {
+		next = s.log.resumeStart(ctx)
+	}
+	if pending < next {
+		pending = -1
+	}
+
+	// register before reading to not miss writes
+	notify := s.log.Notify(ctx)
+
+	var (
+		batch    []Record
+		bytes    int
+		timer    Timer
+		deadline <-chan time.Time
+	)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, deadline = nil, nil
+		}
+
+		if err := s.flush(ctx, batch); err != nil {
+			return err
+		}
+		batch, bytes = nil, 0
+		return nil
+	}
+
+	for {
+		records, err := s.log.ReadBatch(ctx, next, s.maxRecords)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrFutureOffset), errors.Is(err, ErrEmptyLog):
+		default:
+			return err
+		}
+
+		for _, r := range records {
+			switch {
+			case pending != -1 && r.Metadata.Offset > pending:
+				// rebuilt pending batch complete
+				pending = -1
+				if err = flush(); err != nil {
+					return err
+				}
+			case pending == -1 && len(batch) > 0 && s.maxBytes > 0 && bytes+len(r.Data) > s.maxBytes:
+				if err = flush(); err != nil {
+					return err
+				}
+			}
+
+			batch = append(batch, r)
+			bytes += len(r.Data)
+			next = r.Metadata.Offset + 1
+
+			if pending != -1 {
+				continue
+			}
+
+			if len(batch) >= s.maxRecords {
+				if err = flush(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if timer == nil {
+				timer = s.log.clock.NewTimer(s.maxWait)
+				deadline = timer.Chan()
+			}
+		}
+
+		if pending != -1 && next > pending {
+			pending = -1
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+
+		if len(records) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-notify:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return ErrClosed
+			}
+		case <-deadline:
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// flush inserts the batch and commits the offset of its last record
+func (s *Sink) flush(ctx context.Context, batch []Record) error {
+	first, last := batch[0].Metadata.Offset, batch[len(batch)-1].Metadata.Offset
+	id := fmt.Sprintf("%s-%d-%d", s.log.ID(), first, last)
+
+	checkpoint := Checkpoint{LogID: s.log.ID(), Offset: last}
+	if err := s.store.Save(ctx, s.name+sinkPendingSuffix, checkpoint); err != nil {
+		return fmt.Errorf("save pending batch %s: %w", id, err)
+	}
+
+	if err := s.insert(ctx, SinkBatch{ID: id, Records: batch}); err != nil {
+		return fmt.Errorf("insert batch %s: %w", id, err)
+	}
+
+	if err := s.store.Save(ctx, s.name, checkpoint); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return s.log.Commit(ctx, s.name, last)
+}
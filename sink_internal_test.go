@@ -0,0 +1,229 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+// batchRecorder returns an insert function sending inserted batches to the
+// returned channel
+func batchRecorder() (BulkInsertFunc, <-chan SinkBatch) {
+	batches := make(chan SinkBatch, 100)
+	return func(ctx context.Context, b SinkBatch) error {
+		batches <- b
+		return nil
+	}, batches
+}
+
+func sinkOffsets(b SinkBatch) []Offset {
+	offsets := make([]Offset, 0, len(b.Records))
+	for _, r := range b.Records {
+		offsets = append(offsets, r.Metadata.Offset)
+	}
+	return offsets
+}
+
+func TestLog_NewSink(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	insert, _ := batchRecorder()
+	store := NewMemoryCheckpointStore()
+
+	testCases := []struct {
+		name    string
+		sink    string
+		store   CheckpointStore
+		insert  BulkInsertFunc
+		options []SinkOption
+		wantErr string
+	}{
+		{name: "empty name", sink: "", store: store, insert: insert, wantErr: "name must not be empty"},
+		{name: "nil store", sink: "warehouse", store: nil, insert: insert, wantErr: "store must not be nil"},
+		{name: "nil insert", sink: "warehouse", store: store, insert: nil, wantErr: "insert function must not be nil"},
+		{name: "invalid max records", sink: "warehouse", store: store, insert: insert, options: []SinkOption{WithSinkMaxRecords(0)}, wantErr: "max records must be greater than 0"},
+		{name: "invalid max bytes", sink: "warehouse", store: store, insert: insert, options: []SinkOption{WithSinkMaxBytes(-1)}, wantErr: "max bytes must be greater than 0"},
+		{name: "invalid max wait", sink: "warehouse", store: store, insert: insert, options: []SinkOption{WithSinkMaxWait(0)}, wantErr: "max wait must be greater than 0"},
+		{name: "valid", sink: "warehouse", store: store, insert: insert, options: []SinkOption{WithSinkMaxRecords(10), WithSinkMaxBytes(1024), WithSinkMaxWait(time.Minute)}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := l.NewSink(tc.sink, tc.store, tc.insert, tc.options...)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, s != nil)
+		})
+	}
+}
+
+func TestSink_Run(t *testing.T) {
+	t.Run("inserts batches bounded by records and bytes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range []string{"a", "b", "c", "dddd", "e"} {
+			_, err = l.Write(ctx, []byte(d))
+			assert.NilError(t, err)
+		}
+
+		insert, batches := batchRecorder()
+		store := NewMemoryCheckpointStore()
+		s, err := l.NewSink("warehouse", store, insert, WithSinkMaxRecords(2), WithSinkMaxBytes(4), WithSinkMaxWait(time.Millisecond))
+		assert.NilError(t, err)
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- s.Run(ctx)
+		}()
+
+		// "dddd" does not fit next to "c" or "e"
+		for _, want := range [][]Offset{{0, 1}, {2}, {3}, {4}} {
+			b := <-batches
+			assert.DeepEqual(t, sinkOffsets(b), want)
+		}
+
+		cancel()
+		assert.Assert(t, errors.Is(<-errCh, context.Canceled))
+
+		checkpoint, err := store.Load(context.Background(), "warehouse")
+		assert.NilError(t, err)
+		assert.Equal(t, checkpoint.Offset, Offset(4))
+		assert.Equal(t, checkpoint.LogID, l.ID())
+	})
+
+	t.Run("inserts incomplete batch after max wait", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		insert, batches := batchRecorder()
+		s, err := l.NewSink("warehouse", NewMemoryCheckpointStore(), insert, WithSinkMaxRecords(10), WithSinkMaxWait(time.Second))
+		assert.NilError(t, err)
+
+		go func() {
+			_ = s.Run(ctx)
+		}()
+
+		_, err = l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+
+		// the timer is started asynchronously once the record is read
+		for {
+			mockClock.Add(time.Second)
+			select {
+			case b := <-batches:
+				assert.DeepEqual(t, sinkOffsets(b), []Offset{0})
+				return
+			case <-time.After(time.Millisecond * 10):
+			}
+		}
+	})
+
+	t.Run("retries failed batch with the same id", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range []string{"a", "b", "c"} {
+			_, err = l.Write(ctx, []byte(d))
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		failing := func(ctx context.Context, b SinkBatch) error {
+			return errors.New("warehouse unavailable")
+		}
+		s, err := l.NewSink("warehouse", store, failing, WithSinkMaxRecords(2))
+		assert.NilError(t, err)
+
+		err = s.Run(ctx)
+		assert.ErrorContains(t, err, "warehouse unavailable")
+		assert.ErrorContains(t, err, "insert batch "+l.ID()+"-0-1")
+
+		// more records and a larger batch size must not change the pending batch
+		_, err = l.Write(ctx, []byte("d"))
+		assert.NilError(t, err)
+
+		insert, batches := batchRecorder()
+		s, err = l.NewSink("warehouse", store, insert, WithSinkMaxRecords(10), WithSinkMaxWait(time.Millisecond))
+		assert.NilError(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go func() {
+			_ = s.Run(runCtx)
+		}()
+
+		b := <-batches
+		assert.Equal(t, b.ID, l.ID()+"-0-1")
+		assert.DeepEqual(t, sinkOffsets(b), []Offset{0, 1})
+
+		b = <-batches
+		assert.DeepEqual(t, sinkOffsets(b), []Offset{2, 3})
+	})
+
+	t.Run("resumes after committed batch", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range []string{"a", "b", "c"} {
+			_, err = l.Write(ctx, []byte(d))
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		assert.NilError(t, store.Save(ctx, "warehouse", Checkpoint{LogID: l.ID(), Offset: 1}))
+
+		insert, batches := batchRecorder()
+		s, err := l.NewSink("warehouse", store, insert, WithSinkMaxRecords(1))
+		assert.NilError(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go func() {
+			_ = s.Run(runCtx)
+		}()
+
+		b := <-batches
+		assert.DeepEqual(t, sinkOffsets(b), []Offset{2})
+		assert.Equal(t, b.ID, l.ID()+"-2-2")
+	})
+
+	t.Run("returns ErrClosed when log is closed", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		insert, _ := batchRecorder()
+		s, err := l.NewSink("warehouse", NewMemoryCheckpointStore(), insert)
+		assert.NilError(t, err)
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- s.Run(ctx)
+		}()
+
+		time.Sleep(time.Millisecond * 10)
+		assert.NilError(t, l.Close())
+		assert.Assert(t, errors.Is(<-errCh, ErrClosed))
+	})
+}
@@ -0,0 +1,261 @@
+package memlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// snapshotVersion is the version of the snapshot format
+const snapshotVersion = 1
+
+// managerSnapshot is the serialized form of a Manager snapshot
+type managerSnapshot struct {
+	Version int             `json:"version"`
+	Topics  []topicSnapshot `json:"topics"`
+}
+
+// topicSnapshot is the serialized form of a log in a Manager snapshot
+type topicSnapshot struct {
+	Topic string `json:"topic"`
+	Snapshot
+}
+
+// Snapshot is the state of a log, i.e. its retained records and offsets, see
+// Log.Snapshot()
+type Snapshot struct {
+	// ID is the identity of the log, see Log.ID()
+	ID string `json:"id"`
+	// Epoch is the writer epoch of the log, see SetEpoch()
+	Epoch uint64 `json:"epoch"`
+	// Next is the next write offset of the log
+	Next Offset `json:"next"`
+	// Records are the records retained in the log ordered by offset. Gaps and
+	// expired records are not included.
+	Records []Record `json:"records"`
+}
+
+// Snapshot returns the retained records and offset state of the log, e.g. to
+// hand over a log to another process or restart a process with a warm log
+// without replaying an upstream source, see NewFromSnapshot(). Writes are
+// blocked while the records are copied.
+//
+// Safe for concurrent use.
+func (l *Log) Snapshot(ctx context.Context) (Snapshot, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.snapshot(ctx)
+}
+
+// NewFromSnapshot creates a log with the records and offset state of the
+// snapshot with default options applied, unless specified otherwise. Records
+// are written at their original offsets, preserving their key, headers,
+// creation time, expiry time and writer epoch. The log has the identity of the snapshotted log, so that
+// consumer checkpoints remain valid, unless specified otherwise with WithID().
+// Snapshots of logs with gaps require WithSparseOffsets().
+func NewFromSnapshot(ctx context.Context, s Snapshot, options ...Option) (*Log, error) {
+	l, err := New(ctx, s.options(options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.restore(ctx, s); err != nil {
+		return nil, fmt.Errorf("restore snapshot: %w", err)
+	}
+	return l, nil
+}
+
+// options returns the options of a log restored from the snapshot, i.e. the
+// identity of the snapshotted log, the given options and the start offset
+func (s Snapshot) options(options []Option) []Option {
+	restored := make([]Option, 0, len(options)+2)
+	if s.ID != "" {
+		restored = append(restored, WithID(s.ID))
+	}
+	restored = append(restored, options...)
+
+	start := s.Next
+	if len(s.Records) > 0 {
+		start = s.Records[0].Metadata.Offset
+	}
+	return append(restored, WithStartOffset(start))
+}
+
+// restore sets the epoch, writes the records of the snapshot to the empty log
+// and advances the log to the next offset of the snapshot
+func (l *Log) restore(ctx context.Context, s Snapshot) error {
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	l.epoch = s.Epoch
+	l.mu.Unlock()
+
+	if err := l.ImportFrom(ctx, NewSliceSource(s.Records), WithPreserveOffsets(), withPreservedMetadata()); err != nil {
+		return err
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.mu.Unlock()
+
+	// records after the last snapshot record were purged or expired, offsets
+	// must not be reused
+	if s.Next > l.offset {
+		return l.skipTo(s.Next)
+	}
+	return nil
+}
+
+// Snapshot writes a consistent snapshot of the records retained in all topics
+// to w, e.g. to capture the state of a whole application. All logs are cut at
+// the same point in time, i.e. the snapshot contains either all or none of the
+// records of a transaction (see WriteTransaction()). Writes are blocked while
+// the records are copied, but not while the snapshot is written to w. Gaps and
+// expired records are not part of the snapshot. See Restore() to create the
+// topics of a snapshot.
+//
+// Safe for concurrent use.
+func (m *Manager) Snapshot(ctx context.Context, w io.Writer) error {
+	if w == nil {
+		return errors.New("writer must not be nil")
+	}
+
+	snapshot, err := m.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(w).Encode(snapshot); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshot copies the records of all topics while holding the read lock of
+// all logs
+func (m *Manager) snapshot(ctx context.Context) (managerSnapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	topics := make([]string, 0, len(m.logs))
+	for t := range m.logs {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+
+	// lock logs in topic order like transactions to prevent deadlocks
+	for _, t := range topics {
+		m.logs[t].mu.RLock()
+	}
+	defer func() {
+		for _, t := range topics {
+			m.logs[t].mu.RUnlock()
+		}
+	}()
+
+	snapshot := managerSnapshot{
+		Version: snapshotVersion,
+		Topics:  make([]topicSnapshot, 0, len(topics)),
+	}
+	for _, t := range topics {
+		s, err := m.logs[t].snapshot(ctx)
+		if err != nil {
+			return managerSnapshot{}, fmt.Errorf("snapshot topic %q: %w", t, err)
+		}
+
+		snapshot.Topics = append(snapshot.Topics, topicSnapshot{Topic: t, Snapshot: s})
+	}
+
+	return snapshot, nil
+}
+
+// snapshot returns the records and offset state of the log. Must be protected
+// with a lock by the caller.
+func (l *Log) snapshot(ctx context.Context) (Snapshot, error) {
+	if l.closed {
+		return Snapshot{}, ErrClosed
+	}
+
+	s := Snapshot{ID: l.id, Epoch: l.epoch, Next: l.offset, Records: []Record{}}
+	if l.isEmpty() {
+		return s, nil
+	}
+
+	earliest, latest := l.offsetRange()
+	for offset := earliest; offset <= latest; offset++ {
+		r, err := l.read(ctx, offset)
+		if errors.Is(err, ErrOffsetGap) {
+			continue
+		}
+		if err != nil {
+			return Snapshot{}, err
+		}
+		s.Records = append(s.Records, r)
+	}
+
+	return s, nil
+}
+
+// Restore creates the topics of a snapshot written with Snapshot() and writes
+// their records at their original offsets, preserving their key, headers,
+// creation time, expiry time and writer epoch. Restored logs keep their identity and epoch, see
+// NewFromSnapshot(). Topics are created with the default options of the Manager
+// (see WithDefaultOptions()) and the specified options, e.g.
+// WithSparseOffsets() for snapshots of logs with gaps. Restore fails with
+// ErrTopicExists if a topic of the snapshot exists. If an error occurs, the
+// topics created by Restore are deleted.
+//
+// Safe for concurrent use.
+func (m *Manager) Restore(ctx context.Context, r io.Reader, options ...Option) error {
+	if r == nil {
+		return errors.New("reader must not be nil")
+	}
+
+	var snapshot managerSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	for _, ts := range snapshot.Topics {
+		if _, err := m.Get(ts.Topic); err == nil {
+			return fmt.Errorf("%w: %s", ErrTopicExists, ts.Topic)
+		}
+	}
+
+	var created []string
+	for _, ts := range snapshot.Topics {
+		if err := m.restore(ctx, ts, options); err != nil {
+			for _, t := range created {
+				_ = m.Delete(ctx, t)
+			}
+			return fmt.Errorf("restore topic %q: %w", ts.Topic, err)
+		}
+		created = append(created, ts.Topic)
+	}
+
+	return nil
+}
+
+// restore creates the topic of the snapshot and restores its records
+func (m *Manager) restore(ctx context.Context, ts topicSnapshot, options []Option) error {
+	l, err := m.Create(ctx, ts.Topic, ts.options(options)...)
+	if err != nil {
+		return err
+	}
+
+	if err = l.restore(ctx, ts.Snapshot); err != nil {
+		_ = m.Delete(ctx, ts.Topic)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package memlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewFromSnapshot(t *testing.T) {
+	t.Run("restores records and offset state", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(10), WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+		assert.NilError(t, l.SetEpoch(ctx, 3))
+
+		for _, d := range []string{"a", "b", "c", "d", "e"} {
+			_, err = l.Write(ctx, []byte(d), WithKey([]byte(d)))
+			assert.NilError(t, err)
+		}
+
+		snapshot, err := l.Snapshot(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, snapshot.ID, l.ID())
+		assert.Equal(t, snapshot.Epoch, uint64(3))
+		assert.Equal(t, snapshot.Next, Offset(15))
+		assert.Equal(t, len(snapshot.Records), 3)
+
+		restored, err := NewFromSnapshot(ctx, snapshot, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+		assert.Equal(t, restored.ID(), l.ID())
+		assert.Equal(t, restored.Epoch(), uint64(3))
+
+		earliest, latest := restored.Range(ctx)
+		assert.Equal(t, earliest, Offset(12))
+		assert.Equal(t, latest, Offset(14))
+
+		for offset := earliest; offset <= latest; offset++ {
+			want, err := l.Read(ctx, offset)
+			assert.NilError(t, err)
+			got, err := restored.Read(ctx, offset)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got.Data, want.Data)
+			assert.DeepEqual(t, got.Metadata.Key, want.Metadata.Key)
+			assert.Equal(t, got.Metadata.Created, want.Metadata.Created)
+		}
+
+		// checkpoints of the snapshotted log remain valid
+		store := NewMemoryCheckpointStore()
+		assert.NilError(t, store.Save(ctx, "consumer", Checkpoint{LogID: l.ID(), Offset: 12}))
+		c, err := restored.NewConsumer(ctx, "consumer", store)
+		assert.NilError(t, err)
+		r, err := c.Next(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(13))
+
+		offset, err := restored.Write(ctx, []byte("f"), WithEpoch(3))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(15))
+	})
+
+	t.Run("restores next offset after expired records", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("b"))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("c"), WithTTL(time.Second))
+		assert.NilError(t, err)
+
+		mockClock.Add(time.Second)
+
+		snapshot, err := l.Snapshot(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, snapshot.Next, Offset(3))
+		assert.Equal(t, len(snapshot.Records), 2)
+
+		restored, err := NewFromSnapshot(ctx, snapshot, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		_, err = restored.Read(ctx, 2)
+		assert.Assert(t, errors.Is(err, ErrOffsetGap))
+
+		offset, err := restored.Write(ctx, []byte("d"))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(3))
+	})
+
+	t.Run("restores record metadata", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("a"), WithKey([]byte("a")), WithTTL(time.Minute))
+		assert.NilError(t, err)
+		assert.NilError(t, l.SetEpoch(ctx, 1))
+		_, err = l.Write(ctx, []byte("b"), WithHeader("h", []byte("v")))
+		assert.NilError(t, err)
+
+		snapshot, err := l.Snapshot(ctx)
+		assert.NilError(t, err)
+
+		restored, err := NewFromSnapshot(ctx, snapshot, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		for _, want := range snapshot.Records {
+			got, err := restored.Read(ctx, want.Metadata.Offset)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got.Metadata, want.Metadata)
+		}
+
+		mockClock.Add(time.Minute)
+
+		_, err = restored.Read(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrExpired))
+	})
+
+	t.Run("restores empty logs with another identity", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(5))
+		assert.NilError(t, err)
+
+		snapshot, err := l.Snapshot(ctx)
+		assert.NilError(t, err)
+
+		restored, err := NewFromSnapshot(ctx, snapshot, WithID("other"))
+		assert.NilError(t, err)
+		assert.Equal(t, restored.ID(), "other")
+
+		offset, err := restored.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+	})
+
+	t.Run("fails with closed logs and invalid snapshots", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+		assert.NilError(t, l.Close())
+
+		_, err = l.Snapshot(ctx)
+		assert.Assert(t, errors.Is(err, ErrClosed))
+
+		// gaps require sparse offsets
+		snapshot := Snapshot{Next: 3, Records: []Record{
+			{Metadata: Header{Offset: 0}, Data: []byte("a")},
+			{Metadata: Header{Offset: 2}, Data: []byte("b")},
+		}}
+		_, err = NewFromSnapshot(ctx, snapshot)
+		assert.ErrorContains(t, err, "restore snapshot")
+
+		restored, err := NewFromSnapshot(ctx, snapshot, WithSparseOffsets())
+		assert.NilError(t, err)
+		_, err = restored.Read(ctx, 1)
+		assert.Assert(t, errors.Is(err, ErrOffsetGap))
+	})
+}
+
+func TestManager_Snapshot(t *testing.T) {
+	t.Run("restores topics at their original offsets", func(t *testing.T) {
+		ctx := context.Background()
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		orders, err := m.Create(ctx, "orders", WithStartOffset(10), WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+		_, err = m.Create(ctx, "empty", WithStartOffset(5))
+		assert.NilError(t, err)
+
+		for _, d := range []string{"a", "b", "c"} {
+			_, err = orders.Write(ctx, []byte(d), WithKey([]byte(d)))
+			assert.NilError(t, err)
+		}
+
+		var buf bytes.Buffer
+		assert.NilError(t, m.Snapshot(ctx, &buf))
+
+		restored, err := NewManager()
+		assert.NilError(t, err)
+		assert.NilError(t, restored.Restore(ctx, &buf))
+		assert.DeepEqual(t, restored.Topics(), []string{"empty", "orders"})
+
+		l, err := restored.Get("orders")
+		assert.NilError(t, err)
+		assert.Equal(t, l.ID(), orders.ID())
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(10))
+		assert.Equal(t, latest, Offset(12))
+
+		for offset := earliest; offset <= latest; offset++ {
+			want, err := orders.Read(ctx, offset)
+			assert.NilError(t, err)
+			got, err := l.Read(ctx, offset)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got.Data, want.Data)
+			assert.DeepEqual(t, got.Metadata.Key, want.Metadata.Key)
+			assert.Equal(t, got.Metadata.Created, want.Metadata.Created)
+		}
+
+		l, err = restored.Get("empty")
+		assert.NilError(t, err)
+		offset, err := l.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(5))
+	})
+
+	t.Run("restores offset state and metadata of all topics", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		events, err := m.Create(ctx, "events", WithClock(mockClock))
+		assert.NilError(t, err)
+		orders, err := m.Create(ctx, "orders", WithClock(mockClock))
+		assert.NilError(t, err)
+
+		_, err = events.Write(ctx, []byte("a"))
+		assert.NilError(t, err)
+		for _, d := range []string{"b", "c"} {
+			_, err = events.Write(ctx, []byte(d), WithTTL(time.Second))
+			assert.NilError(t, err)
+		}
+
+		assert.NilError(t, orders.SetEpoch(ctx, 2))
+		_, err = orders.Write(ctx, []byte("a"), WithTTL(time.Minute))
+		assert.NilError(t, err)
+
+		// purge the tail of events
+		mockClock.Add(time.Second)
+		n, err := events.PurgeExpired(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 2)
+
+		var buf bytes.Buffer
+		assert.NilError(t, m.Snapshot(ctx, &buf))
+
+		restored, err := NewManager()
+		assert.NilError(t, err)
+		assert.NilError(t, restored.Restore(ctx, &buf, WithClock(mockClock)))
+
+		l, err := restored.Get("events")
+		assert.NilError(t, err)
+		offset, err := l.Write(ctx, []byte("d"))
+		assert.NilError(t, err)
+		assert.Equal(t, offset, Offset(3))
+
+		l, err = restored.Get("orders")
+		assert.NilError(t, err)
+		want, err := orders.Read(ctx, 0)
+		assert.NilError(t, err)
+		got, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got.Metadata, want.Metadata)
+		assert.Equal(t, got.Metadata.Epoch, uint64(2))
+		assert.Assert(t, !got.Metadata.Expires.IsZero())
+	})
+
+	t.Run("fails to restore existing topics and invalid snapshots", func(t *testing.T) {
+		ctx := context.Background()
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		_, err = m.Create(ctx, "orders")
+		assert.NilError(t, err)
+
+		var buf bytes.Buffer
+		assert.NilError(t, m.Snapshot(ctx, &buf))
+
+		err = m.Restore(ctx, &buf)
+		assert.Assert(t, errors.Is(err, ErrTopicExists))
+
+		err = m.Restore(ctx, strings.NewReader(`{"version":2}`))
+		assert.ErrorContains(t, err, "unsupported snapshot version")
+
+		err = m.Restore(ctx, strings.NewReader(`not json`))
+		assert.ErrorContains(t, err, "read snapshot")
+	})
+
+	t.Run("deletes restored topics on error", func(t *testing.T) {
+		ctx := context.Background()
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		// gaps can not be restored without sparse offsets
+		snapshot := `{"version":1,"topics":[` +
+			`{"topic":"a","next":1,"records":[{"metadata":{"created":"2021-01-01T00:00:00Z"},"data":"YQ=="}]},` +
+			`{"topic":"b","next":3,"records":[{"metadata":{"created":"2021-01-01T00:00:00Z"},"data":"YQ=="},` +
+			`{"metadata":{"offset":2,"created":"2021-01-01T00:00:00Z"},"data":"YQ=="}]}]}`
+
+		err = m.Restore(ctx, strings.NewReader(snapshot))
+		assert.ErrorContains(t, err, `restore topic "b"`)
+		assert.Equal(t, len(m.Topics()), 0)
+
+		assert.NilError(t, m.Restore(ctx, strings.NewReader(snapshot), WithSparseOffsets()))
+		assert.DeepEqual(t, m.Topics(), []string{"a", "b"})
+	})
+
+	t.Run("cuts all topics at the same point", func(t *testing.T) {
+		ctx := context.Background()
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		for _, topic := range []string{"audit", "events"} {
+			_, err = m.Create(ctx, topic)
+			assert.NilError(t, err)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_, err := m.WriteTransaction(ctx, []TopicRecord{
+					{Topic: "events", Data: []byte("event")},
+					{Topic: "audit", Data: []byte("audit")},
+				})
+				assert.Check(t, err)
+			}
+		}()
+
+		for i := 0; i < 10; i++ {
+			snapshot, err := m.snapshot(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, len(snapshot.Topics[0].Records), len(snapshot.Topics[1].Records))
+		}
+		wg.Wait()
+	})
+}
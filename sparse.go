@@ -0,0 +1,181 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// gapRecord is stored in segments for offsets without a record. It is
+// identified by an invalid offset.
+var gapRecord = Record{Metadata: Header{Offset: -1}}
+
+// WriteAt creates a new record in the log at the given offset. The offset
+// must not be lower than the next offset of the log, i.e. the latest offset
+// plus one. Offsets skipped by WriteAt are gaps and reading them returns
+// ErrOffsetGap. Requires the log to be created with WithSparseOffsets().
+//
+// The written offset is returned. If an error occurs, an invalid offset (-1)
+// and the error is returned.
+//
+// Safe for concurrent use.
+func (l *Log) WriteAt(ctx context.Context, offset Offset, data []byte, options ...WriteOption) (Offset, error) {
+	if l.latency != nil {
+		defer l.latency.write.since(l.clock, l.clock.Now())
+	}
+
+	if err := l.lock(ctx); err != nil {
+		return -1, err
+	}
+	defer l.mu.Unlock()
+
+	return l.writeAt(ctx, offset, data, options...)
+}
+
+func (l *Log) writeAt(ctx context.Context, offset Offset, data []byte, options ...WriteOption) (Offset, error) {
+	if !l.conf.sparse {
+		return -1, errors.New("sparse offsets not enabled")
+	}
+
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+
+	// validate before skipping, so that a rejected write does not leave a gap
+	wc := newWriteConfig(options)
+	if original, ok, err := l.duplicate(wc); ok || err != nil {
+		return original, err
+	}
+
+	if err := l.validateWrite(data, wc); err != nil {
+		return -1, err
+	}
+
+	if offset < l.offset {
+		return -1, fmt.Errorf("%w: offset %d lower than next offset %d", ErrOutOfRange, offset, l.offset)
+	}
+
+	if err := l.checkPurge(l.purgedByAt(offset)); err != nil {
+		return -1, err
+	}
+
+	if err := l.skipTo(offset); err != nil {
+		return -1, err
+	}
+
+	return l.write(ctx, data, options...)
+}
+
+// skipTo advances the next write offset of the log to the given offset,
+// filling the active segment with gaps or starting a new active segment if
+// the gap does not fit. Must be protected with a lock by the caller.
+func (l *Log) skipTo(offset Offset) error {
+	gap := int(offset - l.offset)
+	if gap == 0 {
+		return nil
+	}
+
+	switch {
+	case l.active.currentOffset() == -1:
+		if err := l.rebase(offset); err != nil {
+			return err
+		}
+
+	case len(l.active.data)+gap <= cap(l.active.data):
+		for i := 0; i < gap; i++ {
+			l.active.data = append(l.active.data, gapRecord)
+		}
+
+	default:
+		if err := l.extendAt(offset); err != nil {
+			return err
+		}
+	}
+
+	l.offset = offset
+	return nil
+}
+
+// purgedByAt returns the highest offset purged by skipping to the given offset
+// (see skipTo()) and writing a record at the offset or -1 if no record is
+// purged. Must be protected with a lock by the caller.
+func (l *Log) purgedByAt(offset Offset) Offset {
+	gap := int(offset - l.offset)
+	switch {
+	case gap == 0:
+		return l.purgedBy(1)
+	case l.active.currentOffset() == -1:
+		// rebased, the record is written to the new active segment
+		return -1
+	case len(l.active.data)+gap <= cap(l.active.data):
+		return l.purgedBy(gap + 1)
+	case l.history == nil:
+		return -1
+	default:
+		// the active segment rolls at the offset and the history is purged
+		return l.history.currentOffset()
+	}
+}
+
+// rebase replaces the empty active segment with a segment starting at the
+// given offset and sets the start and next write offset of the log
+// accordingly. Must be protected with a lock by the caller.
+func (l *Log) rebase(offset Offset) error {
+	seg, err := l.newSegment(offset)
+	if err != nil {
+		return err
+	}
+
+	l.active = seg
+	l.offset = offset
+	l.conf.startOffset = offset
+	return nil
+}
+
+// nextWritten returns the first offset greater or equal to the given offset
+// with an unexpired record. If there is none, the next write offset is
+// returned. Must be protected with a lock by the caller.
+func (l *Log) nextWritten(offset Offset) Offset {
+	now := l.expiryTime()
+	for _, s := range []*segment{l.history, l.active} {
+		if s == nil || offset > s.currentOffset() {
+			continue
+		}
+
+		if offset < s.start {
+			offset = s.start
+		}
+
+		for ; offset <= s.currentOffset(); offset++ {
+			if s.live(offset, now) {
+				return offset
+			}
+		}
+	}
+
+	return l.offset
+}
+
+// prevWritten returns the last offset less or equal to the given offset with an
+// unexpired record. If there is none, -1 is returned. Must be protected with a
+// lock by the caller.
+func (l *Log) prevWritten(offset Offset) Offset {
+	now := l.expiryTime()
+	for _, s := range []*segment{l.active, l.history} {
+		if s == nil || offset < s.start || s.currentOffset() == -1 {
+			continue
+		}
+
+		if offset > s.currentOffset() {
+			offset = s.currentOffset()
+		}
+
+		for ; offset >= s.start; offset-- {
+			if s.live(offset, now) {
+				return offset
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,140 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_WriteAt(t *testing.T) {
+	t.Run("fails when sparse offsets are not enabled", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.WriteAt(ctx, 5, newTestData(t, "1"))
+		assert.ErrorContains(t, err, "not enabled")
+	})
+
+	t.Run("fails when combined with merkle tree", func(t *testing.T) {
+		_, err := New(context.Background(), WithSparseOffsets(), WithMerkleTree())
+		assert.ErrorContains(t, err, "cannot be combined")
+	})
+
+	t.Run("fails when offset is already written", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets())
+		assert.NilError(t, err)
+
+		_, err = l.WriteAt(ctx, 5, newTestData(t, "1"))
+		assert.NilError(t, err)
+
+		_, err = l.WriteAt(ctx, 5, newTestData(t, "2"))
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+	})
+
+	t.Run("reads records and gaps", func(t *testing.T) {
+		type read struct {
+			offset  Offset
+			wantErr error
+		}
+
+		testCases := []struct {
+			name    string
+			start   Offset
+			segSize int
+			writes  []Offset
+			reads   []read
+			want    [2]Offset // earliest, latest
+		}{
+			{
+				name:    "first write rebases empty log",
+				start:   0,
+				segSize: 10,
+				writes:  []Offset{100, 101},
+				reads:   []read{{offset: 0, wantErr: ErrOutOfRange}, {offset: 99, wantErr: ErrOutOfRange}, {offset: 100}, {offset: 101}},
+				want:    [2]Offset{100, 101},
+			},
+			{
+				name:    "gaps within active segment",
+				start:   0,
+				segSize: 10,
+				writes:  []Offset{0, 3, 4, 8},
+				reads:   []read{{offset: 0}, {offset: 1, wantErr: ErrOffsetGap}, {offset: 3}, {offset: 7, wantErr: ErrOffsetGap}, {offset: 8}, {offset: 9, wantErr: ErrFutureOffset}},
+				want:    [2]Offset{0, 8},
+			},
+			{
+				name:    "gap larger than active segment",
+				start:   0,
+				segSize: 10,
+				writes:  []Offset{0, 2, 50, 51},
+				reads:   []read{{offset: 0}, {offset: 3, wantErr: ErrOffsetGap}, {offset: 20, wantErr: ErrOffsetGap}, {offset: 50}, {offset: 51}},
+				want:    [2]Offset{0, 51},
+			},
+			{
+				name:    "gaps purged with history",
+				start:   0,
+				segSize: 5,
+				writes:  []Offset{0, 10, 20, 30},
+				reads:   []read{{offset: 0, wantErr: ErrOutOfRange}, {offset: 10, wantErr: ErrOutOfRange}, {offset: 25, wantErr: ErrOffsetGap}, {offset: 30}},
+				want:    [2]Offset{20, 30},
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctx := context.Background()
+				l, err := New(ctx, WithSparseOffsets(), WithStartOffset(tc.start), WithMaxSegmentSize(tc.segSize))
+				assert.NilError(t, err)
+
+				for _, o := range tc.writes {
+					offset, writeErr := l.WriteAt(ctx, o, newTestData(t, "1"))
+					assert.NilError(t, writeErr)
+					assert.Equal(t, offset, o)
+				}
+
+				for _, r := range tc.reads {
+					rec, readErr := l.Read(ctx, r.offset)
+					if r.wantErr != nil {
+						assert.Assert(t, errors.Is(readErr, r.wantErr), "offset %d: %v", r.offset, readErr)
+						continue
+					}
+					assert.NilError(t, readErr)
+					assert.Equal(t, rec.Metadata.Offset, r.offset)
+				}
+
+				earliest, latest := l.Range(ctx)
+				assert.Equal(t, earliest, tc.want[0])
+				assert.Equal(t, latest, tc.want[1])
+			})
+		}
+	})
+
+	t.Run("stream skips gaps", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx, WithSparseOffsets(), WithMaxSegmentSize(5))
+		assert.NilError(t, err)
+
+		want := []Offset{1, 3, 20, 22}
+		for _, o := range want {
+			_, err = l.WriteAt(ctx, o, newTestData(t, "1"))
+			assert.NilError(t, err)
+		}
+
+		stream, errs := l.Stream(ctx, 1)
+		var got []Offset
+		for len(got) < len(want) {
+			select {
+			case r := <-stream:
+				got = append(got, r.Record.Metadata.Offset)
+			case streamErr := <-errs:
+				t.Fatalf("unexpected stream error: %v", streamErr)
+			}
+		}
+		assert.DeepEqual(t, got, want)
+	})
+}
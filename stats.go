@@ -0,0 +1,63 @@
+package memlog
+
+import "context"
+
+// Stats are statistics of a log at the time of retrieval
+type Stats struct {
+	// Records is the number of records retained in the log, excluding gaps of
+	// logs with sparse offsets
+	Records int `json:"records"`
+	// Bytes is the total payload size of the retained records, see
+	// RetainedBytes()
+	Bytes int `json:"bytes"`
+	// Writes is the number of records written to the log since its creation
+	Writes uint64 `json:"writes"`
+	// Purged is the number of records purged from the log since its creation,
+	// e.g. due to retention or Trim()
+	Purged uint64 `json:"purged"`
+	// Segments are the retained segments of the log ordered by offset, i.e.
+	// their fill levels, see Segments()
+	Segments []SegmentInfo `json:"segments"`
+	// Subscriptions are the active stream subscriptions of the log ordered by
+	// creation
+	Subscriptions []SubscriptionInfo `json:"subscriptions"`
+	// AliveConsumers is the number of registered consumers with a heartbeat
+	// within the consumer timeout, see Consumers()
+	AliveConsumers int `json:"aliveConsumers"`
+	// DeadConsumers is the number of registered consumers without a heartbeat
+	// within the consumer timeout, see Consumers()
+	DeadConsumers int `json:"deadConsumers"`
+}
+
+// Stats returns statistics of the log, e.g. for capacity planning of the
+// memory footprint or to find leaked stream subscriptions and dead consumers
+// in long-running services
+//
+// Safe for concurrent use.
+func (l *Log) Stats(_ context.Context) Stats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := Stats{
+		Bytes:         l.retainedBytes(),
+		Writes:        l.writes,
+		Purged:        l.purged,
+		Segments:      make([]SegmentInfo, 0, 2),
+		Subscriptions: l.subscriptions.list(),
+	}
+
+	l.consumers.mu.Lock()
+	stats.AliveConsumers, stats.DeadConsumers = l.consumers.liveness(l.clock.Now())
+	l.consumers.mu.Unlock()
+
+	for _, s := range []*segment{l.history, l.active} {
+		if s == nil {
+			continue
+		}
+
+		stats.Records += s.written()
+		stats.Segments = append(stats.Segments, s.info())
+	}
+
+	return stats
+}
@@ -0,0 +1,83 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Stats(t *testing.T) {
+	t.Run("empty log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(10))
+		assert.NilError(t, err)
+
+		stats := l.Stats(ctx)
+		assert.Equal(t, stats.Records, 0)
+		assert.Equal(t, stats.Bytes, 0)
+		assert.Equal(t, stats.Writes, uint64(0))
+		assert.Equal(t, stats.Purged, uint64(0))
+		assert.Equal(t, len(stats.Segments), 1)
+		assert.Equal(t, stats.Segments[0].Capacity, 10)
+	})
+
+	t.Run("counts writes and purges", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(10))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 25)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		stats := l.Stats(ctx)
+		assert.Equal(t, stats.Records, 15)
+		assert.Equal(t, stats.Bytes, l.RetainedBytes())
+		assert.Equal(t, stats.Writes, uint64(25))
+		assert.Equal(t, stats.Purged, uint64(10))
+		assert.Equal(t, len(stats.Segments), 2)
+		assert.Equal(t, stats.Segments[0].Records, 10)
+		assert.Equal(t, stats.Segments[1].Records, 5)
+
+		assert.NilError(t, l.Trim(ctx, 12))
+		stats = l.Stats(ctx)
+		assert.Equal(t, stats.Records, 13)
+		assert.Equal(t, stats.Purged, uint64(12))
+	})
+
+	t.Run("excludes gaps of sparse offsets", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(10), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{0, 3, 7} {
+			_, err = l.WriteAt(ctx, offset, newTestData(t, "sparse"))
+			assert.NilError(t, err)
+		}
+
+		stats := l.Stats(ctx)
+		assert.Equal(t, stats.Records, 3)
+		assert.Equal(t, stats.Writes, uint64(3))
+		assert.Equal(t, stats.Segments[0].Records, 8)
+	})
+	t.Run("counts alive and dead consumers", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithConsumerTimeout(time.Second*10))
+		assert.NilError(t, err)
+
+		assert.NilError(t, l.Heartbeat(ctx, "billing"))
+		mockClock.Add(time.Second * 11)
+		assert.NilError(t, l.Heartbeat(ctx, "audit"))
+		assert.NilError(t, l.Heartbeat(ctx, "metrics"))
+
+		stats := l.Stats(ctx)
+		assert.Equal(t, stats.AliveConsumers, 2)
+		assert.Equal(t, stats.DeadConsumers, 1)
+	})
+}
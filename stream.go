@@ -3,6 +3,8 @@ package memlog
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 	"time"
 )
 
@@ -14,17 +16,131 @@ const (
 // ErrSlowReader is returned by a stream when the stream buffer is full
 var ErrSlowReader = errors.New("slow reader blocking stream channel send")
 
+// StreamHeader is metadata of a streamed record describing the log at the
+// time the record was read
 type StreamHeader struct {
 	Earliest Offset
 	Latest   Offset
 }
 
+// StreamRecord is a record delivered by a stream
 type StreamRecord struct {
 	Metadata StreamHeader
 	Record   Record
 }
 
-func (l *Log) Stream(ctx context.Context, start Offset) (<-chan StreamRecord, <-chan error) {
+// StreamOption customizes a stream
+type StreamOption func(*streamConfig) error
+
+type streamConfig struct {
+	checkpoint *streamCheckpoint // optional
+	sample     func() bool       // optional, true if a record is delivered
+	pace       time.Duration     // optional, minimum duration between records
+	owner      string            // optional, subscription owner
+	bookmark   string            // optional, start bookmark
+}
+
+// WithStreamCheckpoint persists the offset of the last record received from the
+// stream channel under the given consumer name every n records or after the
+// given interval, whichever comes first. A zero value disables the respective
+// trigger. Records buffered in the stream channel are not checkpointed until
+// the receiver reads them. When a checkpoint exists for the consumer, the
+// stream resumes after the checkpoint instead of the specified start offset,
+// i.e. a crashed subscriber resumes within a bounded window of records. If the
+// checkpoint was created against another log incarnation (see Log.ID()), the
+// stream terminates with ErrLogMismatch.
+func WithStreamCheckpoint(store CheckpointStore, consumer string, n int, interval time.Duration) StreamOption {
+	return func(sc *streamConfig) error {
+		if store == nil {
+			return errors.New("checkpoint store must not be nil")
+		}
+
+		if consumer == "" {
+			return errors.New("consumer name must not be empty")
+		}
+
+		if n < 0 || interval < 0 {
+			return errors.New("checkpoint triggers must not be negative")
+		}
+
+		if n == 0 && interval == 0 {
+			return errors.New("at least one checkpoint trigger must be specified")
+		}
+
+		sc.checkpoint = &streamCheckpoint{
+			store:    store,
+			consumer: consumer,
+			every:    n,
+			interval: interval,
+			last:     -1,
+		}
+		return nil
+	}
+}
+
+// WithStreamSampleEvery delivers only every n-th record of the stream starting
+// with the first record, e.g. for monitoring dashboards which need a
+// representative feed instead of every record
+func WithStreamSampleEvery(n int) StreamOption {
+	return func(sc *streamConfig) error {
+		if n <= 0 {
+			return errors.New("sample interval must be greater than 0")
+		}
+
+		count := 0
+		sc.sample = func() bool {
+			deliver := count%n == 0
+			count++
+			return deliver
+		}
+		return nil
+	}
+}
+
+// WithStreamSampleRate delivers each record of the stream with the given
+// probability in the range (0, 1]
+func WithStreamSampleRate(p float64) StreamOption {
+	return func(sc *streamConfig) error {
+		if p <= 0 || p > 1 {
+			return errors.New("sample rate must be in the range (0, 1]")
+		}
+
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+		sc.sample = func() bool {
+			return rnd.Float64() < p
+		}
+		return nil
+	}
+}
+
+// WithStreamRateLimit limits the delivery rate of the stream to the given
+// number of records per second, e.g. to feed downstream systems with strict
+// ingest limits while the log absorbs bursts. Note that a stream delivers at
+// most one record per poll interval (10ms).
+func WithStreamRateLimit(recordsPerSec float64) StreamOption {
+	return func(sc *streamConfig) error {
+		if recordsPerSec <= 0 {
+			return errors.New("rate limit must be greater than 0")
+		}
+
+		sc.pace = time.Duration(float64(time.Second) / recordsPerSec)
+		return nil
+	}
+}
+
+// Stream streams records starting at the given offset. The stream replays
+// records from the given offset and then tails new writes until the context is
+// cancelled or an error occurs, e.g. when the stream offset has been purged
+// (ErrOutOfRange) or the receiver does not keep up with the stream
+// (ErrSlowReader).
+//
+// The terminating error is sent on the returned error channel and both
+// channels are closed afterwards.
+//
+// Active streams are reported as subscriptions in Stats(). Streams are
+// force-closed with ErrSubscriptionClosed when the log is closed, e.g. deleted
+// from a Manager.
+func (l *Log) Stream(ctx context.Context, start Offset, options ...StreamOption) (<-chan StreamRecord, <-chan error) {
 	var (
 		streamCh = make(chan StreamRecord, streamBuffer)
 
@@ -33,6 +149,28 @@ func (l *Log) Stream(ctx context.Context, start Offset) (<-chan StreamRecord, <-
 		errCh = make(chan error)
 	)
 
+	var (
+		sc     streamConfig
+		optErr error
+	)
+	for _, opt := range options {
+		if optErr = opt(&sc); optErr != nil {
+			optErr = fmt.Errorf("configure stream option: %w", optErr)
+			break
+		}
+	}
+
+	if optErr == nil && sc.bookmark != "" {
+		l.mu.RLock()
+		start, optErr = l.bookmark(sc.bookmark)
+		l.mu.RUnlock()
+	}
+
+	var sub *subscription
+	if optErr == nil {
+		sub = l.subscriptions.register(sc.owner, start, l.clock.Now())
+	}
+
 	go func() {
 		ticker := time.NewTicker(streamPollInterval)
 		defer func() {
@@ -41,17 +179,62 @@ func (l *Log) Stream(ctx context.Context, start Offset) (<-chan StreamRecord, <-
 			ticker.Stop()
 		}()
 
+		if optErr != nil {
+			errCh <- optErr
+			return
+		}
+		defer l.subscriptions.unregister(sub)
+
+		// terminate persists pending stream progress and sends the terminating
+		// error
+		terminate := func(err error) {
+			if sc.checkpoint != nil {
+				sc.checkpoint.flush(len(streamCh), l.clock.Now())
+			}
+			errCh <- err
+		}
+
+		var lastSent time.Time // rate limit
 		offset := start
+		if sc.checkpoint != nil {
+			sc.checkpoint.log = l
+			resume, err := sc.checkpoint.resume(ctx, start)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			offset = resume
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
-				errCh <- ctx.Err()
+				terminate(ctx.Err())
+				return
+
+			case <-sub.closed:
+				// force-closed, do not leak the goroutine if the receiver is gone
+				if sc.checkpoint != nil {
+					sc.checkpoint.flush(len(streamCh), l.clock.Now())
+				}
+
+				timer := time.NewTimer(streamCloseTimeout)
+				select {
+				case errCh <- ErrSubscriptionClosed:
+				case <-timer.C:
+				}
+				timer.Stop()
 				return
 
 			case <-ticker.C:
-				sendOne := func() error {
+				if sc.pace > 0 && !lastSent.IsZero() && l.clock.Since(lastSent) < sc.pace {
+					continue
+				}
+
+				// readOne reads the next record to send
+				readOne := func() (StreamRecord, bool, error) {
 					if len(streamCh) == streamBuffer {
-						return ErrSlowReader
+						return StreamRecord{}, false, ErrSlowReader
 					}
 
 					l.mu.RLock()
@@ -59,13 +242,24 @@ func (l *Log) Stream(ctx context.Context, start Offset) (<-chan StreamRecord, <-
 
 					earliest, latest := l.offsetRange()
 					r, err := l.read(ctx, offset)
+					for err == nil && sc.sample != nil && !sc.sample() {
+						// skip records not sampled
+						offset = r.Metadata.Offset + 1
+						r, err = l.read(ctx, offset)
+					}
+
 					if err != nil {
 						if errors.Is(err, ErrFutureOffset) {
 							// continue polling
-							return nil
+							return StreamRecord{}, false, nil
+						}
+
+						if errors.Is(err, ErrOffsetGap) {
+							offset = l.nextWritten(offset)
+							return StreamRecord{}, false, nil
 						}
 
-						return err
+						return StreamRecord{}, false, err
 					}
 
 					rec := StreamRecord{
@@ -76,19 +270,146 @@ func (l *Log) Stream(ctx context.Context, start Offset) (<-chan StreamRecord, <-
 						Record: r,
 					}
 
-					streamCh <- rec
-					offset = r.Metadata.Offset + 1
+					if l.latency != nil {
+						l.latency.stream.record(l.clock.Since(r.Metadata.Created))
+					}
 
-					return nil
+					return rec, true, nil
 				}
 
-				if err := sendOne(); err != nil {
-					errCh <- err
+				rec, sent, err := readOne()
+				if err != nil {
+					terminate(err)
 					return
 				}
+
+				if sent {
+					// records are not handed out without access evidence
+					if err = l.recordAccess(ctx, sc.owner, rec.Record.Metadata.Offset); err != nil {
+						terminate(err)
+						return
+					}
+
+					streamCh <- rec
+					offset = rec.Record.Metadata.Offset + 1
+				}
+
+				if sent && sc.pace > 0 {
+					lastSent = l.clock.Now()
+				}
+
+				if sc.checkpoint != nil {
+					if sent {
+						sc.checkpoint.sent(offset - 1)
+					}
+
+					// also saves pending progress of an idle stream
+					if err = sc.checkpoint.received(ctx, len(streamCh), l.clock.Now()); err != nil {
+						terminate(err)
+						return
+					}
+				}
 			}
 		}
 	}()
 
 	return streamCh, errCh
 }
+
+// streamCheckpoint tracks and persists the position of a stream
+type streamCheckpoint struct {
+	log      *Log
+	store    CheckpointStore
+	consumer string
+	every    int
+	interval time.Duration
+
+	inFlight []Offset  // offsets sent but not yet received, oldest first
+	last     Offset    // last received offset
+	pending  int       // received records since last save
+	lastSave time.Time // time of last save
+}
+
+// resume returns the offset following the stored checkpoint or start if no
+// checkpoint exists
+func (c *streamCheckpoint) resume(ctx context.Context, start Offset) (Offset, error) {
+	checkpoint, err := c.store.Load(ctx, c.consumer)
+	if errors.Is(err, ErrCheckpointNotFound) {
+		return start, nil
+	}
+	if err != nil {
+		return -1, fmt.Errorf("load checkpoint: %w", err)
+	}
+
+	if err = checkpoint.verify(c.log); err != nil {
+		return -1, err
+	}
+
+	c.last = checkpoint.Offset
+	return checkpoint.Offset + 1, nil
+}
+
+// sent records the offset of a record sent to the stream channel
+func (c *streamCheckpoint) sent(offset Offset) {
+	c.inFlight = append(c.inFlight, offset)
+}
+
+// received records the records read by the receiver, given the number of
+// records still buffered in the stream channel, and saves a checkpoint if a
+// trigger fires
+func (c *streamCheckpoint) received(ctx context.Context, buffered int, now time.Time) error {
+	c.advance(buffered, now)
+
+	if c.pending == 0 {
+		return nil
+	}
+
+	if (c.every > 0 && c.pending >= c.every) || (c.interval > 0 && now.Sub(c.lastSave) >= c.interval) {
+		return c.save(ctx, now)
+	}
+	return nil
+}
+
+// advance moves the last received offset past the records no longer buffered
+// in the stream channel
+func (c *streamCheckpoint) advance(buffered int, now time.Time) {
+	n := len(c.inFlight) - buffered
+	if n <= 0 {
+		return
+	}
+
+	offset := c.inFlight[n-1]
+	c.inFlight = c.inFlight[n:]
+	if offset <= c.last {
+		return
+	}
+
+	if c.pending == 0 {
+		c.lastSave = now
+	}
+	c.last = offset
+	c.pending += n
+}
+
+func (c *streamCheckpoint) save(ctx context.Context, now time.Time) error {
+	checkpoint := Checkpoint{
+		LogID:  c.log.ID(),
+		Offset: c.last,
+	}
+	if err := c.store.Save(ctx, c.consumer, checkpoint); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	c.pending = 0
+	c.lastSave = now
+	return nil
+}
+
+// flush saves pending progress of received records when the stream terminates.
+// Errors are ignored since the stream has already terminated with an error.
+func (c *streamCheckpoint) flush(buffered int, now time.Time) {
+	c.advance(buffered, now)
+	if c.pending > 0 {
+		_ = c.save(context.Background(), now)
+	}
+}
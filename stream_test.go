@@ -290,4 +290,212 @@ func TestLog_Stream(t *testing.T) {
 		streamErr := <-errCh
 		assert.Assert(t, errors.Is(streamErr, ErrSlowReader))
 	})
+
+	t.Run("stream persists checkpoints and resumes after last checkpoint", func(t *testing.T) {
+		t.Parallel()
+
+		const records = 30
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx, WithMaxSegmentSize(records))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, records) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		streamCh, errCh := l.Stream(ctx, 0, WithStreamCheckpoint(store, "subscriber", 10, 0))
+
+		counter := 0
+	LOOP:
+		for {
+			select {
+			case <-streamCh:
+				counter++
+				if counter == 10 {
+					// wait for checkpoint after 10 records
+					for checkpoint, loadErr := store.Load(ctx, "subscriber"); loadErr != nil || checkpoint.Offset != 9; checkpoint, loadErr = store.Load(ctx, "subscriber") {
+						time.Sleep(time.Millisecond)
+					}
+				}
+				if counter == 25 {
+					cancel()
+				}
+			case streamErr := <-errCh:
+				assert.Assert(t, errors.Is(streamErr, context.Canceled))
+				break LOOP
+			}
+		}
+
+		// pending progress flushed on termination
+		checkpoint, err := store.Load(context.Background(), "subscriber")
+		assert.NilError(t, err)
+		assert.Equal(t, checkpoint.Offset, Offset(counter-1))
+		assert.Equal(t, checkpoint.LogID, l.ID())
+
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+
+		streamCh, _ = l.Stream(ctx, 0, WithStreamCheckpoint(store, "subscriber", 10, 0))
+		r := <-streamCh
+		assert.Equal(t, r.Record.Metadata.Offset, checkpoint.Offset+1)
+
+		// log recreated, e.g. after a crash
+		l, err = New(ctx)
+		assert.NilError(t, err)
+
+		_, errCh = l.Stream(ctx, 0, WithStreamCheckpoint(store, "subscriber", 10, 0))
+		assert.Assert(t, errors.Is(<-errCh, ErrLogMismatch))
+	})
+
+	t.Run("stream does not checkpoint records buffered in stream channel", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		_, errCh := l.Stream(ctx, 0, WithStreamCheckpoint(store, "subscriber", 1, 0))
+
+		// records are sent to the stream channel but never received
+		time.Sleep(streamPollInterval * 5)
+		cancel()
+		assert.Assert(t, errors.Is(<-errCh, context.Canceled))
+
+		_, err = store.Load(context.Background(), "subscriber")
+		assert.Assert(t, errors.Is(err, ErrCheckpointNotFound))
+	})
+
+	t.Run("stream checkpoints received records of idle stream after interval", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 2) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		store := NewMemoryCheckpointStore()
+		streamCh, _ := l.Stream(ctx, 0, WithStreamCheckpoint(store, "subscriber", 100, streamPollInterval*2))
+		<-streamCh
+		<-streamCh
+
+		// no more records are delivered
+		for checkpoint, loadErr := store.Load(ctx, "subscriber"); loadErr != nil || checkpoint.Offset != 1; checkpoint, loadErr = store.Load(ctx, "subscriber") {
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("stream fails with invalid checkpoint option", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, errCh := l.Stream(ctx, 0, WithStreamCheckpoint(NewMemoryCheckpointStore(), "subscriber", 0, 0))
+		assert.ErrorContains(t, <-errCh, "at least one checkpoint trigger")
+	})
+
+	t.Run("stream delivers every n-th record", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		streamCh, _ := l.Stream(ctx, 0, WithStreamSampleEvery(3))
+		for _, want := range []Offset{0, 3, 6, 9} {
+			r := <-streamCh
+			assert.Equal(t, r.Record.Metadata.Offset, want)
+		}
+	})
+
+	t.Run("stream delivers sampled records", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		// all records sampled
+		streamCh, _ := l.Stream(ctx, 0, WithStreamSampleRate(1))
+		for want := Offset(0); want < 10; want++ {
+			r := <-streamCh
+			assert.Equal(t, r.Record.Metadata.Offset, want)
+		}
+	})
+
+	t.Run("stream fails with invalid sample option", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, errCh := l.Stream(ctx, 0, WithStreamSampleEvery(0))
+		assert.ErrorContains(t, <-errCh, "greater than 0")
+
+		_, errCh = l.Stream(ctx, 0, WithStreamSampleRate(1.5))
+		assert.ErrorContains(t, <-errCh, "range (0, 1]")
+	})
+
+	t.Run("stream delivers records at limited rate", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 5) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		_, errCh := l.Stream(ctx, 0, WithStreamRateLimit(0))
+		assert.ErrorContains(t, <-errCh, "greater than 0")
+
+		start := time.Now()
+		streamCh, _ := l.Stream(ctx, 0, WithStreamRateLimit(20))
+		for want := Offset(0); want < 5; want++ {
+			r := <-streamCh
+			assert.Equal(t, r.Record.Metadata.Offset, want)
+		}
+
+		// 4 intervals of 50ms between 5 records
+		assert.Assert(t, time.Since(start) >= time.Millisecond*200)
+	})
 }
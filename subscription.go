@@ -0,0 +1,141 @@
+package memlog
+
+import (
+	"errors"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// streamCloseTimeout is the time a force-closed stream waits for the receiver
+// to accept the terminating error before the stream goroutine exits
+const streamCloseTimeout = time.Second
+
+// ErrSubscriptionClosed is returned by a stream which has been force-closed,
+// e.g. because its log was closed
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// SubscriptionInfo describes an active subscription of a log, i.e. a running
+// stream, see Stats()
+type SubscriptionInfo struct {
+	// ID is a unique identifier of the subscription within the log
+	ID uint64 `json:"id"`
+	// Owner is the owner of the subscription, see WithStreamOwner()
+	Owner string `json:"owner,omitempty"`
+	// Start is the start offset of the subscription
+	Start Offset `json:"start"`
+	// Created is the time the subscription was created
+	Created time.Time `json:"created"`
+	// Stack is the stack trace of the goroutine creating the subscription.
+	// Only recorded with WithDebugSubscriptions().
+	Stack string `json:"stack,omitempty"`
+}
+
+// WithStreamOwner sets the owner of the stream subscription reported in
+// Stats(), e.g. the name of the component creating the stream to find
+// subscriptions which are never cancelled
+func WithStreamOwner(owner string) StreamOption {
+	return func(sc *streamConfig) error {
+		if owner == "" {
+			return errors.New("owner must not be empty")
+		}
+
+		sc.owner = owner
+		return nil
+	}
+}
+
+// WithDebugSubscriptions records the stack trace of the goroutine creating a
+// stream subscription, which is reported in Stats() to find the origin of
+// leaked subscriptions. Capturing stack traces is expensive and should not be
+// enabled for logs with frequently created streams.
+func WithDebugSubscriptions() Option {
+	return func(log *Log) error {
+		log.conf.debugSubscriptions = true
+		return nil
+	}
+}
+
+// subscriptionRegistry tracks the active subscriptions of a log. Safe for
+// concurrent use.
+type subscriptionRegistry struct {
+	mu     sync.Mutex
+	debug  bool // record stack traces
+	nextID uint64
+	active map[uint64]*subscription
+}
+
+// subscription is an active stream subscription
+type subscription struct {
+	info   SubscriptionInfo
+	closed chan struct{} // closed on force-close
+}
+
+func newSubscriptionRegistry(debug bool) *subscriptionRegistry {
+	return &subscriptionRegistry{
+		debug:  debug,
+		active: make(map[uint64]*subscription),
+	}
+}
+
+// register adds a new subscription with the given owner and start offset
+func (r *subscriptionRegistry) register(owner string, start Offset, now time.Time) *subscription {
+	var stack string
+	if r.debug {
+		stack = string(debug.Stack())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	s := &subscription{
+		info: SubscriptionInfo{
+			ID:      r.nextID,
+			Owner:   owner,
+			Start:   start,
+			Created: now,
+			Stack:   stack,
+		},
+		closed: make(chan struct{}),
+	}
+	r.active[s.info.ID] = s
+	return s
+}
+
+// unregister removes the given subscription
+func (r *subscriptionRegistry) unregister(s *subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.active, s.info.ID)
+}
+
+// closeAll force-closes all active subscriptions, which terminate with
+// ErrSubscriptionClosed
+func (r *subscriptionRegistry) closeAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, s := range r.active {
+		close(s.closed)
+		delete(r.active, id)
+	}
+}
+
+// list returns the active subscriptions ordered by ID
+func (r *subscriptionRegistry) list() []SubscriptionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subs := make([]SubscriptionInfo, 0, len(r.active))
+	for _, s := range r.active {
+		subs = append(subs, s.info)
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].ID < subs[j].ID
+	})
+	return subs
+}
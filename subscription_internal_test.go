@@ -0,0 +1,83 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+func TestLog_Subscriptions(t *testing.T) {
+	t.Run("tracks active subscriptions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx, WithDebugSubscriptions())
+		assert.NilError(t, err)
+
+		streamCtx, streamCancel := context.WithCancel(ctx)
+		_, errCh := l.Stream(streamCtx, 0, WithStreamOwner("indexer"))
+		_, _ = l.Stream(ctx, 0)
+
+		subs := l.Stats(ctx).Subscriptions
+		assert.Equal(t, len(subs), 2)
+		assert.Equal(t, subs[0].Owner, "indexer")
+		assert.Equal(t, subs[0].Start, Offset(0))
+		assert.Assert(t, strings.Contains(subs[0].Stack, "TestLog_Subscriptions"))
+		assert.Equal(t, subs[1].Owner, "")
+
+		streamCancel()
+		assert.Assert(t, errors.Is(<-errCh, context.Canceled))
+
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if len(l.Stats(ctx).Subscriptions) == 1 {
+				return poll.Success()
+			}
+			return poll.Continue("waiting for subscription to be removed")
+		}, poll.WithTimeout(time.Second))
+	})
+
+	t.Run("does not record stack without debug", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, _ = l.Stream(ctx, 0)
+		subs := l.Stats(ctx).Subscriptions
+		assert.Equal(t, len(subs), 1)
+		assert.Equal(t, subs[0].Stack, "")
+	})
+
+	t.Run("invalid stream option does not register", func(t *testing.T) {
+		ctx := context.Background()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, errCh := l.Stream(ctx, 0, WithStreamOwner(""))
+		assert.ErrorContains(t, <-errCh, "owner must not be empty")
+		assert.Equal(t, len(l.Stats(ctx).Subscriptions), 0)
+	})
+
+	t.Run("force-closes subscriptions on delete", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		m, err := NewManager()
+		assert.NilError(t, err)
+		l, err := m.Create(ctx, "topic")
+		assert.NilError(t, err)
+
+		_, errCh := l.Stream(ctx, 0, WithStreamOwner("leaked"))
+		assert.NilError(t, m.Delete(ctx, "topic"))
+
+		assert.Assert(t, errors.Is(<-errCh, ErrSubscriptionClosed))
+		assert.Equal(t, len(l.Stats(ctx).Subscriptions), 0)
+	})
+}
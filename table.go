@@ -0,0 +1,174 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// UpdateKind describes a table update delivered by SubscribeTable()
+type UpdateKind int
+
+const (
+	// UpdateSnapshot is the latest record of a key at the time of subscribing
+	UpdateSnapshot UpdateKind = iota
+	// UpdateSnapshotEnd marks the end of the snapshot. All following updates
+	// are live changes.
+	UpdateSnapshotEnd
+	// UpdateLive is a record written after the snapshot
+	UpdateLive
+)
+
+func (k UpdateKind) String() string {
+	switch k {
+	case UpdateSnapshot:
+		return "snapshot"
+	case UpdateSnapshotEnd:
+		return "snapshotEnd"
+	case UpdateLive:
+		return "live"
+	default:
+		return fmt.Sprintf("updateKind(%d)", int(k))
+	}
+}
+
+// TableUpdate is an update of the compacted table of a log delivered by
+// SubscribeTable()
+type TableUpdate struct {
+	Kind UpdateKind
+	// Record is the updated record. Empty for UpdateSnapshotEnd.
+	Record Record
+	// Offset is the offset of the first live update, i.e. the next write offset
+	// of the log when the snapshot was taken. Only set for UpdateSnapshotEnd.
+	Offset Offset
+}
+
+// SubscribeTable subscribes to the compacted table of the log, i.e. the latest
+// record per key. The subscription first delivers a consistent snapshot of the
+// latest record of every key retained in the log, including compacted records
+// (see WithCompaction()), ordered by offset, followed by an UpdateSnapshotEnd
+// marker. Afterwards, new records are delivered as live updates, e.g. to warm
+// a cache before serving changes. Records without a key are not part of the
+// table and skipped.
+//
+// Live updates are streamed with Stream() using the given stream options. If
+// records written after the snapshot are purged before the snapshot has been
+// received, the subscription terminates with ErrOutOfRange.
+//
+// The terminating error is sent on the returned error channel and both
+// channels are closed afterwards.
+//
+// Safe for concurrent use.
+func (l *Log) SubscribeTable(ctx context.Context, options ...StreamOption) (<-chan TableUpdate, <-chan error) {
+	var (
+		updateCh = make(chan TableUpdate, streamBuffer)
+		errCh    = make(chan error)
+	)
+
+	snapshot, next, err := l.tableSnapshot(ctx)
+
+	go func() {
+		defer func() {
+			close(updateCh)
+			close(errCh)
+		}()
+
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		send := func(u TableUpdate) bool {
+			select {
+			case updateCh <- u:
+				return true
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return false
+			}
+		}
+
+		for _, r := range snapshot {
+			if !send(TableUpdate{Kind: UpdateSnapshot, Record: r}) {
+				return
+			}
+		}
+
+		if !send(TableUpdate{Kind: UpdateSnapshotEnd, Offset: next}) {
+			return
+		}
+
+		stream, errs := l.Stream(ctx, next, options...)
+		for {
+			select {
+			case r, ok := <-stream:
+				if !ok {
+					// stream terminated, wait for its error
+					stream = nil
+					continue
+				}
+
+				if len(r.Record.Metadata.Key) == 0 {
+					continue
+				}
+
+				if !send(TableUpdate{Kind: UpdateLive, Record: r.Record}) {
+					return
+				}
+
+			case err, ok := <-errs:
+				if ok {
+					errCh <- err
+				}
+				return
+			}
+		}
+	}()
+
+	return updateCh, errCh
+}
+
+// tableSnapshot returns the latest record of every key retained or compacted
+// in the log ordered by offset and the next write offset of the log
+func (l *Log) tableSnapshot(ctx context.Context) ([]Record, Offset, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	next := l.offset
+	earliest, latest := l.offsetRange()
+	if latest == -1 {
+		return nil, next, nil
+	}
+
+	latestByKey := make(map[string]Record)
+	for _, r := range l.compactedHistory() {
+		latestByKey[string(r.Metadata.Key)] = r
+	}
+
+	for offset := earliest; offset <= latest; offset++ {
+		r, err := l.read(ctx, offset)
+		if err != nil {
+			if errors.Is(err, ErrOffsetGap) {
+				offset = l.nextWritten(offset) - 1
+				continue
+			}
+			return nil, -1, fmt.Errorf("snapshot table: %w", err)
+		}
+
+		if len(r.Metadata.Key) == 0 {
+			continue
+		}
+		latestByKey[string(r.Metadata.Key)] = r
+	}
+
+	snapshot := make([]Record, 0, len(latestByKey))
+	for _, r := range latestByKey {
+		snapshot = append(snapshot, r)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Metadata.Offset < snapshot[j].Metadata.Offset
+	})
+
+	return snapshot, next, nil
+}
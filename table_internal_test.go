@@ -0,0 +1,89 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_SubscribeTable(t *testing.T) {
+	t.Run("delivers snapshot followed by live updates", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		writes := []struct {
+			key  string
+			data string
+		}{
+			{key: "a", data: "a1"},
+			{key: "b", data: "b1"},
+			{data: "unkeyed"},
+			{key: "a", data: "a2"},
+		}
+		for _, w := range writes {
+			var opts []WriteOption
+			if w.key != "" {
+				opts = append(opts, WithKey([]byte(w.key)))
+			}
+			_, err = l.Write(ctx, []byte(w.data), opts...)
+			assert.NilError(t, err)
+		}
+
+		updateCh, _ := l.SubscribeTable(ctx)
+
+		u := <-updateCh
+		assert.Equal(t, u.Kind, UpdateSnapshot)
+		assert.Equal(t, string(u.Record.Data), "b1")
+
+		u = <-updateCh
+		assert.Equal(t, u.Kind, UpdateSnapshot)
+		assert.Equal(t, string(u.Record.Data), "a2")
+
+		u = <-updateCh
+		assert.Equal(t, u.Kind, UpdateSnapshotEnd)
+		assert.Equal(t, u.Offset, Offset(4))
+
+		_, err = l.Write(ctx, []byte("unkeyed"))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("b2"), WithKey([]byte("b")))
+		assert.NilError(t, err)
+
+		u = <-updateCh
+		assert.Equal(t, u.Kind, UpdateLive)
+		assert.Equal(t, string(u.Record.Data), "b2")
+		assert.Equal(t, u.Record.Metadata.Offset, Offset(5))
+	})
+
+	t.Run("delivers marker for empty log", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx, WithStartOffset(10))
+		assert.NilError(t, err)
+
+		updateCh, errCh := l.SubscribeTable(ctx)
+		u := <-updateCh
+		assert.Equal(t, u.Kind, UpdateSnapshotEnd)
+		assert.Equal(t, u.Offset, Offset(10))
+
+		cancel()
+		assert.Assert(t, errors.Is(<-errCh, context.Canceled))
+	})
+
+	t.Run("fails with invalid stream option", func(t *testing.T) {
+		ctx := context.Background()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		updateCh, errCh := l.SubscribeTable(ctx, WithStreamSampleEvery(0))
+		u := <-updateCh
+		assert.Equal(t, u.Kind, UpdateSnapshotEnd)
+		assert.ErrorContains(t, <-errCh, "configure stream option")
+	})
+}
@@ -0,0 +1,213 @@
+package memlog
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	tagName         = "memlog"
+	tagKey          = "key"
+	tagHeaderPrefix = "header="
+)
+
+// MarshalTagged encodes the struct v (or a pointer to it) into a record using
+// the given codec. The record key and headers are derived from struct fields
+// with a memlog tag:
+//
+//	type Order struct {
+//		ID     string `json:"id" memlog:"key"`
+//		Tenant string `json:"tenant" memlog:"header=tenant"`
+//	}
+//
+// Tagged fields must be of type string, []byte or implement
+// encoding.TextMarshaler. Empty values are omitted. The record data is the
+// codec encoding of the full struct, i.e. fields should be excluded explicitly
+// from the codec if they must not be part of the data.
+func MarshalTagged(codec Codec, v interface{}) (Record, error) {
+	if codec == nil {
+		return Record{}, errors.New("codec must not be nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return Record{}, errors.New("value must not be nil")
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return Record{}, fmt.Errorf("value must be a struct, got %s", rv.Kind())
+	}
+
+	var r Record
+	err := walkTagged(rv, func(f reflect.Value, name, header string) error {
+		b, err := taggedBytes(f)
+		if err != nil {
+			return fmt.Errorf("field %q: %v", name, err)
+		}
+
+		if len(b) == 0 {
+			return nil
+		}
+
+		if header == "" {
+			r.Metadata.Key = b
+			return nil
+		}
+
+		if r.Metadata.Headers == nil {
+			r.Metadata.Headers = make(map[string][]byte)
+		}
+		r.Metadata.Headers[header] = b
+		return nil
+	})
+	if err != nil {
+		return Record{}, err
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return Record{}, fmt.Errorf("marshal value: %v", err)
+	}
+	r.Data = data
+
+	return r, nil
+}
+
+// UnmarshalTagged decodes the record data into the struct pointer v using the
+// given codec. Fields with a memlog tag are populated from the record key and
+// headers, overwriting values decoded from the data. See MarshalTagged for the
+// supported tags.
+func UnmarshalTagged(codec Codec, r Record, v interface{}) error {
+	if codec == nil {
+		return errors.New("codec must not be nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("value must be a non-nil pointer to a struct")
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("value must be a pointer to a struct, got %s", rv.Kind())
+	}
+
+	if err := codec.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("unmarshal data: %v", err)
+	}
+
+	return walkTagged(rv, func(f reflect.Value, name, header string) error {
+		b := r.Metadata.Key
+		if header != "" {
+			b = r.Metadata.Headers[header]
+		}
+
+		if b == nil {
+			return nil
+		}
+
+		if err := setTaggedBytes(f, b); err != nil {
+			return fmt.Errorf("field %q: %v", name, err)
+		}
+		return nil
+	})
+}
+
+// WriteTagged encodes v with MarshalTagged and writes the resulting record,
+// including its key and headers, to the log.
+//
+// Safe for concurrent use.
+func (l *Log) WriteTagged(ctx context.Context, codec Codec, v interface{}, options ...WriteOption) (Offset, error) {
+	r, err := MarshalTagged(codec, v)
+	if err != nil {
+		return -1, err
+	}
+
+	opts := []WriteOption{WithKey(r.Metadata.Key), WithHeaders(r.Metadata.Headers)}
+	return l.Write(ctx, r.Data, append(opts, options...)...)
+}
+
+// walkTagged calls fn for every exported field of the struct rv with a memlog
+// tag. header is empty for the key field.
+func walkTagged(rv reflect.Value, fn func(f reflect.Value, name, header string) error) error {
+	rt := rv.Type()
+	hasKey := false
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			return fmt.Errorf("field %q: tagged field must be exported", sf.Name)
+		}
+
+		var header string
+		switch {
+		case tag == tagKey:
+			if hasKey {
+				return fmt.Errorf("field %q: duplicate key tag", sf.Name)
+			}
+			hasKey = true
+		case strings.HasPrefix(tag, tagHeaderPrefix):
+			header = strings.TrimPrefix(tag, tagHeaderPrefix)
+			if header == "" {
+				return fmt.Errorf("field %q: header name must not be empty", sf.Name)
+			}
+		default:
+			return fmt.Errorf("field %q: invalid tag %q", sf.Name, tag)
+		}
+
+		if err := fn(rv.Field(i), sf.Name, header); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var (
+	bytesType           = reflect.TypeOf([]byte(nil))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func taggedBytes(f reflect.Value) ([]byte, error) {
+	switch {
+	case f.Type().Implements(textMarshalerType):
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			return nil, nil
+		}
+		return f.Interface().(encoding.TextMarshaler).MarshalText()
+	case f.Kind() == reflect.String:
+		return []byte(f.String()), nil
+	case f.Type() == bytesType:
+		return copyBytes(f.Bytes()), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", f.Type())
+	}
+}
+
+func setTaggedBytes(f reflect.Value, b []byte) error {
+	switch {
+	case f.CanAddr() && f.Addr().Type().Implements(textUnmarshalerType):
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(b)
+	case f.Kind() == reflect.String:
+		f.SetString(string(b))
+		return nil
+	case f.Type() == bytesType:
+		f.SetBytes(copyBytes(b))
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+}
@@ -0,0 +1,103 @@
+package memlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type taggedOrder struct {
+	ID      string    `json:"id" memlog:"key"`
+	Tenant  string    `json:"tenant" memlog:"header=tenant"`
+	Trace   []byte    `json:"-" memlog:"header=trace-id"`
+	Placed  time.Time `json:"placed" memlog:"header=placed"`
+	Comment string    `json:"comment"`
+}
+
+func TestMarshalTagged(t *testing.T) {
+	t.Run("fails with invalid input", func(t *testing.T) {
+		type badTag struct {
+			ID string `memlog:"primary"`
+		}
+		type badType struct {
+			ID int `memlog:"key"`
+		}
+		type duplicateKey struct {
+			A string `memlog:"key"`
+			B string `memlog:"key"`
+		}
+		type emptyHeader struct {
+			A string `memlog:"header="`
+		}
+
+		testCases := []struct {
+			name  string
+			codec Codec
+			value interface{}
+			error string
+		}{
+			{name: "nil codec", codec: nil, value: taggedOrder{}, error: "codec must not be nil"},
+			{name: "nil value", codec: JSONCodec{}, value: (*taggedOrder)(nil), error: "must not be nil"},
+			{name: "not a struct", codec: JSONCodec{}, value: "order", error: "must be a struct"},
+			{name: "invalid tag", codec: JSONCodec{}, value: badTag{}, error: "invalid tag"},
+			{name: "unsupported type", codec: JSONCodec{}, value: badType{}, error: "unsupported type"},
+			{name: "duplicate key", codec: JSONCodec{}, value: duplicateKey{}, error: "duplicate key"},
+			{name: "empty header name", codec: JSONCodec{}, value: emptyHeader{}, error: "must not be empty"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := MarshalTagged(tc.codec, tc.value)
+				assert.ErrorContains(t, err, tc.error)
+			})
+		}
+	})
+
+	t.Run("derives key and headers from tagged fields", func(t *testing.T) {
+		placed := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+		o := taggedOrder{ID: "order-1", Tenant: "acme", Trace: []byte("abc"), Placed: placed}
+
+		r, err := MarshalTagged(JSONCodec{}, &o)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, r.Metadata.Key, []byte("order-1"))
+		assert.DeepEqual(t, r.Metadata.Headers, map[string][]byte{
+			"tenant":   []byte("acme"),
+			"trace-id": []byte("abc"),
+			"placed":   []byte("2021-10-01T12:00:00Z"),
+		})
+	})
+
+	t.Run("omits empty values", func(t *testing.T) {
+		r, err := MarshalTagged(JSONCodec{}, taggedOrder{ID: "order-1"})
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, r.Metadata.Key, []byte("order-1"))
+		_, ok := r.Metadata.Headers["tenant"]
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestLog_WriteTagged(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	placed := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	want := taggedOrder{ID: "order-1", Tenant: "acme", Trace: []byte("abc"), Placed: placed, Comment: "rush"}
+
+	offset, err := l.WriteTagged(ctx, JSONCodec{}, want, WithHeader("source", []byte("test")))
+	assert.NilError(t, err)
+
+	r, err := l.Read(ctx, offset)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Metadata.Key, []byte("order-1"))
+	assert.DeepEqual(t, r.Metadata.Headers["source"], []byte("test"))
+
+	var got taggedOrder
+	err = UnmarshalTagged(JSONCodec{}, r, &got)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, want)
+}
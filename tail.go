@@ -0,0 +1,218 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+)
+
+// Tail returns the last n records of the log ordered by offset. If the log
+// contains fewer than n records, all records are returned. Gaps of logs with
+// sparse offsets are skipped, i.e. do not count towards n. Records are read
+// consistently, i.e. concurrent writes can not purge records while reading.
+//
+// Safe for concurrent use.
+func (l *Log) Tail(ctx context.Context, n int) ([]Record, error) {
+	if n <= 0 {
+		return nil, errors.New("n must be greater than 0")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	start, ok := l.tailStart(n)
+	if !ok {
+		return []Record{}, nil
+	}
+
+	records := make([]Record, 0, minInt(n, int(l.offset-start)))
+	for offset := start; offset < l.offset; offset++ {
+		r, err := l.read(ctx, offset)
+		if errors.Is(err, ErrOffsetGap) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// Head returns the first n records retained in the log ordered by offset, i.e.
+// starting at the earliest offset. If the log contains fewer than n records,
+// all records are returned. Gaps of logs with sparse offsets are skipped, i.e.
+// do not count towards n. Records are read consistently, i.e. concurrent
+// writes can not purge records while reading.
+//
+// Safe for concurrent use.
+func (l *Log) Head(ctx context.Context, n int) ([]Record, error) {
+	if n <= 0 {
+		return nil, errors.New("n must be greater than 0")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	earliest, latest := l.offsetRange()
+	if latest == -1 {
+		return []Record{}, nil
+	}
+
+	records := make([]Record, 0, minInt(n, int(latest-earliest+1)))
+	for offset := earliest; offset <= latest && len(records) < n; offset++ {
+		r, err := l.read(ctx, offset)
+		if errors.Is(err, ErrOffsetGap) {
+			offset = l.nextWritten(offset) - 1
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// Last returns the last n records of the log ordered by offset. Last is
+// equivalent to Tail().
+//
+// Safe for concurrent use.
+func (l *Log) Last(ctx context.Context, n int) ([]Record, error) {
+	return l.Tail(ctx, n)
+}
+
+// ReadLatest reads the most recently written record of the log. Resolving and
+// reading the latest offset is atomic, i.e. unlike Range() followed by Read()
+// not affected by concurrent writes. If the log is empty, ErrEmptyLog is
+// returned.
+//
+// Safe for concurrent use.
+func (l *Log) ReadLatest(ctx context.Context) (Record, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if ctx.Err() != nil {
+		return Record{}, ctx.Err()
+	}
+
+	_, latest := l.offsetRange()
+	if latest == -1 {
+		return Record{}, ErrEmptyLog
+	}
+
+	return l.read(ctx, latest)
+}
+
+// ReadEarliest reads the earliest record retained in the log, e.g. to resume
+// a slow reader after ErrOutOfRange. Resolving and reading the earliest
+// offset is atomic, i.e. unlike Range() followed by Read() not affected by
+// concurrent writes purging the record. If the log is empty, ErrEmptyLog is
+// returned.
+//
+// Safe for concurrent use.
+func (l *Log) ReadEarliest(ctx context.Context) (Record, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if ctx.Err() != nil {
+		return Record{}, ctx.Err()
+	}
+
+	earliest, latest := l.offsetRange()
+	if latest == -1 {
+		return Record{}, ErrEmptyLog
+	}
+
+	return l.read(ctx, l.nextWritten(earliest))
+}
+
+// ReadBackward reads up to max records from the log starting at the given
+// offset towards the earliest offset, i.e. the records are ordered by
+// descending offset, e.g. for debugging tools showing the latest records
+// first. Fewer records are returned if the earliest record is reached. Gaps
+// of logs with sparse offsets are skipped, i.e. do not count towards max. The
+// start offset must be readable, i.e. ReadBackward returns the same errors as
+// Read() for the start offset.
+//
+// Safe for concurrent use.
+func (l *Log) ReadBackward(ctx context.Context, start Offset, max int) ([]Record, error) {
+	if max <= 0 {
+		return nil, errors.New("max must be greater than 0")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// fail early if the start offset is not readable
+	if _, err := l.read(ctx, start); err != nil && !errors.Is(err, ErrOffsetGap) {
+		return nil, err
+	}
+
+	records := make([]Record, 0, minInt(max, int(start-l.conf.startOffset)+1))
+	for offset := l.prevWritten(start); offset != -1 && len(records) < max; offset = l.prevWritten(offset - 1) {
+		r, err := l.read(ctx, offset)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// Follow streams the last n records of the log (see Tail()) followed by new
+// records written to the log, i.e. a stream starting at the n-th last record.
+// If the log is empty, the stream starts at the next write offset. See
+// Stream() for the stream semantics and options.
+//
+// Safe for concurrent use.
+func (l *Log) Follow(ctx context.Context, n int, options ...StreamOption) (<-chan StreamRecord, <-chan error) {
+	if n <= 0 {
+		errCh := make(chan error, 1)
+		errCh <- errors.New("n must be greater than 0")
+		close(errCh)
+
+		streamCh := make(chan StreamRecord)
+		close(streamCh)
+		return streamCh, errCh
+	}
+
+	l.mu.RLock()
+	start, ok := l.tailStart(n)
+	if !ok {
+		start = l.offset
+	}
+	l.mu.RUnlock()
+
+	return l.Stream(ctx, start, options...)
+}
+
+// tailStart returns the offset of the n-th last record in the log. If the log
+// is empty, false is returned. Must be protected with a lock by the caller.
+func (l *Log) tailStart(n int) (Offset, bool) {
+	earliest, latest := l.offsetRange()
+	if latest == -1 {
+		return -1, false
+	}
+
+	start := latest
+	now := l.expiryTime()
+	for offset := latest; offset >= earliest && n > 0; offset-- {
+		s, err := l.getSegment(offset)
+		if err != nil {
+			// sealed history segment ends before active segment starts, skip
+			// to the end of history
+			offset = l.history.currentOffset() + 1
+			continue
+		}
+
+		if s.live(offset, now) {
+			start = offset
+			n--
+		}
+	}
+
+	return start, true
+}
@@ -0,0 +1,352 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Tail(t *testing.T) {
+	testCases := []struct {
+		name    string
+		records int
+		n       int
+		want    []Offset
+		wantErr string
+	}{
+		{name: "fails with invalid n", records: 5, n: 0, wantErr: "greater than 0"},
+		{name: "empty log", records: 0, n: 3, want: []Offset{}},
+		{name: "fewer records than n", records: 2, n: 5, want: []Offset{0, 1}},
+		{name: "last records of active segment", records: 5, n: 2, want: []Offset{3, 4}},
+		{name: "last records across history and active segment", records: 14, n: 6, want: []Offset{8, 9, 10, 11, 12, 13}},
+		{name: "all retained records after purge", records: 25, n: 30, want: []Offset{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}},
+		{name: "n larger than any slice", records: 3, n: math.MaxInt, want: []Offset{0, 1, 2}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithMaxSegmentSize(10))
+			assert.NilError(t, err)
+
+			for _, d := range NewTestDataSlice(t, tc.records) {
+				_, err = l.Write(ctx, d)
+				assert.NilError(t, err)
+			}
+
+			records, err := l.Tail(ctx, tc.n)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+
+			got := make([]Offset, 0, len(records))
+			for _, r := range records {
+				got = append(got, r.Metadata.Offset)
+			}
+			assert.DeepEqual(t, got, tc.want)
+		})
+	}
+
+	t.Run("skips gaps of sparse log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(5), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{0, 1, 3, 100, 102} {
+			_, err = l.WriteAt(ctx, offset, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		records, err := l.Tail(ctx, 3)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), 3)
+		assert.Equal(t, records[0].Metadata.Offset, Offset(3))
+		assert.Equal(t, records[1].Metadata.Offset, Offset(100))
+		assert.Equal(t, records[2].Metadata.Offset, Offset(102))
+	})
+}
+
+func TestLog_Head(t *testing.T) {
+	testCases := []struct {
+		name    string
+		records int
+		n       int
+		want    []Offset
+		wantErr string
+	}{
+		{name: "fails with invalid n", records: 5, n: -1, wantErr: "greater than 0"},
+		{name: "empty log", records: 0, n: 3, want: []Offset{}},
+		{name: "fewer records than n", records: 2, n: 5, want: []Offset{0, 1}},
+		{name: "first records of history segment", records: 14, n: 3, want: []Offset{0, 1, 2}},
+		{name: "first retained records after purge", records: 25, n: 2, want: []Offset{10, 11}},
+		{name: "n larger than any slice", records: 3, n: 1 << 40, want: []Offset{0, 1, 2}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithMaxSegmentSize(10))
+			assert.NilError(t, err)
+
+			for _, d := range NewTestDataSlice(t, tc.records) {
+				_, err = l.Write(ctx, d)
+				assert.NilError(t, err)
+			}
+
+			records, err := l.Head(ctx, tc.n)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+
+			got := make([]Offset, 0, len(records))
+			for _, r := range records {
+				got = append(got, r.Metadata.Offset)
+			}
+			assert.DeepEqual(t, got, tc.want)
+
+			// Last is equivalent to Tail
+			last, err := l.Last(ctx, tc.n)
+			assert.NilError(t, err)
+			tail, err := l.Tail(ctx, tc.n)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, last, tail)
+		})
+	}
+
+	t.Run("skips gaps of sparse log", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(5), WithSparseOffsets())
+		assert.NilError(t, err)
+
+		for _, offset := range []Offset{0, 3, 100, 102} {
+			_, err = l.WriteAt(ctx, offset, newTestData(t, "data"))
+			assert.NilError(t, err)
+		}
+
+		records, err := l.Head(ctx, 3)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), 3)
+		assert.Equal(t, records[0].Metadata.Offset, Offset(0))
+		assert.Equal(t, records[1].Metadata.Offset, Offset(3))
+		assert.Equal(t, records[2].Metadata.Offset, Offset(100))
+	})
+}
+
+func TestLog_Follow(t *testing.T) {
+	t.Run("fails with invalid n", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		streamCh, errCh := l.Follow(ctx, -1)
+		assert.ErrorContains(t, <-errCh, "greater than 0")
+		_, ok := <-streamCh
+		assert.Assert(t, !ok)
+	})
+
+	t.Run("streams last records and new writes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 10) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		streamCh, _ := l.Follow(ctx, 3)
+		for _, want := range []Offset{7, 8, 9} {
+			r := <-streamCh
+			assert.Equal(t, r.Record.Metadata.Offset, want)
+		}
+
+		_, err = l.Write(ctx, newTestData(t, "new"))
+		assert.NilError(t, err)
+
+		r := <-streamCh
+		assert.Equal(t, r.Record.Metadata.Offset, Offset(10))
+	})
+}
+
+func TestLog_ReadLatest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		records int
+		want    Offset
+		wantErr error
+	}{
+		{name: "fails with empty log", records: 0, wantErr: ErrEmptyLog},
+		{name: "latest record of active segment", records: 5, want: 4},
+		{name: "latest record after purge", records: 25, want: 24},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithMaxSegmentSize(10))
+			assert.NilError(t, err)
+
+			for _, d := range NewTestDataSlice(t, tc.records) {
+				_, err = l.Write(ctx, d)
+				assert.NilError(t, err)
+			}
+
+			r, err := l.ReadLatest(ctx)
+			if tc.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, r.Metadata.Offset, tc.want)
+		})
+	}
+
+	t.Run("latest record with sparse offsets", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets())
+		assert.NilError(t, err)
+
+		_, err = l.WriteAt(ctx, 7, []byte("data"))
+		assert.NilError(t, err)
+
+		r, err := l.ReadLatest(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(7))
+	})
+}
+
+func TestLog_ReadEarliest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		start   Offset
+		records int
+		want    Offset
+		wantErr error
+	}{
+		{name: "fails with empty log", records: 0, wantErr: ErrEmptyLog},
+		{name: "start offset without purge", start: 10, records: 5, want: 10},
+		{name: "earliest record after purge", records: 25, want: 10},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithStartOffset(tc.start), WithMaxSegmentSize(10))
+			assert.NilError(t, err)
+
+			for _, d := range NewTestDataSlice(t, tc.records) {
+				_, err = l.Write(ctx, d)
+				assert.NilError(t, err)
+			}
+
+			r, err := l.ReadEarliest(ctx)
+			if tc.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, r.Metadata.Offset, tc.want)
+		})
+	}
+
+	t.Run("resumes after purged offset", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		for _, d := range NewTestDataSlice(t, 6) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		_, err = l.Read(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		r, err := l.ReadEarliest(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(2))
+	})
+}
+
+func TestLog_ReadBackward(t *testing.T) {
+	testCases := []struct {
+		name       string
+		start      Offset
+		max        int
+		want       []Offset
+		wantErr    error
+		wantErrMsg string
+	}{
+		{name: "fails with invalid max", start: 20, max: 0, wantErrMsg: "max must be greater than 0"},
+		{name: "fails with purged offset", start: 5, max: 2, wantErr: ErrOutOfRange},
+		{name: "fails with future offset", start: 25, max: 2, wantErr: ErrFutureOffset},
+		{name: "reads up to max records", start: 24, max: 3, want: []Offset{24, 23, 22}},
+		{name: "reads across segments", start: 21, max: 4, want: []Offset{21, 20, 19, 18}},
+		{name: "reads until earliest record", start: 12, max: 10, want: []Offset{12, 11, 10}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithMaxSegmentSize(10))
+			assert.NilError(t, err)
+
+			for _, d := range NewTestDataSlice(t, 25) {
+				_, err = l.Write(ctx, d)
+				assert.NilError(t, err)
+			}
+
+			records, err := l.ReadBackward(ctx, tc.start, tc.max)
+			switch {
+			case tc.wantErr != nil:
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+				return
+			case tc.wantErrMsg != "":
+				assert.ErrorContains(t, err, tc.wantErrMsg)
+				return
+			}
+			assert.NilError(t, err)
+
+			offsets := make([]Offset, 0, len(records))
+			for _, r := range records {
+				offsets = append(offsets, r.Metadata.Offset)
+			}
+			assert.DeepEqual(t, offsets, tc.want)
+		})
+	}
+
+	t.Run("skips gaps", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithSparseOffsets(), WithMaxSegmentSize(3))
+		assert.NilError(t, err)
+
+		// 10 starts a new active segment
+		for _, offset := range []Offset{0, 2, 10, 12} {
+			_, err = l.WriteAt(ctx, offset, []byte("data"))
+			assert.NilError(t, err)
+		}
+
+		records, err := l.ReadBackward(ctx, 11, 10)
+		assert.NilError(t, err)
+
+		offsets := make([]Offset, 0, len(records))
+		for _, r := range records {
+			offsets = append(offsets, r.Metadata.Offset)
+		}
+		assert.DeepEqual(t, offsets, []Offset{10, 2, 0})
+	})
+}
@@ -0,0 +1,90 @@
+package memlog
+
+import (
+	"context"
+	"strconv"
+)
+
+// HeaderTombstone is the record header marking a deleted record, see
+// Log.Delete(). The value is the UTC deletion time in Unix nanoseconds.
+const HeaderTombstone = "memlog-tombstone"
+
+// IsTombstone returns true if the record has been deleted with Log.Delete()
+func IsTombstone(r Record) bool {
+	_, ok := r.Metadata.Headers[HeaderTombstone]
+	return ok
+}
+
+// Delete replaces the record at the given offset with a tombstone, e.g. to
+// redact user data without rebuilding the log. The tombstone keeps the offset,
+// creation time, epoch and key of the record, so that offsets stay continuous.
+// The payload and headers are removed and the HeaderTombstone header is added,
+// see IsTombstone(). Payload bytes not shared with other records (see
+// WithPayloadDeduplication()) are overwritten with zeros. Deleting a
+// tombstone is a no-op.
+//
+// Tombstones are not verified on read (see WithVerifier()) and invalidate
+// inclusion proofs of the deleted record (see WithMerkleTree()). Values
+// decoded before the deletion, e.g. by a DecodeCache, are not affected.
+//
+// Safe for concurrent use.
+func (l *Log) Delete(ctx context.Context, offset Offset) error {
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	if offset >= l.offset {
+		if l.isEmpty() {
+			return ErrEmptyLog
+		}
+		return ErrFutureOffset
+	}
+
+	s, err := l.getSegment(offset)
+	if err != nil {
+		return err
+	}
+
+	// payloads of compressed segments are compressed as a whole
+	if err = s.touch(l.clock.Now()); err != nil {
+		return err
+	}
+
+	r := &s.data[offset-s.start]
+	if r.Metadata.Offset != offset {
+		return ErrOffsetGap
+	}
+
+	if IsTombstone(*r) {
+		return nil
+	}
+
+	s.bytes -= len(r.Data)
+	if l.payloads != nil {
+		l.releasePayload(r.Data)
+	} else {
+		// scrub the private copy, also in off-heap memory
+		for i := range r.Data {
+			r.Data[i] = 0
+		}
+	}
+
+	r.Data = nil
+	if l.conf.checksums {
+		r.Metadata.Checksum = checksum(r.Data)
+	}
+	r.Metadata.Headers = map[string][]byte{
+		HeaderTombstone: []byte(strconv.FormatInt(l.clock.Now().UTC().UnixNano(), 10)),
+	}
+
+	return nil
+}
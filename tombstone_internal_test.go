@@ -0,0 +1,152 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Delete(t *testing.T) {
+	t.Run("fails with invalid offset", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			records int
+			offset  Offset
+			wantErr error
+		}{
+			{name: "empty log", records: 0, offset: 0, wantErr: ErrEmptyLog},
+			{name: "future offset", records: 5, offset: 5, wantErr: ErrFutureOffset},
+			{name: "purged offset", records: 25, offset: 5, wantErr: ErrOutOfRange},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				ctx := context.Background()
+				l, err := New(ctx, WithMaxSegmentSize(10))
+				assert.NilError(t, err)
+
+				for _, d := range NewTestDataSlice(t, tc.records) {
+					_, err = l.Write(ctx, d)
+					assert.NilError(t, err)
+				}
+
+				err = l.Delete(ctx, tc.offset)
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+			})
+		}
+	})
+
+	t.Run("replaces record with tombstone", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		for _, d := range []string{"alice", "bob"} {
+			_, err = l.Write(ctx, []byte(d), WithKey([]byte("user")), WithHeader("email", []byte(d+"@example.com")))
+			assert.NilError(t, err)
+		}
+
+		mockClock.Add(time.Minute)
+		assert.NilError(t, l.Delete(ctx, 0))
+		assert.Equal(t, l.RetainedBytes(), len("bob"))
+
+		// scrubbed
+		assert.Assert(t, l.active.data[0].Data == nil)
+
+		r, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Assert(t, IsTombstone(r))
+		assert.Equal(t, r.Metadata.Offset, Offset(0))
+		assert.Equal(t, string(r.Metadata.Key), "user")
+		assert.Equal(t, len(r.Data), 0)
+		_, ok := r.Metadata.Headers["email"]
+		assert.Assert(t, !ok)
+
+		r, err = l.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.Assert(t, !IsTombstone(r))
+		assert.Equal(t, string(r.Data), "bob")
+
+		// no-op
+		assert.NilError(t, l.Delete(ctx, 0))
+	})
+
+	t.Run("scrubs payload", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("secret"))
+		assert.NilError(t, err)
+
+		payload := l.active.data[0].Data
+		assert.NilError(t, l.Delete(ctx, 0))
+		assert.DeepEqual(t, payload, make([]byte, len("secret")))
+	})
+
+	t.Run("releases shared payload", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithPayloadDeduplication())
+		assert.NilError(t, err)
+
+		for _, d := range []string{"shared", "shared", "single"} {
+			_, err = l.Write(ctx, []byte(d))
+			assert.NilError(t, err)
+		}
+
+		assert.NilError(t, l.Delete(ctx, 0))
+		assert.NilError(t, l.Delete(ctx, 2))
+		assert.Equal(t, l.payloads.Len(), 1)
+
+		r, err := l.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.Equal(t, string(r.Data), "shared")
+	})
+
+	t.Run("tombstone is not verified", func(t *testing.T) {
+		ctx := context.Background()
+		signer, err := NewHMACSigner([]byte("secret"))
+		assert.NilError(t, err)
+
+		l, err := New(ctx, WithSigner(signer), WithVerifier(signer))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("data"))
+		assert.NilError(t, err)
+		assert.NilError(t, l.Delete(ctx, 0))
+
+		r, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Assert(t, IsTombstone(r))
+	})
+
+	t.Run("deletes from compressed segment", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 3)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		mockClock.Add(time.Hour)
+		n, err := l.CompressIdle(ctx, time.Minute)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 1)
+
+		assert.NilError(t, l.Delete(ctx, 0))
+
+		r, err := l.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data, data[1])
+	})
+}
@@ -0,0 +1,91 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// TopicRecord is a record written to a topic of a Manager, see
+// WriteTransaction()
+type TopicRecord struct {
+	// Topic is the name of the topic to write to
+	Topic string
+	// Data is the record payload
+	Data []byte
+	// Options are the write options of the record
+	Options []WriteOption
+}
+
+// WriteTransaction writes the given records to their topics atomically, i.e.
+// either all records appear in their logs or none, e.g. to emit an event and
+// an audit record which must never diverge. Records of the same topic are
+// written in order. All logs of the transaction are locked until all records
+// have been written, so readers never observe a partially written
+// transaction. The offsets of the written records are returned in the order
+// of the given records. If an error occurs, no record is written and the error
+// is returned. If a topic does not exist, ErrTopicNotFound is returned.
+//
+// Safe for concurrent use.
+func (m *Manager) WriteTransaction(ctx context.Context, records []TopicRecord) ([]Offset, error) {
+	if len(records) == 0 {
+		return nil, errors.New("no records provided")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// group records by topic, remembering their position in the transaction
+	var (
+		batches   = make(map[string][]batchEntry)
+		positions = make(map[string][]int)
+	)
+	for i, r := range records {
+		if _, ok := m.logs[r.Topic]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTopicNotFound, r.Topic)
+		}
+
+		batches[r.Topic] = append(batches[r.Topic], batchEntry{data: r.Data, options: r.Options})
+		positions[r.Topic] = append(positions[r.Topic], i)
+	}
+
+	// lock logs in topic order to prevent deadlocks between transactions
+	topics := make([]string, 0, len(batches))
+	for t := range batches {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+
+	for i, t := range topics {
+		if err := m.logs[t].lock(ctx); err != nil {
+			for _, locked := range topics[:i] {
+				m.logs[locked].mu.Unlock()
+			}
+			return nil, err
+		}
+	}
+	defer func() {
+		for _, t := range topics {
+			m.logs[t].mu.Unlock()
+		}
+	}()
+
+	configs := make(map[string][]writeConfig, len(topics))
+	for _, t := range topics {
+		c, err := m.logs[t].prepareBatch(ctx, batches[t])
+		if err != nil {
+			return nil, fmt.Errorf("topic %q: %w", t, err)
+		}
+		configs[t] = c
+	}
+
+	offsets := make([]Offset, len(records))
+	for _, t := range topics {
+		for i, offset := range m.logs[t].commitBatch(batches[t], configs[t]) {
+			offsets[positions[t][i]] = offset
+		}
+	}
+
+	return offsets, nil
+}
@@ -0,0 +1,89 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestManager_WriteTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	newManager := func(t *testing.T) *Manager {
+		m, err := NewManager()
+		assert.NilError(t, err)
+
+		_, err = m.Create(ctx, "events")
+		assert.NilError(t, err)
+
+		_, err = m.Create(ctx, "audit", WithMaxRecordSizeBytes(10))
+		assert.NilError(t, err)
+
+		return m
+	}
+
+	t.Run("fails without records", func(t *testing.T) {
+		m := newManager(t)
+		_, err := m.WriteTransaction(ctx, nil)
+		assert.ErrorContains(t, err, "no records provided")
+	})
+
+	t.Run("writes records to all topics", func(t *testing.T) {
+		m := newManager(t)
+
+		offsets, err := m.WriteTransaction(ctx, []TopicRecord{
+			{Topic: "events", Data: []byte("e1")},
+			{Topic: "audit", Data: []byte("a1"), Options: []WriteOption{WithKey([]byte("k"))}},
+			{Topic: "events", Data: []byte("e2")},
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, offsets, []Offset{0, 0, 1})
+
+		events, err := m.Get("events")
+		assert.NilError(t, err)
+
+		r, err := events.Read(ctx, 1)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data, []byte("e2"))
+
+		audit, err := m.Get("audit")
+		assert.NilError(t, err)
+
+		r, err = audit.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data, []byte("a1"))
+		assert.DeepEqual(t, r.Metadata.Key, []byte("k"))
+	})
+
+	t.Run("writes no record if a topic does not exist", func(t *testing.T) {
+		m := newManager(t)
+
+		_, err := m.WriteTransaction(ctx, []TopicRecord{
+			{Topic: "events", Data: []byte("e1")},
+			{Topic: "unknown", Data: []byte("u1")},
+		})
+		assert.Assert(t, errors.Is(err, ErrTopicNotFound))
+
+		events, err := m.Get("events")
+		assert.NilError(t, err)
+		assert.Assert(t, events.IsEmpty(ctx))
+	})
+
+	t.Run("writes no record if a record is invalid", func(t *testing.T) {
+		m := newManager(t)
+
+		_, err := m.WriteTransaction(ctx, []TopicRecord{
+			{Topic: "events", Data: []byte("e1")},
+			{Topic: "audit", Data: []byte("record exceeds the size limit")},
+		})
+		assert.Assert(t, errors.Is(err, ErrRecordTooLarge))
+
+		for _, topic := range m.Topics() {
+			l, err := m.Get(topic)
+			assert.NilError(t, err)
+			assert.Assert(t, l.IsEmpty(ctx), topic)
+		}
+	})
+}
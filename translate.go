@@ -0,0 +1,139 @@
+package memlog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// OffsetTranslation maps offsets of a source log to the offsets of the log the
+// source records were imported into. It is created by ImportFrom() when
+// offsets are not preserved and can be stored to translate consumer
+// checkpoints created against the source log, see WithOffsetTranslation().
+type OffsetTranslation struct {
+	// Source is the source id of the import, see WithSourceID(). Empty for
+	// imports without source id.
+	Source string `json:"source,omitempty"`
+	// Runs are contiguous offset ranges ordered by source offset
+	Runs []TranslationRun `json:"runs"`
+}
+
+// TranslationRun maps Count contiguous source offsets starting at Source to
+// contiguous target offsets starting at Target
+type TranslationRun struct {
+	Source Offset `json:"source"`
+	Target Offset `json:"target"`
+	Count  int    `json:"count"`
+}
+
+// Translate returns the target offset of the given source offset. If the source
+// offset has not been imported, ErrOutOfRange is returned.
+func (t OffsetTranslation) Translate(source Offset) (Offset, error) {
+	i := sort.Search(len(t.Runs), func(i int) bool {
+		r := t.Runs[i]
+		return r.Source+Offset(r.Count) > source
+	})
+
+	if i == len(t.Runs) || t.Runs[i].Source > source {
+		return -1, fmt.Errorf("%w: no translation for offset %d", ErrOutOfRange, source)
+	}
+
+	r := t.Runs[i]
+	return r.Target + (source - r.Source), nil
+}
+
+// end returns the source offset following the last translated source offset
+func (t OffsetTranslation) end() Offset {
+	n := len(t.Runs)
+	if n == 0 {
+		return 0
+	}
+
+	last := t.Runs[n-1]
+	return last.Source + Offset(last.Count)
+}
+
+// add records the translation of a single offset. Source offsets must be
+// added in increasing order, see end().
+func (t *OffsetTranslation) add(source, target Offset) {
+	if n := len(t.Runs); n > 0 {
+		last := &t.Runs[n-1]
+		if source == t.end() && target == last.Target+Offset(last.Count) {
+			last.Count++
+			return
+		}
+	}
+
+	t.Runs = append(t.Runs, TranslationRun{Source: source, Target: target, Count: 1})
+}
+
+func (t OffsetTranslation) copy() OffsetTranslation {
+	return OffsetTranslation{Source: t.Source, Runs: append([]TranslationRun(nil), t.Runs...)}
+}
+
+// Translate returns the offset in this log of a record imported from the
+// given source offset by an import without source id. Translations are
+// recorded by ImportFrom() without WithPreserveOffsets() or loaded with
+// WithOffsetTranslation(). If the source offset has not been imported,
+// ErrOutOfRange is returned. See TranslateFrom() for imports with a source id.
+//
+// Safe for concurrent use.
+func (l *Log) Translate(ctx context.Context, source Offset) (Offset, error) {
+	return l.TranslateFrom(ctx, "", source)
+}
+
+// TranslateFrom is like Translate() for the source offsets of imports with the
+// given source id, see WithSourceID()
+//
+// Safe for concurrent use.
+func (l *Log) TranslateFrom(ctx context.Context, sourceID string, source Offset) (Offset, error) {
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.translations[sourceID].Translate(source)
+}
+
+// OffsetTranslation returns a copy of the offset translation of imports
+// without source id, e.g. to persist it after an import
+//
+// Safe for concurrent use.
+func (l *Log) OffsetTranslation() OffsetTranslation {
+	return l.OffsetTranslationFrom("")
+}
+
+// OffsetTranslationFrom returns a copy of the offset translation of imports
+// with the given source id, see WithSourceID()
+//
+// Safe for concurrent use.
+func (l *Log) OffsetTranslationFrom(sourceID string) OffsetTranslation {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	t := l.translations[sourceID].copy()
+	t.Source = sourceID
+	return t
+}
+
+// translate records the translation of the source offset of an imported record
+// to the given offset. If the first record of an import does not continue the
+// translation of its source, the import starts a new translation, e.g. for
+// another source imported without source id. Must be protected with a lock by
+// the caller.
+func (l *Log) translate(sourceID string, source, offset Offset, first bool) {
+	t := l.translations[sourceID]
+	if first && source < t.end() {
+		t = OffsetTranslation{}
+	}
+
+	t.Source = sourceID
+	t.add(source, offset)
+
+	if l.translations == nil {
+		l.translations = make(map[string]OffsetTranslation)
+	}
+	l.translations[sourceID] = t
+}
@@ -0,0 +1,146 @@
+package memlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Translate(t *testing.T) {
+	t.Run("translates offsets of imported records", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithStartOffset(1000))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, newTestData(t, "existing"))
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 10, 11, 12, 20, 21)))
+		assert.NilError(t, err)
+
+		testCases := []struct {
+			source  Offset
+			want    Offset
+			wantErr error
+		}{
+			{source: 9, wantErr: ErrOutOfRange},
+			{source: 10, want: 1001},
+			{source: 12, want: 1003},
+			{source: 15, wantErr: ErrOutOfRange},
+			{source: 20, want: 1004},
+			{source: 21, want: 1005},
+			{source: 22, wantErr: ErrOutOfRange},
+		}
+
+		for _, tc := range testCases {
+			got, translateErr := l.Translate(ctx, tc.source)
+			if tc.wantErr != nil {
+				assert.Assert(t, errors.Is(translateErr, tc.wantErr))
+				continue
+			}
+			assert.NilError(t, translateErr)
+			assert.Equal(t, got, tc.want)
+		}
+
+		assert.DeepEqual(t, l.OffsetTranslation(), OffsetTranslation{Runs: []TranslationRun{
+			{Source: 10, Target: 1001, Count: 3},
+			{Source: 20, Target: 1004, Count: 2},
+		}})
+	})
+
+	t.Run("fails on non-increasing source offsets", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 10, 5)))
+		assert.ErrorContains(t, err, "not increasing")
+
+		_, latest := l.Range(ctx)
+		assert.Equal(t, latest, Offset(0))
+	})
+
+	t.Run("translates imports from several sources", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 10, 11)), WithSourceID("a"))
+		assert.NilError(t, err)
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 0, 1)), WithSourceID("b"))
+		assert.NilError(t, err)
+
+		got, err := l.TranslateFrom(ctx, "a", 11)
+		assert.NilError(t, err)
+		assert.Equal(t, got, Offset(1))
+
+		got, err = l.TranslateFrom(ctx, "b", 0)
+		assert.NilError(t, err)
+		assert.Equal(t, got, Offset(2))
+
+		_, err = l.Translate(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+		assert.DeepEqual(t, l.OffsetTranslationFrom("b"), OffsetTranslation{Source: "b", Runs: []TranslationRun{
+			{Source: 0, Target: 2, Count: 2},
+		}})
+
+		restarted, err := New(ctx, WithOffsetTranslation(l.OffsetTranslationFrom("a")), WithOffsetTranslation(l.OffsetTranslationFrom("b")))
+		assert.NilError(t, err)
+
+		got, err = restarted.TranslateFrom(ctx, "a", 10)
+		assert.NilError(t, err)
+		assert.Equal(t, got, Offset(0))
+	})
+
+	t.Run("imports another source without source id", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 10, 11)))
+		assert.NilError(t, err)
+
+		// the second import starts a new translation
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 0, 1)))
+		assert.NilError(t, err)
+
+		got, err := l.Translate(ctx, 1)
+		assert.NilError(t, err)
+		assert.Equal(t, got, Offset(3))
+
+		_, err = l.Translate(ctx, 10)
+		assert.Assert(t, errors.Is(err, ErrOutOfRange))
+	})
+
+	t.Run("loads persisted translation", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		err = l.ImportFrom(ctx, NewSliceSource(newTestRecords(t, 50, 51, 52)))
+		assert.NilError(t, err)
+
+		b, err := json.Marshal(l.OffsetTranslation())
+		assert.NilError(t, err)
+
+		var persisted OffsetTranslation
+		assert.NilError(t, json.Unmarshal(b, &persisted))
+
+		restarted, err := New(ctx, WithOffsetTranslation(persisted))
+		assert.NilError(t, err)
+
+		got, err := restarted.Translate(ctx, 51)
+		assert.NilError(t, err)
+		assert.Equal(t, got, Offset(1))
+	})
+
+	t.Run("fails with invalid persisted translation", func(t *testing.T) {
+		invalid := OffsetTranslation{Runs: []TranslationRun{{Source: 10, Count: 5}, {Source: 12, Count: 1}}}
+		_, err := New(context.Background(), WithOffsetTranslation(invalid))
+		assert.ErrorContains(t, err, "ordered")
+	})
+}
@@ -0,0 +1,106 @@
+package memlog
+
+import (
+	"context"
+	"fmt"
+)
+
+// Trim purges all records with an offset lower than the given offset
+// independent of segment rollover, e.g. to free memory as soon as all consumers
+// have committed their progress. Trimmed records are not downsampled (see
+// WithDownsampling()) but compacted (see WithCompaction()). The latest record
+// is always retained, i.e. before must not be greater than the latest offset.
+// Trimming offsets which are already purged is a no-op. If trimming would purge
+// records held by a consumer (see HoldPurge()), ErrPurgeHeld is returned.
+//
+// Payloads of trimmed records stored off-heap (see WithOffHeapStorage()) are
+// released when the remaining records of their segment are purged.
+//
+// Safe for concurrent use.
+func (l *Log) Trim(ctx context.Context, before Offset) error {
+	if err := l.lock(ctx); err != nil {
+		return err
+	}
+	defer l.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	if l.isEmpty() {
+		return ErrEmptyLog
+	}
+
+	earliest, latest := l.offsetRange()
+	if before > latest {
+		return fmt.Errorf("%w: trim offset %d after latest offset %d", ErrFutureOffset, before, latest)
+	}
+
+	if before <= earliest {
+		return nil
+	}
+
+	if err := l.checkPurge(before - 1); err != nil {
+		return err
+	}
+
+	return l.trim(before)
+}
+
+// trim purges all records with an offset lower than the given offset, which
+// must not be greater than the next write offset. Must be protected with a
+// lock by the caller.
+func (l *Log) trim(before Offset) error {
+	if l.history != nil {
+		if before <= l.history.currentOffset() {
+			return l.trimSegment(l.history, before)
+		}
+
+		if err := l.compact(l.history, len(l.history.data)); err != nil {
+			return err
+		}
+		l.purge(l.history)
+		l.history = nil
+	}
+
+	if before > l.active.start {
+		return l.trimSegment(l.active, before)
+	}
+	return nil
+}
+
+// trimSegment removes the records of the segment with an offset lower than the
+// given offset, which must be within the segment or its next offset. Must be
+// protected with a lock by the caller.
+func (l *Log) trimSegment(s *segment, before Offset) error {
+	// payloads of compressed segments are compressed as a whole
+	if err := s.touch(l.clock.Now()); err != nil {
+		return err
+	}
+
+	n := int(before - s.start)
+	if err := l.compact(s, n); err != nil {
+		return err
+	}
+	l.emit(EventPurged, s.start, before-1)
+
+	for i, r := range s.data[:n] {
+		if r.Metadata.Offset == s.start+Offset(i) {
+			l.purged++
+		}
+		s.bytes -= len(r.Data)
+		l.releasePayload(r.Data)
+	}
+
+	// keep the capacity of the segment
+	data := make([]Record, len(s.data)-n, cap(s.data))
+	copy(data, s.data[n:])
+	s.data = data
+	s.start = before
+
+	return nil
+}
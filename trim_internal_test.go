@@ -0,0 +1,96 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_Trim(t *testing.T) {
+	testCases := []struct {
+		name         string
+		records      int
+		before       Offset
+		wantEarliest Offset
+		wantErr      error
+	}{
+		{name: "fails with empty log", records: 0, before: 0, wantErr: ErrEmptyLog},
+		{name: "fails after latest offset", records: 15, before: 15, wantErr: ErrFutureOffset},
+		{name: "no-op for purged offset", records: 25, before: 5, wantEarliest: 10},
+		{name: "trims within active segment", records: 5, before: 3, wantEarliest: 3},
+		{name: "trims within history segment", records: 15, before: 7, wantEarliest: 7},
+		{name: "purges history segment", records: 15, before: 10, wantEarliest: 10},
+		{name: "purges history and trims active segment", records: 15, before: 14, wantEarliest: 14},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, err := New(ctx, WithMaxSegmentSize(10))
+			assert.NilError(t, err)
+
+			for _, d := range NewTestDataSlice(t, tc.records) {
+				_, err = l.Write(ctx, d)
+				assert.NilError(t, err)
+			}
+
+			err = l.Trim(ctx, tc.before)
+			if tc.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+				return
+			}
+			assert.NilError(t, err)
+
+			earliest, latest := l.Range(ctx)
+			assert.Equal(t, earliest, tc.wantEarliest)
+			assert.Equal(t, latest, Offset(tc.records-1))
+
+			_, err = l.Read(ctx, tc.wantEarliest-1)
+			assert.Assert(t, errors.Is(err, ErrOutOfRange))
+
+			for offset := earliest; offset <= latest; offset++ {
+				_, err = l.Read(ctx, offset)
+				assert.NilError(t, err)
+			}
+		})
+	}
+
+	t.Run("keeps writing after trim", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithMaxSegmentSize(4))
+		assert.NilError(t, err)
+
+		data := NewTestDataSlice(t, 3)
+		for _, d := range data {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.Trim(ctx, 2))
+		assert.Equal(t, l.RetainedBytes(), len(data[2]))
+
+		for _, d := range NewTestDataSlice(t, 6) {
+			_, err = l.Write(ctx, d)
+			assert.NilError(t, err)
+		}
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(2))
+		assert.Equal(t, latest, Offset(8))
+	})
+
+	t.Run("releases deduplicated payloads", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx, WithPayloadDeduplication())
+		assert.NilError(t, err)
+
+		for _, d := range []string{"a", "b", "c"} {
+			_, err = l.Write(ctx, []byte(d))
+			assert.NilError(t, err)
+		}
+		assert.NilError(t, l.Trim(ctx, 2))
+		assert.Equal(t, l.payloads.Len(), 1)
+	})
+}
@@ -0,0 +1,109 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrExpired is returned when reading a record whose time to live has passed,
+// see WithTTL(). Expired records are treated like gaps of logs with sparse
+// offsets, i.e. errors.Is(err, ErrOffsetGap) is also true for expired records.
+var ErrExpired = errors.New("record expired")
+
+// expiredError is returned when reading an expired record
+type expiredError struct{}
+
+func (expiredError) Error() string {
+	return ErrExpired.Error()
+}
+
+func (expiredError) Is(target error) bool {
+	return target == ErrExpired || target == ErrOffsetGap
+}
+
+// WithTTL sets the time to live of the written record, i.e. the record expires
+// and is no longer readable once the given duration has passed since its
+// creation, e.g. for short-lived entries of an event cache. Readers skip
+// expired records like gaps. Expired records are purged with PurgeExpired(),
+// when their segment is purged or, if they are the earliest records of the
+// log, with the next write. A TTL can not be combined with minimal metadata
+// (see WithMinimalMetadata()).
+func WithTTL(d time.Duration) WriteOption {
+	return func(wc *writeConfig) {
+		wc.ttl = d
+	}
+}
+
+// PurgeExpired purges all expired records retained in the log (see WithTTL())
+// independent of segment rollover and returns the number of purged records.
+// The earliest offset of the log advances past purged records.
+//
+// Safe for concurrent use.
+func (l *Log) PurgeExpired(ctx context.Context) (int, error) {
+	if err := l.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer l.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	if l.closed {
+		return 0, ErrClosed
+	}
+
+	now := l.expiryTime()
+	if now.IsZero() {
+		return 0, nil
+	}
+
+	var purged int
+	for _, s := range []*segment{l.history, l.active} {
+		if s == nil {
+			continue
+		}
+
+		for i, r := range s.data {
+			offset := s.start + Offset(i)
+			if r.Metadata.Offset != offset || !expired(r.Metadata, now) {
+				continue
+			}
+
+			// payloads of compressed segments are compressed as a whole
+			if err := s.touch(l.clock.Now()); err != nil {
+				return purged, err
+			}
+
+			if err := l.checkPurge(offset); err != nil {
+				return purged, err
+			}
+
+			l.emit(EventPurged, offset, offset)
+			s.bytes -= len(s.data[i].Data)
+			l.releasePayload(s.data[i].Data)
+			s.data[i] = gapRecord
+			l.purged++
+			purged++
+		}
+	}
+
+	return purged, l.purgeExpiredPrefix()
+}
+
+// expiryTime returns the current time to check records for expiry or the zero
+// time if no record with a TTL has been written. Must be protected with a lock
+// by the caller.
+func (l *Log) expiryTime() time.Time {
+	if !l.expiring {
+		return time.Time{}
+	}
+	return l.clock.Now()
+}
+
+// expired returns true if the record with the given metadata has expired at the
+// given time. The zero time disables expiry.
+func expired(h Header, now time.Time) bool {
+	return !now.IsZero() && !h.Expires.IsZero() && !now.Before(h.Expires)
+}
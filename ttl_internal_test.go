@@ -0,0 +1,101 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"gotest.tools/v3/assert"
+)
+
+func TestLog_WithTTL(t *testing.T) {
+	t.Run("fails with invalid ttl", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("data"), WithTTL(-time.Second))
+		assert.ErrorContains(t, err, "ttl must be positive")
+
+		l, err = New(ctx, WithMinimalMetadata())
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("data"), WithTTL(time.Second))
+		assert.ErrorContains(t, err, "minimal metadata")
+	})
+
+	t.Run("expired records are skipped", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock))
+		assert.NilError(t, err)
+
+		_, err = l.Write(ctx, []byte("short"), WithTTL(time.Second))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("forever"))
+		assert.NilError(t, err)
+		_, err = l.Write(ctx, []byte("long"), WithTTL(time.Minute))
+		assert.NilError(t, err)
+
+		r, err := l.Read(ctx, 0)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Expires, mockClock.Now().UTC().Add(time.Second))
+
+		mockClock.Add(time.Second)
+
+		_, err = l.Read(ctx, 0)
+		assert.Assert(t, errors.Is(err, ErrExpired))
+		assert.Assert(t, errors.Is(err, ErrOffsetGap))
+
+		records, err := l.Head(ctx, 10)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), 2)
+		assert.DeepEqual(t, records[0].Data, []byte("forever"))
+
+		r, err = l.ReadEarliest(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Metadata.Offset, Offset(1))
+
+		mockClock.Add(time.Minute)
+
+		records, err = l.Tail(ctx, 10)
+		assert.NilError(t, err)
+		assert.Equal(t, len(records), 1)
+		assert.Equal(t, records[0].Metadata.Offset, Offset(1))
+	})
+
+	t.Run("purges expired records", func(t *testing.T) {
+		ctx := context.Background()
+		mockClock := clock.NewMock()
+		l, err := New(ctx, WithClock(mockClock), WithMaxSegmentSize(2))
+		assert.NilError(t, err)
+
+		n, err := l.PurgeExpired(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 0)
+
+		for i := 0; i < 3; i++ {
+			_, err = l.Write(ctx, []byte("data"), WithTTL(time.Second))
+			assert.NilError(t, err)
+		}
+		_, err = l.Write(ctx, []byte("forever"))
+		assert.NilError(t, err)
+
+		mockClock.Add(time.Second)
+
+		n, err = l.PurgeExpired(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, n, 3)
+		assert.Equal(t, l.RetainedBytes(), len("forever"))
+
+		earliest, latest := l.Range(ctx)
+		assert.Equal(t, earliest, Offset(3))
+		assert.Equal(t, latest, Offset(3))
+
+		stats := l.Stats(ctx)
+		assert.Equal(t, stats.Records, 1)
+		assert.Equal(t, stats.Purged, uint64(3))
+	})
+}
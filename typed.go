@@ -0,0 +1,71 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Typed is a log of values of type T, encoding written values and decoding
+// read records with a codec, so that callers do not marshal record data
+// themselves.
+//
+// Safe for concurrent use.
+type Typed[T any] struct {
+	log   *Log
+	codec Codec
+}
+
+// NewTyped creates a typed log writing to and reading from the given log with
+// the given codec, e.g. JSONCodec{}. If codec is nil, the codec of the log is
+// used, see WithCodec().
+func NewTyped[T any](l *Log, codec Codec) (*Typed[T], error) {
+	if l == nil {
+		return nil, errors.New("log must not be nil")
+	}
+
+	if codec == nil {
+		codec = l.Codec()
+	}
+
+	return &Typed[T]{log: l, codec: codec}, nil
+}
+
+// Log returns the underlying log
+func (t *Typed[T]) Log() *Log {
+	return t.log
+}
+
+// Write encodes the value and creates a new record with the encoded data in
+// the log, see Log.Write()
+func (t *Typed[T]) Write(ctx context.Context, v T, options ...WriteOption) (Offset, error) {
+	data, err := t.codec.Marshal(v)
+	if err != nil {
+		return -1, fmt.Errorf("encode value: %w", err)
+	}
+
+	return t.log.Write(ctx, data, options...)
+}
+
+// Read reads the record at the given offset and decodes its data, see
+// Log.Read(). If the record cannot be decoded, a *DecodeError is returned.
+func (t *Typed[T]) Read(ctx context.Context, offset Offset) (TypedRecord[T], error) {
+	r, err := t.log.Read(ctx, offset)
+	if err != nil {
+		return TypedRecord[T]{}, err
+	}
+
+	var v T
+	if err = t.codec.Unmarshal(r.Data, &v); err != nil {
+		return TypedRecord[T]{}, &DecodeError{Offset: r.Metadata.Offset, Err: err}
+	}
+	return TypedRecord[T]{Metadata: r.Metadata, Data: v}, nil
+}
+
+// Stream streams the decoded records of the log starting at the given offset,
+// see Log.Stream() and Decode() for the handling of records which cannot be
+// decoded
+func (t *Typed[T]) Stream(ctx context.Context, start Offset, options ...StreamOption) (<-chan TypedRecord[T], <-chan error) {
+	stream, errs := t.log.Stream(ctx, start, options...)
+	return Decode[T](ctx, stream, errs, t.codec)
+}
@@ -0,0 +1,103 @@
+package memlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNewTyped(t *testing.T) {
+	ctx := context.Background()
+	l, err := New(ctx)
+	assert.NilError(t, err)
+
+	_, err = NewTyped[string](nil, JSONCodec{})
+	assert.ErrorContains(t, err, "log must not be nil")
+
+	typed, err := NewTyped[string](l, JSONCodec{})
+	assert.NilError(t, err)
+	assert.Equal(t, typed.Log(), l)
+
+	// codec of the log
+	l, err = New(ctx, WithCodec(GobCodec{}))
+	assert.NilError(t, err)
+
+	typed, err = NewTyped[string](l, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, typed.codec, Codec(GobCodec{}))
+}
+
+func TestTyped(t *testing.T) {
+	type event struct {
+		ID string `json:"id"`
+	}
+
+	t.Run("writes and reads values", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		events, err := NewTyped[event](l, JSONCodec{})
+		assert.NilError(t, err)
+
+		offset, err := events.Write(ctx, event{ID: "1"}, WithKey([]byte("1")))
+		assert.NilError(t, err)
+
+		tr, err := events.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.Equal(t, tr.Data, event{ID: "1"})
+		assert.DeepEqual(t, tr.Metadata.Key, []byte("1"))
+
+		r, err := l.Read(ctx, offset)
+		assert.NilError(t, err)
+		assert.Equal(t, string(r.Data), `{"id":"1"}`)
+
+		_, err = events.Read(ctx, offset+1)
+		assert.Assert(t, errors.Is(err, ErrFutureOffset))
+	})
+
+	t.Run("fails with values which cannot be encoded or decoded", func(t *testing.T) {
+		ctx := context.Background()
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		funcs, err := NewTyped[func()](l, JSONCodec{})
+		assert.NilError(t, err)
+		_, err = funcs.Write(ctx, func() {})
+		assert.ErrorContains(t, err, "encode value")
+
+		offset, err := l.Write(ctx, []byte("not json"))
+		assert.NilError(t, err)
+
+		events, err := NewTyped[event](l, JSONCodec{})
+		assert.NilError(t, err)
+		_, err = events.Read(ctx, offset)
+		var decodeErr *DecodeError
+		assert.Assert(t, errors.As(err, &decodeErr))
+		assert.Equal(t, decodeErr.Offset, offset)
+	})
+
+	t.Run("streams decoded values", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, err := New(ctx)
+		assert.NilError(t, err)
+
+		events, err := NewTyped[event](l, JSONCodec{})
+		assert.NilError(t, err)
+
+		for _, id := range []string{"1", "2"} {
+			_, err = events.Write(ctx, event{ID: id})
+			assert.NilError(t, err)
+		}
+
+		stream, _ := events.Stream(ctx, 0)
+		for _, id := range []string{"1", "2"} {
+			tr := <-stream
+			assert.Equal(t, tr.Data.ID, id)
+		}
+	})
+}